@@ -0,0 +1,59 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+// AddAliasExpansion makes the alias, when it is the first argument of the program,
+// stand for the arguments of expansion, as git aliases do: with the expansion "co"
+// for {"checkout", "--quiet"}, 'app co main' executes 'app checkout --quiet main'.
+// Alias expansions are only honored on the root command, are not expanded
+// recursively, and are ignored when a sub-command has the same name or alias.
+func (c *Command) AddAliasExpansion(alias string, expansion []string) {
+	if c.aliasExpansions == nil {
+		c.aliasExpansions = map[string][]string{}
+	}
+	c.aliasExpansions[alias] = append([]string(nil), expansion...)
+}
+
+// AliasExpansion returns the arguments the alias stands for, and false if it is
+// not an alias expansion of c.
+func (c *Command) AliasExpansion(alias string) ([]string, bool) {
+	expansion, ok := c.aliasExpansions[alias]
+	return expansion, ok
+}
+
+// expandAlias returns args with its first argument replaced by the alias expansion
+// it stands for, if any.  The first argument following the completion request
+// command is expanded for shell completion.
+func (c *Command) expandAlias(args []string) []string {
+	i := 0
+	if isCompletionRequest(args) {
+		i = 1
+	}
+	// Do not expand the last argument of a completion request, which is being completed.
+	if len(args) <= i || (i == 1 && len(args) == 2) {
+		return args
+	}
+	expansion, ok := c.aliasExpansions[args[i]]
+	if !ok {
+		return args
+	}
+	if c.hasSubCommand(args[i]) {
+		return args
+	}
+	expanded := make([]string, 0, len(args)+len(expansion)-1)
+	expanded = append(expanded, args[:i]...)
+	expanded = append(expanded, expansion...)
+	return append(expanded, args[i+1:]...)
+}
@@ -0,0 +1,92 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newAliasExpansionTree(quiet *bool, gotArgs *[]string) *Command {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	checkoutCmd := &Command{
+		Use:       "checkout",
+		ValidArgs: []string{"main", "develop"},
+		Run:       func(_ *Command, args []string) { *gotArgs = args },
+	}
+	checkoutCmd.Flags().BoolVar(quiet, "quiet", false, "")
+	statusCmd := &Command{Use: "status", Aliases: []string{"st"}, Run: emptyRun}
+	rootCmd.AddCommand(checkoutCmd, statusCmd)
+	rootCmd.AddAliasExpansion("co", []string{"checkout", "--quiet"})
+	rootCmd.AddAliasExpansion("st", []string{"checkout"})
+	return rootCmd
+}
+
+func TestAliasExpansion(t *testing.T) {
+	var quiet bool
+	var gotArgs []string
+	rootCmd := newAliasExpansionTree(&quiet, &gotArgs)
+
+	cmd, _, err := executeCommandC(rootCmd, "co", "main")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cmd.Name() != "checkout" || !quiet || !reflect.DeepEqual(gotArgs, []string{"main"}) {
+		t.Errorf("Expected 'checkout --quiet main', got %q with quiet=%v and args %v", cmd.Name(), quiet, gotArgs)
+	}
+	if expansion, ok := rootCmd.AliasExpansion("co"); !ok || !reflect.DeepEqual(expansion, []string{"checkout", "--quiet"}) {
+		t.Errorf("Unexpected alias expansion %v", expansion)
+	}
+}
+
+func TestAliasExpansionIgnoredForCommands(t *testing.T) {
+	var quiet bool
+	var gotArgs []string
+	rootCmd := newAliasExpansionTree(&quiet, &gotArgs)
+
+	// The alias of a sub-command wins over an alias expansion.
+	cmd, _, err := executeCommandC(rootCmd, "st")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cmd.Name() != "status" {
+		t.Errorf("Expected the status command, got %q", cmd.Name())
+	}
+
+	// Only the first argument is expanded.
+	rootCmd = newAliasExpansionTree(&quiet, &gotArgs)
+	if _, err := executeCommand(rootCmd, "checkout", "co"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotArgs, []string{"co"}) {
+		t.Errorf("Expected the argument not to be expanded, got %v", gotArgs)
+	}
+}
+
+func TestAliasExpansionCompletion(t *testing.T) {
+	var quiet bool
+	var gotArgs []string
+	rootCmd := newAliasExpansionTree(&quiet, &gotArgs)
+
+	output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "co", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{"main", "develop", ":4", "Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
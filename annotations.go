@@ -0,0 +1,55 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "strconv"
+
+// CommandHiddenFromDocsAnnotation is the annotation excluding a command and its
+// sub-commands from the generated documentation, see SetHiddenFromDocs.
+const CommandHiddenFromDocsAnnotation = "cobra_annotation_hidden_from_docs"
+
+// SetAnnotation sets the annotation key of the command to value.
+func (c *Command) SetAnnotation(key, value string) {
+	if c.Annotations == nil {
+		c.Annotations = map[string]string{}
+	}
+	c.Annotations[key] = value
+}
+
+// Annotation returns the value of the annotation key of the command or, if it has
+// none, of its closest parent, and whether one was found.
+func (c *Command) Annotation(key string) (string, bool) {
+	for p := c; p != nil; p = p.parent {
+		if value, ok := p.Annotations[key]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// SetHiddenFromDocs sets whether the command and its sub-commands which do not set
+// their own are excluded from the generated documentation, while they are still
+// shown in the help, unlike hidden commands.
+func (c *Command) SetHiddenFromDocs(hidden bool) {
+	c.SetAnnotation(CommandHiddenFromDocsAnnotation, strconv.FormatBool(hidden))
+}
+
+// IsHiddenFromDocs returns true if the command is excluded from the generated
+// documentation, see SetHiddenFromDocs.
+func (c *Command) IsHiddenFromDocs() bool {
+	value, _ := c.Annotation(CommandHiddenFromDocsAnnotation)
+	hidden, _ := strconv.ParseBool(value)
+	return hidden
+}
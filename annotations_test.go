@@ -0,0 +1,60 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "testing"
+
+func TestAnnotation(t *testing.T) {
+	root := &Command{Use: "root"}
+	child := &Command{Use: "child"}
+	grandchild := &Command{Use: "grandchild", Annotations: map[string]string{"team": "storage"}}
+	root.AddCommand(child)
+	child.AddCommand(grandchild)
+
+	if _, ok := grandchild.Annotation("owner"); ok {
+		t.Errorf("Expected no annotation")
+	}
+
+	root.SetAnnotation("owner", "platform")
+	child.SetAnnotation("team", "compute")
+	if v, ok := grandchild.Annotation("owner"); !ok || v != "platform" {
+		t.Errorf("Expected the annotation of the root, got %q, %v", v, ok)
+	}
+	if v, _ := grandchild.Annotation("team"); v != "storage" {
+		t.Errorf("Expected the annotation of the command itself, got %q", v)
+	}
+	if v, _ := child.Annotation("team"); v != "compute" {
+		t.Errorf("Expected the annotation set on the child, got %q", v)
+	}
+	if _, ok := root.Annotation("team"); ok {
+		t.Errorf("Expected the annotations of the children not to apply to the root")
+	}
+}
+
+func TestHiddenFromDocs(t *testing.T) {
+	root := &Command{Use: "root"}
+	internal := &Command{Use: "internal"}
+	debug := &Command{Use: "debug"}
+	public := &Command{Use: "public"}
+	root.AddCommand(internal)
+	internal.AddCommand(debug, public)
+
+	internal.SetHiddenFromDocs(true)
+	public.SetHiddenFromDocs(false)
+	if root.IsHiddenFromDocs() || !internal.IsHiddenFromDocs() || !debug.IsHiddenFromDocs() || public.IsHiddenFromDocs() {
+		t.Errorf("Unexpected hidden from docs: root %v, internal %v, debug %v, public %v",
+			root.IsHiddenFromDocs(), internal.IsHiddenFromDocs(), debug.IsHiddenFromDocs(), public.IsHiddenFromDocs())
+	}
+}
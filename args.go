@@ -33,15 +33,53 @@ func legacyArgs(cmd *Command, args []string) error {
 
 	// root command with subcommands, do subcommand checking.
 	if !cmd.HasParent() && len(args) > 0 {
-		return fmt.Errorf("unknown command %q for %q%s", args[0], cmd.CommandPath(), cmd.findSuggestions(args[0]))
+		return &UnknownCommandError{Command: cmd.CommandPath(), Name: args[0], Suggestions: cmd.suggestionsForError(args[0])}
 	}
 	return nil
 }
 
+// dropEmptyArgs returns args without its empty and whitespace-only elements,
+// except those which may be the value of the flag preceding them.
+func dropEmptyArgs(args []string) []string {
+	kept := make([]string, 0, len(args))
+	for i, arg := range args {
+		if strings.TrimSpace(arg) == "" && !(i > 0 && isFlagWithoutValue(args[i-1])) {
+			continue
+		}
+		kept = append(kept, arg)
+	}
+	return kept
+}
+
+// isFlagWithoutValue returns true if arg is a flag which does not hold its value
+// itself, and may therefore be followed by it.
+func isFlagWithoutValue(arg string) bool {
+	if arg == "--" || !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	if strings.HasPrefix(arg, "--") {
+		return !strings.Contains(arg, "=")
+	}
+	// A shorthand flag may be followed by its value, as in "-n3".
+	return len(arg) == 2
+}
+
+// isCompletionRequest returns true if args invoke the hidden completion command,
+// for which empty arguments are meaningful.
+func isCompletionRequest(args []string) bool {
+	return len(args) > 0 && (args[0] == ShellCompRequestCmd || args[0] == ShellCompNoDescRequestCmd)
+}
+
+// RawArgs returns the arguments received by the root command when it was executed,
+// before any empty argument was dropped because of DropEmptyArgs.
+func (c *Command) RawArgs() []string {
+	return c.Root().rawArgs
+}
+
 // NoArgs returns an error if any args are included.
 func NoArgs(cmd *Command, args []string) error {
 	if len(args) > 0 {
-		return fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())
+		return &UnknownCommandError{Command: cmd.CommandPath(), Name: args[0]}
 	}
 	return nil
 }
@@ -58,13 +96,28 @@ func OnlyValidArgs(cmd *Command, args []string) error {
 		}
 		for _, v := range args {
 			if !stringInSlice(v, validArgs) {
-				return fmt.Errorf("invalid argument %q for %q%s", v, cmd.CommandPath(), cmd.findSuggestions(args[0]))
+				return &InvalidArgsError{
+					Command:     cmd.CommandPath(),
+					Args:        args,
+					Arg:         v,
+					Suggestions: cmd.suggestionsForError(v),
+					Reason:      fmt.Sprintf("invalid argument %q for %q", v, cmd.CommandPath()),
+				}
 			}
 		}
 	}
 	return nil
 }
 
+// invalidArgsCount returns an InvalidArgsError about the number of arguments of cmd.
+func invalidArgsCount(cmd *Command, args []string, format string, a ...interface{}) error {
+	err := &InvalidArgsError{Args: args, Reason: fmt.Sprintf(format, a...)}
+	if cmd != nil {
+		err.Command = cmd.CommandPath()
+	}
+	return err
+}
+
 // ArbitraryArgs never returns an error.
 func ArbitraryArgs(cmd *Command, args []string) error {
 	return nil
@@ -74,7 +127,7 @@ func ArbitraryArgs(cmd *Command, args []string) error {
 func MinimumNArgs(n int) PositionalArgs {
 	return func(cmd *Command, args []string) error {
 		if len(args) < n {
-			return fmt.Errorf("requires at least %d arg(s), only received %d", n, len(args))
+			return invalidArgsCount(cmd, args, "requires at least %d arg(s), only received %d", n, len(args))
 		}
 		return nil
 	}
@@ -84,7 +137,7 @@ func MinimumNArgs(n int) PositionalArgs {
 func MaximumNArgs(n int) PositionalArgs {
 	return func(cmd *Command, args []string) error {
 		if len(args) > n {
-			return fmt.Errorf("accepts at most %d arg(s), received %d", n, len(args))
+			return invalidArgsCount(cmd, args, "accepts at most %d arg(s), received %d", n, len(args))
 		}
 		return nil
 	}
@@ -94,7 +147,7 @@ func MaximumNArgs(n int) PositionalArgs {
 func ExactArgs(n int) PositionalArgs {
 	return func(cmd *Command, args []string) error {
 		if len(args) != n {
-			return fmt.Errorf("accepts %d arg(s), received %d", n, len(args))
+			return invalidArgsCount(cmd, args, "accepts %d arg(s), received %d", n, len(args))
 		}
 		return nil
 	}
@@ -104,7 +157,7 @@ func ExactArgs(n int) PositionalArgs {
 func RangeArgs(min int, max int) PositionalArgs {
 	return func(cmd *Command, args []string) error {
 		if len(args) < min || len(args) > max {
-			return fmt.Errorf("accepts between %d and %d arg(s), received %d", min, max, len(args))
+			return invalidArgsCount(cmd, args, "accepts between %d and %d arg(s), received %d", min, max, len(args))
 		}
 		return nil
 	}
@@ -122,6 +175,32 @@ func MatchAll(pargs ...PositionalArgs) PositionalArgs {
 	}
 }
 
+// NamedArgs returns an error if there are not exactly one arg per name of the
+// `ArgNames` field of `Command`.  The value of each arg is then available through
+// Arg.  It can be combined with other validators using MatchAll.
+func NamedArgs(cmd *Command, args []string) error {
+	names := cmd.ArgNames
+	cmd.namedArgs = nil
+	if len(args) < len(names) {
+		return invalidArgsCount(cmd, args, "missing argument %s for %q", names[len(args)], cmd.CommandPath())
+	}
+	if len(args) > len(names) {
+		return invalidArgsCount(cmd, args, "accepts %d arg(s), received %d", len(names), len(args))
+	}
+	cmd.namedArgs = make(map[string]string, len(names))
+	for i, name := range names {
+		cmd.namedArgs[name] = args[i]
+	}
+	return nil
+}
+
+// Arg returns the value of the positional argument called name by the NamedArgs
+// validator of cmd, or an empty string if there is no such argument.  It is meant
+// to be called from the *Run functions.
+func Arg(cmd *Command, name string) string {
+	return cmd.namedArgs[name]
+}
+
 // ExactValidArgs returns an error if there are not exactly N positional args OR
 // there are any positional args that are not in the `ValidArgs` field of `Command`
 //
@@ -16,6 +16,7 @@ package cobra
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -539,3 +540,96 @@ func TestLegacyArgsSubcmdAcceptsArgs(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 }
+
+func TestDropEmptyArgs(t *testing.T) {
+	var gotArgs []string
+	var name string
+	rootCmd := &Command{Use: "root", Run: emptyRun, DropEmptyArgs: true}
+	childCmd := &Command{
+		Use: "child",
+		Run: func(_ *Command, args []string) { gotArgs = args },
+	}
+	childCmd.Flags().StringVarP(&name, "name", "n", "default", "")
+	rootCmd.AddCommand(childCmd)
+
+	rawArgs := []string{"", " ", "child", "\t", "a", "--name", "", "b", ""}
+	_, err := executeCommand(rootCmd, rawArgs...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotArgs, []string{"a", "b"}) {
+		t.Errorf("Expected args [a b], got %q", gotArgs)
+	}
+	if name != "" {
+		t.Errorf("Expected the empty value of the flag to be kept, got %q", name)
+	}
+	if !reflect.DeepEqual(childCmd.RawArgs(), rawArgs) {
+		t.Errorf("Expected raw args %q, got %q", rawArgs, childCmd.RawArgs())
+	}
+}
+
+func TestEmptyArgsKeptByDefault(t *testing.T) {
+	var gotArgs []string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{
+		Use: "child",
+		Run: func(_ *Command, args []string) { gotArgs = args },
+	})
+
+	_, err := executeCommand(rootCmd, "", "child", " ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(gotArgs, []string{"", " "}) {
+		t.Errorf("Expected the empty args to be passed to the command, got %q", gotArgs)
+	}
+}
+
+func TestNamedArgs(t *testing.T) {
+	var source, dest string
+	c := getCommand(NamedArgs, false)
+	c.ArgNames = []string{"SOURCE", "DEST"}
+	c.Run = func(cmd *Command, _ []string) {
+		source, dest = Arg(cmd, "SOURCE"), Arg(cmd, "DEST")
+	}
+
+	output, err := executeCommand(c, "a", "b")
+	expectSuccess(output, err, t)
+	if source != "a" || dest != "b" {
+		t.Errorf("Expected SOURCE=a and DEST=b, got SOURCE=%q and DEST=%q", source, dest)
+	}
+
+	_, err = executeCommand(c, "a")
+	if err == nil || err.Error() != `missing argument DEST for "c"` {
+		t.Errorf("Expected a missing argument error, got %v", err)
+	}
+	if Arg(c, "SOURCE") != "" {
+		t.Error("Expected no value after a failed validation")
+	}
+
+	_, err = executeCommand(c, "a", "b", "c")
+	if err == nil || err.Error() != "accepts 2 arg(s), received 3" {
+		t.Errorf("Expected a count error, got %v", err)
+	}
+}
+
+func TestNamedArgsUseLine(t *testing.T) {
+	c := getCommand(MatchAll(OnlyValidArgs, NamedArgs), true)
+	c.ArgNames = []string{"SOURCE", "DEST"}
+	if useLine := c.UseLine(); useLine != "c SOURCE DEST" {
+		t.Errorf("Unexpected use line %q", useLine)
+	}
+
+	c.Use = "c SOURCE... DEST"
+	if useLine := c.UseLine(); useLine != "c SOURCE... DEST" {
+		t.Errorf("Unexpected use line %q", useLine)
+	}
+
+	c.Args = func(*Command, []string) error {
+		t.Fatal("Expected the usage line not to run the validator")
+		return nil
+	}
+	if useLine := c.UseLine(); useLine != "c SOURCE... DEST" {
+		t.Errorf("Unexpected use line %q", useLine)
+	}
+}
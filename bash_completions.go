@@ -81,8 +81,9 @@ __%[1]s_handle_go_custom_completion()
     local shellCompDirectiveNoFileComp=%[5]d
     local shellCompDirectiveFilterFileExt=%[6]d
     local shellCompDirectiveFilterDirs=%[7]d
+    local shellCompDirectiveShellCommand=%[9]d
 
-    local out requestComp lastParam lastChar comp directive args
+    local out requestComp lastParam lastChar comp directive args shellCommand
 
     # Prepare the command to request completions for the program.
     # Calling ${words[0]} instead of directly %[1]s allows handling aliases
@@ -135,6 +136,13 @@ __%[1]s_handle_go_custom_completion()
         fi
     fi
 
+    if [ $((directive & shellCompDirectiveShellCommand)) -ne 0 ]; then
+        # The completions are the lines printed by the shell command
+        shellCommand=$(printf "%%s\n" "${out}" | head -n 1)
+        __%[1]s_debug "${FUNCNAME[0]}: executing shell command: ${shellCommand}"
+        out=$(eval "${shellCommand}" 2>/dev/null)
+    fi
+
     if [ $((directive & shellCompDirectiveFilterFileExt)) -ne 0 ]; then
         # File extension filtering
         local fullFilter filter filteringCmd
@@ -398,7 +406,8 @@ __%[1]s_handle_word()
 
 `, name, ShellCompNoDescRequestCmd,
 		ShellCompDirectiveError, ShellCompDirectiveNoSpace, ShellCompDirectiveNoFileComp,
-		ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs, activeHelpEnvVar(name)))
+		ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs, activeHelpEnvVar(name),
+		ShellCompDirectiveShellCommand))
 }
 
 func writePostscript(buf io.StringWriter, name string) {
@@ -534,18 +543,18 @@ func writeLocalNonPersistentFlag(buf io.StringWriter, flag *pflag.Flag) {
 
 // prepareCustomAnnotationsForFlags setup annotations for go completions for registered flags
 func prepareCustomAnnotationsForFlags(cmd *Command) {
-	flagCompletionMutex.RLock()
-	defer flagCompletionMutex.RUnlock()
-	for flag := range flagCompletionFunctions {
-		// Make sure the completion script calls the __*_go_custom_completion function for
-		// every registered flag.  We need to do this here (and not when the flag was registered
-		// for completion) so that we can know the root command name for the prefix
-		// of __<prefix>_go_custom_completion
-		if flag.Annotations == nil {
-			flag.Annotations = map[string][]string{}
+	cmd.Root().visitTree(func(c *Command) {
+		for flag := range c.flagCompletions.snapshot() {
+			// Make sure the completion script calls the __*_go_custom_completion function for
+			// every registered flag.  We need to do this here (and not when the flag was registered
+			// for completion) so that we can know the root command name for the prefix
+			// of __<prefix>_go_custom_completion
+			if flag.Annotations == nil {
+				flag.Annotations = map[string][]string{}
+			}
+			flag.Annotations[BashCompCustom] = []string{fmt.Sprintf("__%[1]s_handle_go_custom_completion", cmd.Root().Name())}
 		}
-		flag.Annotations[BashCompCustom] = []string{fmt.Sprintf("__%[1]s_handle_go_custom_completion", cmd.Root().Name())}
-	}
+	})
 }
 
 func writeFlags(buf io.StringWriter, cmd *Command) {
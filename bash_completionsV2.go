@@ -102,6 +102,7 @@ __%[1]s_process_completion_results() {
     local shellCompDirectiveFilterFileExt=%[6]d
     local shellCompDirectiveFilterDirs=%[7]d
     local shellCompDirectiveKeepOrder=%[8]d
+    local shellCompDirectiveShellCommand=%[10]d
 
     if (((directive & shellCompDirectiveError) != 0)); then
         # Error code.  No completion.
@@ -144,6 +145,16 @@ __%[1]s_process_completion_results() {
     local activeHelp=()
     __%[1]s_extract_activeHelp
 
+    if (((directive & shellCompDirectiveShellCommand) != 0)); then
+        # The completions are the lines printed by the shell command
+        local shellCommand=${completions[0]} comp
+        __%[1]s_debug "Executing shell command: ${shellCommand}"
+        completions=()
+        while IFS='' read -r comp; do
+            completions+=("$comp")
+        done < <(eval "${shellCommand}" 2>/dev/null)
+    fi
+
     if (((directive & shellCompDirectiveFilterFileExt) != 0)); then
         # File extension filtering
         local fullFilter filter filteringCmd
@@ -375,7 +386,7 @@ fi
 `, name, compCmd,
 		ShellCompDirectiveError, ShellCompDirectiveNoSpace, ShellCompDirectiveNoFileComp,
 		ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs, ShellCompDirectiveKeepOrder,
-		activeHelpMarker))
+		activeHelpMarker, ShellCompDirectiveShellCommand))
 }
 
 // GenBashCompletionFileV2 generates Bash completion version 2.
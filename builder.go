@@ -0,0 +1,100 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	flag "github.com/spf13/pflag"
+)
+
+// CommandOption configures a command created by New.
+type CommandOption func(cmd *Command)
+
+// New returns a command with the given Use line, configured by opts in order.
+// It is an alternative to the struct literal for building command trees
+// programmatically, e.g.
+//
+//	cmd := cobra.New("serve",
+//		cobra.Short("Start the server"),
+//		cobra.WithFlags(func(fs *pflag.FlagSet) { fs.Int("port", 8080, "port to listen on") }),
+//		cobra.RunE(serve),
+//	)
+func New(use string, opts ...CommandOption) *Command {
+	cmd := &Command{Use: use}
+	for _, opt := range opts {
+		opt(cmd)
+	}
+	return cmd
+}
+
+// Short sets the short description of the command.
+func Short(short string) CommandOption {
+	return func(cmd *Command) { cmd.Short = short }
+}
+
+// Long sets the long description of the command.
+func Long(long string) CommandOption {
+	return func(cmd *Command) { cmd.Long = long }
+}
+
+// DisplayName sets the name shown for the command instead of its name, see Command.DisplayName.
+func DisplayName(name string) CommandOption {
+	return func(cmd *Command) { cmd.DisplayName = name }
+}
+
+// Aliases adds aliases to the command.
+func Aliases(aliases ...string) CommandOption {
+	return func(cmd *Command) { cmd.Aliases = append(cmd.Aliases, aliases...) }
+}
+
+// Args sets the validator of the positional arguments of the command.
+func Args(args PositionalArgs) CommandOption {
+	return func(cmd *Command) { cmd.Args = args }
+}
+
+// Hidden hides the command from the help and the completions.
+func Hidden() CommandOption {
+	return func(cmd *Command) { cmd.Hidden = true }
+}
+
+// Run sets the Run function of the command.
+func Run(run func(cmd *Command, args []string)) CommandOption {
+	return func(cmd *Command) { cmd.Run = run }
+}
+
+// RunE sets the RunE function of the command.
+func RunE(run func(cmd *Command, args []string) error) CommandOption {
+	return func(cmd *Command) { cmd.RunE = run }
+}
+
+// WithFlags calls define with the local flags of the command to define them.
+func WithFlags(define func(fs *flag.FlagSet)) CommandOption {
+	return func(cmd *Command) { define(cmd.Flags()) }
+}
+
+// WithPersistentFlags calls define with the persistent flags of the command to
+// define them.
+func WithPersistentFlags(define func(fs *flag.FlagSet)) CommandOption {
+	return func(cmd *Command) { define(cmd.PersistentFlags()) }
+}
+
+// WithSub adds sub-commands to the command.
+func WithSub(cmds ...*Command) CommandOption {
+	return func(cmd *Command) { cmd.AddCommand(cmds...) }
+}
+
+// With applies fn to the command, for the fields which have no option.
+func With(fn func(cmd *Command)) CommandOption {
+	return fn
+}
@@ -0,0 +1,73 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+	"testing"
+
+	flag "github.com/spf13/pflag"
+)
+
+func TestNew(t *testing.T) {
+	var port int
+	var gotArgs []string
+	serve := New("serve [dir]",
+		Short("Start the server"),
+		Aliases("s"),
+		Args(MaximumNArgs(1)),
+		WithFlags(func(fs *flag.FlagSet) { fs.IntVar(&port, "port", 8080, "port to listen on") }),
+		RunE(func(_ *Command, args []string) error {
+			gotArgs = args
+			return nil
+		}),
+	)
+	root := New("app",
+		WithPersistentFlags(func(fs *flag.FlagSet) { fs.Bool("debug", false, "") }),
+		WithSub(serve, New("secret", Hidden(), Run(emptyRun))),
+		With(func(cmd *Command) { cmd.SilenceUsage = true }),
+		DisplayName("kubectl app"),
+	)
+
+	if _, err := executeCommand(root, "s", "--port", "9090", "--debug", "www"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if port != 9090 {
+		t.Errorf("Expected port 9090, got %d", port)
+	}
+	if strings.Join(gotArgs, " ") != "www" {
+		t.Errorf("Expected args [www], got %v", gotArgs)
+	}
+	if serve.Parent() != root || serve.Short != "Start the server" {
+		t.Errorf("Unexpected serve command: %+v", serve)
+	}
+	if serve.CommandPath() != "kubectl app serve" {
+		t.Errorf("Expected the display name in the command path, got %q", serve.CommandPath())
+	}
+	if !root.SilenceUsage {
+		t.Errorf("Expected With to set SilenceUsage")
+	}
+
+	output, err := executeCommand(root, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "serve       Start the server")
+	checkStringOmits(t, output, "secret")
+
+	if _, err := executeCommand(root, "serve", "a", "b"); err == nil {
+		t.Errorf("Expected an error from Args")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 	"unicode"
@@ -52,17 +53,25 @@ const (
 // EnablePrefixMatching allows setting automatic prefix matching. Automatic prefix matching can be a dangerous thing
 // to automatically enable in CLI tools.
 // Set this to true to enable it.
+//
+// Deprecated: set ExecOptions.PrefixMatching with SetExecOptions instead.
 var EnablePrefixMatching = defaultPrefixMatching
 
 // EnableCommandSorting controls sorting of the slice of commands, which is turned on by default.
 // To disable sorting, set it to false.
+//
+// Deprecated: set ExecOptions.CommandSorting with SetExecOptions instead.
 var EnableCommandSorting = defaultCommandSorting
 
-// EnableCaseInsensitive allows case-insensitive commands names. (case sensitive by default)
+// EnableCaseInsensitive allows case-insensitive commands names and aliases. (case sensitive by default)
+//
+// Deprecated: set ExecOptions.CaseInsensitiveCommands and ExecOptions.CaseInsensitiveAliases with SetExecOptions instead.
 var EnableCaseInsensitive = defaultCaseInsensitive
 
 // EnableTraverseRunHooks executes persistent pre-run and post-run hooks from all parents.
 // By default this is disabled, which means only the first run hook to be found is executed.
+//
+// Deprecated: set ExecOptions.TraverseRunHooks with SetExecOptions instead.
 var EnableTraverseRunHooks = defaultTraverseRunHooks
 
 // MousetrapHelpText enables an information splash screen on Windows
@@ -84,6 +93,7 @@ var MousetrapDisplayDuration = 5 * time.Second
 // template generation.
 func AddTemplateFunc(name string, tmplFunc interface{}) {
 	templateFuncs[name] = tmplFunc
+	clearTemplateCache()
 }
 
 // AddTemplateFuncs adds multiple template functions that are available to Usage and
@@ -92,6 +102,7 @@ func AddTemplateFuncs(tmplFuncs template.FuncMap) {
 	for k, v := range tmplFuncs {
 		templateFuncs[k] = v
 	}
+	clearTemplateCache()
 }
 
 // OnInitialize sets the passed functions to be run when each command's
@@ -176,12 +187,54 @@ func rpad(s string, padding int) string {
 	return fmt.Sprintf(formattedString, s)
 }
 
-// tmpl executes the given template text on data, writing the result to w.
-func tmpl(w io.Writer, text string, data interface{}) error {
+// maxCachedTemplates bounds the number of parsed templates kept by tmpl, in case
+// the programs generate their templates.
+const maxCachedTemplates = 64
+
+// templateKey identifies a parsed template: its text, and whether the style
+// functions it was parsed with apply styles.
+type templateKey struct {
+	text  string
+	color bool
+}
+
+// templateCache holds the templates parsed by tmpl.  It is cleared when template
+// functions are added, since the functions are bound when parsing.
+var templateCache = struct {
+	sync.Mutex
+	templates map[templateKey]*template.Template
+}{}
+
+// clearTemplateCache drops the templates parsed so far.
+func clearTemplateCache() {
+	templateCache.Lock()
+	templateCache.templates = nil
+	templateCache.Unlock()
+}
+
+// parsedTemplate returns the template of text, parsing it on first use.
+func parsedTemplate(text string, color bool) *template.Template {
+	key := templateKey{text: text, color: color}
+
+	templateCache.Lock()
+	defer templateCache.Unlock()
+	if t, ok := templateCache.templates[key]; ok {
+		return t
+	}
 	t := template.New("top")
+	t.Funcs(styleFuncs(color))
 	t.Funcs(templateFuncs)
 	template.Must(t.Parse(text))
-	return t.Execute(w, data)
+	if templateCache.templates == nil || len(templateCache.templates) >= maxCachedTemplates {
+		templateCache.templates = make(map[templateKey]*template.Template)
+	}
+	templateCache.templates[key] = t
+	return t
+}
+
+// tmpl executes the given template text on data, writing the result to w.
+func tmpl(w io.Writer, text string, data interface{}) error {
+	return parsedTemplate(text, colorSupported(w)).Execute(w, data)
 }
 
 // ld compares two strings and returns the levenshtein distance between them.
@@ -15,6 +15,7 @@
 package cobra
 
 import (
+	"fmt"
 	"testing"
 	"text/template"
 )
@@ -41,6 +42,48 @@ func TestAddTemplateFunctions(t *testing.T) {
 	}
 }
 
+func TestTemplateFuncsAddedAfterRendering(t *testing.T) {
+	c := &Command{}
+	c.SetUsageTemplate(`{{lateFunc}}`)
+	AddTemplateFunc("lateFunc", func() string { return "first" })
+	if got := c.UsageString(); got != "first" {
+		t.Errorf("Expected 'first', got %q", got)
+	}
+
+	AddTemplateFunc("lateFunc", func() string { return "second" })
+	if got := c.UsageString(); got != "second" {
+		t.Errorf("Expected the cached template to use the new function, got %q", got)
+	}
+}
+
+func TestTemplateCacheBounded(t *testing.T) {
+	c := &Command{}
+	for i := 0; i < 2*maxCachedTemplates; i++ {
+		c.SetUsageTemplate(fmt.Sprintf("usage %d", i))
+		_ = c.UsageString()
+	}
+	templateCache.Lock()
+	defer templateCache.Unlock()
+	if n := len(templateCache.templates); n > maxCachedTemplates {
+		t.Errorf("Expected at most %d cached templates, got %d", maxCachedTemplates, n)
+	}
+}
+
+func BenchmarkHelp(b *testing.B) {
+	root := &Command{Use: "root", Short: "The root command", Run: emptyRun}
+	for i := 0; i < 100; i++ {
+		root.Flags().String(fmt.Sprintf("flag%d", i), "", fmt.Sprintf("the flag number %d", i))
+		root.AddCommand(&Command{Use: fmt.Sprintf("cmd%d", i), Short: "A sub-command", Run: emptyRun})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := executeCommand(root, "--help"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestLevenshteinDistance(t *testing.T) {
 	tests := []struct {
 		name       string
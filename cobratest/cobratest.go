@@ -0,0 +1,105 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cobratest helps testing programs built with cobra: it executes their
+// commands with captured output, and restores the global state of cobra once
+// the test is done so that tests do not affect each other.
+package cobratest
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+// Execute executes root with args, as if they were given on the command line,
+// and returns what the executed command wrote to its output and error streams,
+// and the error it returned.
+//
+// The package-level settings of cobra, such as cobra.EnablePrefixMatching and
+// cobra.ColorTheme, the command-line flag set of pflag and the output of the
+// standard logger are restored when the test and its subtests complete, so the
+// test can change them freely.
+func Execute(t testing.TB, root *cobra.Command, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	RestoreGlobals(t)
+
+	var outBuf, errBuf bytes.Buffer
+	root.SetOut(&outBuf)
+	root.SetErr(&errBuf)
+	if args == nil {
+		args = []string{}
+	}
+	root.SetArgs(args)
+	err = root.Execute()
+	return outBuf.String(), errBuf.String(), err
+}
+
+// RestoreGlobals saves the package-level settings of cobra, the command-line flag
+// set of pflag and the output of the standard logger, and restores them when the
+// test and its subtests complete.  Execute calls it.
+func RestoreGlobals(t testing.TB) {
+	t.Helper()
+	saved := saveGlobals()
+	t.Cleanup(saved.restore)
+}
+
+// globals are the package-level variables restored by RestoreGlobals.
+type globals struct {
+	prefixMatching, commandSorting    bool
+	caseInsensitive, traverseRunHooks bool
+	colorTheme                        cobra.Theme
+	mousetrapHelpText                 string
+	commandLine                       *flag.FlagSet
+	logOutput                         logOutput
+}
+
+// logOutput is the configuration of the standard logger.
+type logOutput struct {
+	writer io.Writer
+	prefix string
+	flags  int
+}
+
+//nolint:staticcheck // The deprecated settings are still honored, so they must be restored.
+func saveGlobals() globals {
+	return globals{
+		prefixMatching:    cobra.EnablePrefixMatching,
+		commandSorting:    cobra.EnableCommandSorting,
+		caseInsensitive:   cobra.EnableCaseInsensitive,
+		traverseRunHooks:  cobra.EnableTraverseRunHooks,
+		colorTheme:        cobra.ColorTheme,
+		mousetrapHelpText: cobra.MousetrapHelpText,
+		commandLine:       flag.CommandLine,
+		logOutput:         logOutput{writer: log.Writer(), prefix: log.Prefix(), flags: log.Flags()},
+	}
+}
+
+//nolint:staticcheck // The deprecated settings are still honored, so they must be restored.
+func (g globals) restore() {
+	cobra.EnablePrefixMatching = g.prefixMatching
+	cobra.EnableCommandSorting = g.commandSorting
+	cobra.EnableCaseInsensitive = g.caseInsensitive
+	cobra.EnableTraverseRunHooks = g.traverseRunHooks
+	cobra.ColorTheme = g.colorTheme
+	cobra.MousetrapHelpText = g.mousetrapHelpText
+	flag.CommandLine = g.commandLine
+	log.SetOutput(g.logOutput.writer)
+	log.SetPrefix(g.logOutput.prefix)
+	log.SetFlags(g.logOutput.flags)
+}
@@ -0,0 +1,94 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobratest
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+func newRoot() *cobra.Command {
+	root := &cobra.Command{Use: "prog", SilenceUsage: true}
+	root.AddCommand(&cobra.Command{
+		Use: "greet",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("nobody to greet")
+			}
+			cmd.Println("hello", strings.Join(args, " "))
+			return nil
+		},
+	})
+	return root
+}
+
+func TestExecute(t *testing.T) {
+	stdout, stderr, err := Execute(t, newRoot(), "greet", "world")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stdout != "hello world\n" || stderr != "" {
+		t.Errorf("Unexpected output %q and errors %q", stdout, stderr)
+	}
+
+	stdout, stderr, err = Execute(t, newRoot(), "greet")
+	if err == nil || err.Error() != "nobody to greet" {
+		t.Errorf("Expected the error of the command, got %v", err)
+	}
+	if stdout != "" || stderr != "Error: nobody to greet\n" {
+		t.Errorf("Unexpected output %q and errors %q", stdout, stderr)
+	}
+}
+
+func TestExecuteWithoutArgs(t *testing.T) {
+	stdout, _, err := Execute(t, newRoot())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout, "Usage:") {
+		t.Errorf("Expected the help of the root command, got %q", stdout)
+	}
+}
+
+//nolint:staticcheck // The test checks that the deprecated settings are restored.
+func TestRestoreGlobals(t *testing.T) {
+	commandLine := flag.CommandLine
+	logWriter := log.Writer()
+
+	t.Run("modify", func(t *testing.T) {
+		RestoreGlobals(t)
+		cobra.EnablePrefixMatching = true
+		cobra.ColorTheme.Heading = "bold"
+		flag.CommandLine = flag.NewFlagSet("other", flag.ContinueOnError)
+		log.SetOutput(new(bytes.Buffer))
+
+		if _, _, err := Execute(t, newRoot(), "gre", "x"); err != nil {
+			t.Errorf("Expected prefix matching during the test, got %v", err)
+		}
+	})
+
+	if cobra.EnablePrefixMatching || cobra.ColorTheme.Heading != "" {
+		t.Errorf("Expected the settings of cobra to be restored")
+	}
+	if flag.CommandLine != commandLine || log.Writer() != logWriter {
+		t.Errorf("Expected the flag set and the logger to be restored")
+	}
+}
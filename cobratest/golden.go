@@ -0,0 +1,95 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobratest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// UpdateGoldenFlag is the name of the flag of the test binaries making
+// AssertHelpGolden write the golden files instead of comparing them, as in
+// 'go test ./... -update-golden'.
+const UpdateGoldenFlag = "update-golden"
+
+var updateGolden = flag.Bool(UpdateGoldenFlag, false, "update the golden files of cobratest.AssertHelpGolden")
+
+// VersionPlaceholder replaces the version of the program in the help output
+// compared by AssertHelpGolden, so that the golden files do not change with it.
+const VersionPlaceholder = "<version>"
+
+// GoldenHelpWidth is the terminal width AssertHelpGolden renders the help with,
+// unless the commands set their own with SetHelpWidth.
+const GoldenHelpWidth = 80
+
+// escapeSequences matches the styles and hyperlinks written to terminals.
+var escapeSequences = regexp.MustCompile("\x1b\\[[0-9;]*m|\x1b\\]8;[^\x1b]*\x1b\\\\")
+
+// AssertHelpGolden compares the help output of cmd, as shown by 'prog ... cmd --help',
+// with the content of the golden file, and reports a test error if they differ.  When
+// the test binary is run with -update-golden, it writes the help output to the
+// golden file instead, creating its directory if needed.
+//
+// The output is normalized: it is rendered for a terminal of GoldenHelpWidth columns
+// by setting the COLUMNS environment variable, so the test cannot be parallel, the
+// styles and hyperlinks are removed, and the version of the root command is replaced
+// by VersionPlaceholder.
+func AssertHelpGolden(t testing.TB, cmd *cobra.Command, golden string) {
+	t.Helper()
+	t.Setenv("COLUMNS", strconv.Itoa(GoldenHelpWidth))
+	args := append(strings.Fields(cmd.CommandPath())[1:], "--help")
+	stdout, stderr, err := Execute(t, cmd.Root(), args...)
+	if err != nil {
+		t.Fatalf("cannot get the help of %q: %v\n%s", cmd.CommandPath(), err, stderr)
+	}
+	got := NormalizeHelp(stdout, cmd.Root().Version)
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil { // #nosec G306 -- golden files are not secret
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("cannot read the golden file, run the test with -%s to create it: %v", UpdateGoldenFlag, err)
+	}
+	if got != string(want) {
+		t.Errorf("the help of %q differs from %s, run the test with -%s to update it\n--- got:\n%s\n--- want:\n%s",
+			cmd.CommandPath(), golden, UpdateGoldenFlag, got, want)
+	}
+}
+
+// NormalizeHelp returns the help output without its styles and hyperlinks, and with
+// version, if not empty, replaced by VersionPlaceholder.
+func NormalizeHelp(help, version string) string {
+	help = escapeSequences.ReplaceAllString(help, "")
+	help = strings.ReplaceAll(help, "\r\n", "\n")
+	if version != "" {
+		help = strings.ReplaceAll(help, version, VersionPlaceholder)
+	}
+	return help
+}
@@ -0,0 +1,67 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobratest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newVersionedRoot() (*cobra.Command, *cobra.Command) {
+	root := newRoot()
+	root.Version = "1.2.3"
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "Start the server of prog 1.2.3",
+		Run:   func(*cobra.Command, []string) {},
+	}
+	serve.Flags().Int("port", 8080, "port to listen on")
+	root.AddCommand(serve)
+	return root, serve
+}
+
+func TestAssertHelpGolden(t *testing.T) {
+	_, serve := newVersionedRoot()
+	AssertHelpGolden(t, serve, filepath.Join("testdata", "serve_help.golden"))
+}
+
+func TestAssertHelpGoldenUpdate(t *testing.T) {
+	defer func(update bool) { *updateGolden = update }(*updateGolden)
+	*updateGolden = true
+
+	_, serve := newVersionedRoot()
+	golden := filepath.Join(t.TempDir(), "testdata", "serve_help.golden")
+	AssertHelpGolden(t, serve, golden)
+
+	got, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Expected the golden file to be written: %v", err)
+	}
+	if !strings.Contains(string(got), "Start the server of prog <version>") {
+		t.Errorf("Expected the version to be normalized, got %q", got)
+	}
+}
+
+func TestNormalizeHelp(t *testing.T) {
+	help := "\x1b[1mUsage:\x1b[0m\r\n  see \x1b]8;;https://example.com\x1b\\the docs\x1b]8;;\x1b\\ for v1.0\n"
+	expected := "Usage:\n  see the docs for <version>\n"
+	if got := NormalizeHelp(help, "v1.0"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
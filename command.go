@@ -26,12 +26,15 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	flag "github.com/spf13/pflag"
 )
 
 const (
-	FlagSetByCobraAnnotation     = "cobra_annotation_flag_set_by_cobra"
+	FlagSetByCobraAnnotation = "cobra_annotation_flag_set_by_cobra"
+	// CommandDisplayNameAnnotation sets the display name of a command when its DisplayName is empty.
+	// Prefer Command.DisplayName.
 	CommandDisplayNameAnnotation = "cobra_annotation_command_display_name"
 )
 
@@ -42,6 +45,8 @@ type FParseErrWhitelist flag.ParseErrorsWhitelist
 type Group struct {
 	ID    string
 	Title string
+	// Description is shown under the title in the usage and in 'help <group ID>'.
+	Description string
 }
 
 // Command is just that, a command for your application.
@@ -60,6 +65,10 @@ type Command struct {
 	// Example: add [-F file | -D dir]... [-f format] profile
 	Use string
 
+	// DisplayName is the name shown for the command in the usage line, the command path and the help
+	// instead of its Name, e.g. "kubectl plugin" for a plugin whose executable is kubectl-plugin.
+	DisplayName string
+
 	// Aliases is an array of aliases that can be used instead of the first word in Use.
 	Aliases []string
 
@@ -79,6 +88,29 @@ type Command struct {
 	// Example is examples of how to use the command.
 	Example string
 
+	// Examples are structured examples of how to use the command.  They are shown
+	// after Example in the 'help' output and in the generated documentation.
+	Examples []Example
+
+	// DocsURL is the URL of the documentation of the command.
+	// It is shown in the 'help' output, as a clickable link if the terminal supports it.
+	DocsURL string
+
+	// SeeAlso lists the paths of related commands, as returned by CommandPath
+	// (e.g. "app config set"), which are shown in the 'help' output and in the
+	// generated documentation.  ValidateTree reports the paths which do not exist.
+	SeeAlso []string
+
+	// Params are the named parameters of the command: its first positional arguments,
+	// which are required.  They are shown in the usage line, their values are available
+	// through Param and they are not part of the arguments passed to Args and the *Run functions.
+	Params []Parameter
+
+	// ArgNames are the names of the positional arguments, such as "SOURCE" and "DEST"
+	// for "app cp SOURCE DEST".  They are appended to the usage line, unless Use already
+	// contains them, and the NamedArgs validator makes their values available through Arg.
+	ArgNames []string
+
 	// ValidArgs is list of all valid non-flag arguments that are accepted in shell completions
 	ValidArgs []string
 	// ValidArgsFunction is an optional function that provides valid non-flag arguments for shell completion.
@@ -101,6 +133,20 @@ type Command struct {
 	// Deprecated defines, if this command is deprecated and should print this string when used.
 	Deprecated string
 
+	// DeprecationPolicy defines when running this command, or any of its deprecated
+	// descendants, fails instead of printing the Deprecated warning.
+	DeprecationPolicy DeprecationPolicy
+
+	// Experimental marks this command, and its sub-commands, as experimental: it is tagged
+	// as such in the help and can only be run with the '--enable-experimental' flag or
+	// the <PROGRAM>_ENABLE_EXPERIMENTAL environment variable set to true.
+	Experimental bool
+
+	// Timeout, if positive, limits the duration of the execution of this command: its
+	// context is cancelled once it elapses, and the error the command returns then is
+	// replaced by a TimeoutError.
+	Timeout time.Duration
+
 	// Annotations are key/value pairs that can be used by applications to identify or
 	// group commands or set special options.
 	Annotations map[string]string
@@ -110,6 +156,9 @@ type Command struct {
 	// will print content of the "Version" variable. A shorthand "v" flag will also be added if the
 	// command does not define one.
 	Version string
+	// OwnVersionFlag makes the "version" flag of a sub-command which defines its own Version,
+	// such as an independently versioned plugin, also print the version of the root command.
+	OwnVersionFlag bool
 
 	// The *Run functions are executed in the following order:
 	//   * PersistentPreRun()
@@ -147,6 +196,22 @@ type Command struct {
 
 	// args is actual args parsed from flags.
 	args []string
+	// rawArgs are the arguments received by ExecuteC, before DropEmptyArgs is applied.
+	rawArgs []string
+	// params holds the values of Params.
+	params map[string]string
+	// namedArgs holds the values of the args validated by NamedArgs.
+	namedArgs map[string]string
+	// envPrefix is the prefix of the environment variables set by AutomaticEnv.
+	envPrefix string
+	// flagSources records the flags whose value does not come from the command-line.
+	flagSources map[*flag.Flag]FlagSource
+	// configuration describes the configuration file set by SetConfig.
+	configuration *Config
+	// configFileUsed is the configuration file applied during the last execution.
+	configFileUsed string
+	// ephemeralCommands are the commands added by AddEphemeral.
+	ephemeralCommands []*Command
 	// flagErrorBuf contains all error messages from pflag.
 	flagErrorBuf *bytes.Buffer
 	// flags is full set of flags.
@@ -159,6 +224,9 @@ type Command struct {
 	// iflags contains inherited flags.
 	// This field does not represent internal state, it's used as a cache to optimise InheritedFlags function call
 	iflags *flag.FlagSet
+	// flagSetSizes is the state of the flag sets lflags and iflags were built from,
+	// see flagSetsChanged.
+	flagSetSizes []flagSetSize
 	// parentsPflags is all persistent flags of cmd's parents.
 	parentsPflags *flag.FlagSet
 	// globNormFunc is the global normalization function
@@ -167,6 +235,24 @@ type Command struct {
 
 	// usageFunc is usage func defined by user.
 	usageFunc func(*Command) error
+	// suggester is the suggestion algorithm set by SetSuggester.
+	suggester Suggester
+	// unknownCommandFunc is the function set by SetUnknownCommandFunc.
+	unknownCommandFunc UnknownCommandFunc
+	// defaultCommand is the name of the sub-command set by SetDefaultCommand.
+	defaultCommand string
+	// renamedCommands maps the old names of sub-commands, registered by RenameCommand,
+	// to the path of the command they stand for.
+	renamedCommands map[string][]string
+	// renamedCommandsUsed are the warnings about the old command paths used by the
+	// current execution, which is only set on the root command.
+	renamedCommandsUsed []string
+	// completionFilter is the completion filter set by SetCompletionFilter.
+	completionFilter CompletionFilter
+	// metrics records the executions of the command, see SetMetrics.
+	metrics *Metrics
+	// telemetry receives the executions of the command, see SetTelemetry.
+	telemetry TelemetryHook
 	// usageTemplate is usage template defined by user.
 	usageTemplate string
 	// flagErrorFunc is func defined by user and it's called when the parsing of
@@ -174,6 +260,10 @@ type Command struct {
 	flagErrorFunc func(*Command, error) error
 	// helpTemplate is help template defined by user.
 	helpTemplate string
+	// usageFooter is the text shown at the end of the usage, defined by user.
+	usageFooter string
+	// helpBanner is the text shown at the beginning of the help, defined by user.
+	helpBanner string
 	// helpFunc is help func defined by user.
 	helpFunc func(*Command, []string)
 	// helpCommand is command with usage 'help'. If it's not defined by user,
@@ -187,6 +277,34 @@ type Command struct {
 
 	// versionTemplate is the version template defined by user.
 	versionTemplate string
+	// versionInfo is the build metadata set by SetVersionInfo.
+	versionInfo map[string]string
+	// interactive is the prompting mode set by SetInteractive.
+	interactive *bool
+	// lazyLoad builds the command this stub stands for, see AddLazyCommand.
+	lazyLoad func() *Command
+	// commandIndex finds the sub-commands by name, see subCommand.
+	commandIndex *commandIndex
+	// execOptions are the settings set by SetExecOptions.
+	execOptions *ExecOptions
+	// helpWidth is the width set by SetHelpWidth.
+	helpWidth int
+	// flagsInterspersed is the flag parsing mode set by SetFlagsInterspersed.
+	flagsInterspersed *bool
+	// middlewares wrap the execution of the command and its children, see Use.
+	middlewares []Middleware
+	// recoverFunc is the panic handler set by SetRecoverFunc.
+	recoverFunc func(cmd *Command, recovered interface{}) error
+	// onInitialize and onFinalize are the functions set by OnInitialize and OnFinalize.
+	onInitialize, onFinalize []func(cmd *Command)
+	// commandProviders are the providers added by AddCommandProvider.
+	commandProviders []CommandProvider
+	// pluginOptions are the options set by EnablePlugins.
+	pluginOptions *PluginOptions
+	// aliasExpansions are the alias expansions added by AddAliasExpansion.
+	aliasExpansions map[string][]string
+	// eventHandlers are the handlers registered by OnEvent.
+	eventHandlers map[EventType][]func(e Event)
 
 	// errPrefix is the error message prefix defined by user.
 	errPrefix string
@@ -204,6 +322,18 @@ type Command struct {
 	// CompletionOptions is a set of options to control the handling of shell completion
 	CompletionOptions CompletionOptions
 
+	// flagCompletions holds the flag completion functions registered on this command.
+	flagCompletions flagCompletionRegistry
+	// flagValidators holds the validators of the flags bound to this command by BindFlag.
+	flagValidators map[*flag.Flag][]func() error
+	// argCompletions holds the completion functions of the positional arguments
+	// registered on this command, by position.
+	argCompletions map[int]func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)
+
+	// flagsCaseFolded defines, if the normalization function of flags folds the case
+	// of the flag names, see applyFlagCaseInsensitivity.
+	flagsCaseFolded bool
+
 	// commandsAreSorted defines, if command slice are sorted or not.
 	commandsAreSorted bool
 	// commandCalledAs is the name or alias value used to call this command.
@@ -211,6 +341,8 @@ type Command struct {
 		name   string
 		called bool
 	}
+	// resolvedBy is how this command was found when it was executed.
+	resolvedBy ResolutionMode
 
 	ctx context.Context
 
@@ -226,6 +358,23 @@ type Command struct {
 	// TraverseChildren parses flags on all parents before executing child command.
 	TraverseChildren bool
 
+	// DropEmptyArgs removes the empty and whitespace-only arguments before looking up
+	// the command to execute.  It is only honored on the root command.
+	// By default, empty arguments are skipped when looking up the command but are passed
+	// to it as positional arguments, which can be surprising when the arguments are
+	// generated, e.g. from templates.
+	// An empty argument directly following a flag without '=' is always kept since it
+	// may be the value of that flag.  The arguments as received are available from RawArgs.
+	DropEmptyArgs bool
+
+	// BufferOutput buffers the standard output of the executed command, which is
+	// flushed once the command returns.  It is only honored on the root command.
+	// When set, failing to write because the reader of the output went away, as when
+	// piping to 'head', is not reported as an error: SIGPIPE is ignored during the
+	// execution so that the Go runtime does not kill the program when writing to a
+	// broken pipe on the standard output.
+	BufferOutput bool
+
 	// Hidden defines, if this command is hidden and should NOT show up in the list of available commands.
 	Hidden bool
 
@@ -248,11 +397,12 @@ type Command struct {
 	DisableFlagsInUseLine bool
 
 	// DisableSuggestions disables the suggestions based on Levenshtein distance
-	// that go along with 'unknown command' messages.
+	// that go along with 'unknown command' messages.  Setting it on a command
+	// disables the suggestions of its children too.
 	DisableSuggestions bool
 
 	// SuggestionsMinimumDistance defines minimum levenshtein distance to display suggestions.
-	// Must be > 0.
+	// When it is not > 0, the distance of the closest parent setting it is used, or 2.
 	SuggestionsMinimumDistance int
 }
 
@@ -306,11 +456,13 @@ func (c *Command) SetIn(newIn io.Reader) {
 }
 
 // SetUsageFunc sets usage function. Usage can be defined by application.
+// It also applies to the children commands which do not set their own.
 func (c *Command) SetUsageFunc(f func(*Command) error) {
 	c.usageFunc = f
 }
 
 // SetUsageTemplate sets usage template. Can be defined by Application.
+// It also applies to the children commands which do not set their own.
 func (c *Command) SetUsageTemplate(s string) {
 	c.usageTemplate = s
 }
@@ -322,6 +474,7 @@ func (c *Command) SetFlagErrorFunc(f func(*Command, error) error) {
 }
 
 // SetHelpFunc sets help function. Can be defined by Application.
+// It also applies to the children commands which do not set their own.
 func (c *Command) SetHelpFunc(f func(*Command, []string)) {
 	c.helpFunc = f
 }
@@ -347,15 +500,56 @@ func (c *Command) SetCompletionCommandGroupID(groupID string) {
 }
 
 // SetHelpTemplate sets help template to be used. Application can use it to set custom template.
+// It also applies to the children commands which do not set their own.
 func (c *Command) SetHelpTemplate(s string) {
 	c.helpTemplate = s
 }
 
+// SetUsageFooter sets a text, such as a support contact, to be shown at the end of
+// the usage message.  It also applies to the children commands which do not set their own.
+func (c *Command) SetUsageFooter(s string) {
+	c.usageFooter = s
+}
+
+// SetHelpBanner sets a text, such as a legal notice, to be shown at the beginning of
+// the help message.  It also applies to the children commands which do not set their own.
+func (c *Command) SetHelpBanner(s string) {
+	c.helpBanner = s
+}
+
 // SetVersionTemplate sets version template to be used. Application can use it to set custom template.
 func (c *Command) SetVersionTemplate(s string) {
 	c.versionTemplate = s
 }
 
+// SetVersionInfo sets build metadata, such as the commit or the build date, printed
+// by the version flag after the version.  It is available to the version template as
+// .VersionInfo and also applies to the children commands which do not set their own.
+func (c *Command) SetVersionInfo(info map[string]string) {
+	c.versionInfo = info
+}
+
+// SetRecoverFunc sets a function converting the panics occurring while the command
+// is executed, e.g. in its Run function or its hooks, to an error, which is then
+// handled like the errors returned by the command.  It also applies to the children
+// commands which do not set their own.  Without such a function, panics are not
+// recovered.
+func (c *Command) SetRecoverFunc(f func(cmd *Command, recovered interface{}) error) {
+	c.recoverFunc = f
+}
+
+// RecoverFunc returns the function set by SetRecoverFunc for this command or a parent,
+// or nil if none was set.
+func (c *Command) RecoverFunc() func(cmd *Command, recovered interface{}) error {
+	if c.recoverFunc != nil {
+		return c.recoverFunc
+	}
+	if c.HasParent() {
+		return c.parent.RecoverFunc()
+	}
+	return nil
+}
+
 // SetErrPrefix sets error message prefix to be used. Application can use it to set custom prefix.
 func (c *Command) SetErrPrefix(s string) {
 	c.errPrefix = s
@@ -367,6 +561,8 @@ func (c *Command) SetGlobalNormalizationFunc(n func(f *flag.FlagSet, name string
 	c.Flags().SetNormalizeFunc(n)
 	c.PersistentFlags().SetNormalizeFunc(n)
 	c.globNormFunc = n
+	c.flagsCaseFolded = false
+	c.lflags, c.iflags = nil, nil
 
 	for _, command := range c.commands {
 		command.SetGlobalNormalizationFunc(n)
@@ -484,8 +680,10 @@ func (c *Command) UsageString() string {
 	tmpErr := c.errWriter
 
 	bb := new(bytes.Buffer)
-	c.outWriter = bb
-	c.errWriter = bb
+	// Style and wrap the usage as the output it is written to is, e.g. by the help template.
+	w := &colorWriter{Writer: bb, color: colorSupported(c.OutOrStderr()), width: outputWidth(c.OutOrStderr())}
+	c.outWriter = w
+	c.errWriter = w
 
 	CheckErr(c.Usage())
 
@@ -551,35 +749,41 @@ func (c *Command) UsageTemplate() string {
 	if c.HasParent() {
 		return c.parent.UsageTemplate()
 	}
-	return `Usage:{{if .Runnable}}
+	return `{{Heading "Usage:"}}{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
   {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
 
-Aliases:
+{{Heading "Aliases:"}}
   {{.NameAndAliases}}{{end}}{{if .HasExample}}
 
-Examples:
-{{.Example}}{{end}}{{if .HasAvailableSubCommands}}{{$cmds := .Commands}}{{if eq (len .Groups) 0}}
+{{Heading "Examples:"}}
+{{.ExamplesString}}{{end}}{{if .HasAvailableSubCommands}}{{$cmds := .Commands}}{{if eq (len .Groups) 0}}
 
-Available Commands:{{range $cmds}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
-  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{else}}{{range $group := .Groups}}
+{{Heading "Available Commands:"}}{{range $cmds}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
+  {{StyleCommand (rpad .Name .NamePadding)}} {{.WrappedShort}}{{if .IsExperimental}} (experimental){{end}}{{if .IsDefaultCommand}} (default){{end}}{{end}}{{end}}{{else}}{{range $group := .Groups}}
 
-{{.Title}}{{range $cmds}}{{if (and (eq .GroupID $group.ID) (or .IsAvailableCommand (eq .Name "help")))}}
-  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if not .AllChildCommandsHaveGroup}}
+{{Heading .Title}}{{with .Description}}
+{{.}}{{end}}{{range $cmds}}{{if (and (eq .GroupID $group.ID) (or .IsAvailableCommand (eq .Name "help")))}}
+  {{StyleCommand (rpad .Name .NamePadding)}} {{.WrappedShort}}{{if .IsExperimental}} (experimental){{end}}{{if .IsDefaultCommand}} (default){{end}}{{end}}{{end}}{{end}}{{if not .AllChildCommandsHaveGroup}}
 
-Additional Commands:{{range $cmds}}{{if (and (eq .GroupID "") (or .IsAvailableCommand (eq .Name "help")))}}
-  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+{{Heading "Additional Commands:"}}{{range $cmds}}{{if (and (eq .GroupID "") (or .IsAvailableCommand (eq .Name "help")))}}
+  {{StyleCommand (rpad .Name .NamePadding)}} {{.WrappedShort}}{{if .IsExperimental}} (experimental){{end}}{{if .IsDefaultCommand}} (default){{end}}{{end}}{{end}}{{end}}{{end}}{{end}}{{if .HasAvailableRequiredFlags}}
 
-Flags:
-{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
+{{Heading "Required Flags:"}}
+{{.WrappedFlagUsages .RequiredFlags | trimTrailingWhitespaces | StyleFlags}}{{end}}{{if .HasAvailableOptionalLocalFlags}}
 
-Global Flags:
-{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasHelpSubCommands}}
+{{Heading "Flags:"}}
+{{.WrappedFlagUsages .OptionalLocalFlags | trimTrailingWhitespaces | StyleFlags}}{{end}}{{if .HasAvailableInheritedFlags}}
 
-Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
-  {{rpad .CommandPath .CommandPathPadding}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableSubCommands}}
+{{Heading "Global Flags:"}}
+{{.WrappedFlagUsages .InheritedFlags | trimTrailingWhitespaces | StyleFlags}}{{end}}{{if .HasHelpSubCommands}}
 
-Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
+{{Heading "Additional help topics:"}}{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
+  {{StyleCommand (rpad .CommandPath .CommandPathPadding)}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableSubCommands}}
+
+Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}{{with .UsageFooter}}
+
+{{. | trimTrailingWhitespaces}}{{end}}
 `
 }
 
@@ -592,9 +796,38 @@ func (c *Command) HelpTemplate() string {
 	if c.HasParent() {
 		return c.parent.HelpTemplate()
 	}
-	return `{{with (or .Long .Short)}}{{. | trimTrailingWhitespaces}}
+	return `{{with .HelpBanner}}{{. | trimTrailingWhitespaces}}
 
-{{end}}{{if or .Runnable .HasSubCommands}}{{.UsageString}}{{end}}`
+{{end}}{{with (or .Long .Short)}}{{$.WrapText 0 . | trimTrailingWhitespaces}}
+
+{{end}}{{if or .Runnable .HasSubCommands}}{{.UsageString}}{{end}}{{with .SeeAlsoCommands}}
+{{Heading "See also:"}}{{range .}}
+  {{.CommandPath}}{{end}}
+{{end}}{{if .DocsURL}}
+{{Heading "Docs:"}} {{.DocsLink}}
+{{end}}`
+}
+
+// UsageFooter returns the usage footer of the command, or of its closest parent defining one.
+func (c *Command) UsageFooter() string {
+	if c.usageFooter != "" {
+		return c.usageFooter
+	}
+	if c.HasParent() {
+		return c.parent.UsageFooter()
+	}
+	return ""
+}
+
+// HelpBanner returns the help banner of the command, or of its closest parent defining one.
+func (c *Command) HelpBanner() string {
+	if c.helpBanner != "" {
+		return c.helpBanner
+	}
+	if c.HasParent() {
+		return c.parent.HelpBanner()
+	}
+	return ""
 }
 
 // VersionTemplate return version template for the command.
@@ -607,7 +840,20 @@ func (c *Command) VersionTemplate() string {
 		return c.parent.VersionTemplate()
 	}
 	return `{{with .Name}}{{printf "%s " .}}{{end}}{{printf "version %s" .Version}}
-`
+{{if and .OwnVersionFlag .HasParent}}{{with .Root}}{{if .Version}}{{printf "%s version %s" .Name .Version}}
+{{end}}{{end}}{{end}}{{range $key, $value := .VersionInfo}}{{printf "%s: %s" $key $value}}
+{{end}}`
+}
+
+// VersionInfo returns the build metadata set by SetVersionInfo for this command or a parent.
+func (c *Command) VersionInfo() map[string]string {
+	if c.versionInfo != nil {
+		return c.versionInfo
+	}
+	if c.HasParent() {
+		return c.parent.VersionInfo()
+	}
+	return nil
 }
 
 // ErrPrefix return error message prefix for the command
@@ -737,7 +983,7 @@ func (c *Command) Find(args []string) (*Command, []string, error) {
 
 		cmd := c.findNext(nextSubCmd)
 		if cmd != nil {
-			return innerfind(cmd, c.argsMinusFirstX(innerArgs, nextSubCmd))
+			return innerfind(cmd.loadLazy(), c.argsMinusFirstX(innerArgs, nextSubCmd))
 		}
 		return c, innerArgs
 	}
@@ -749,31 +995,28 @@ func (c *Command) Find(args []string) (*Command, []string, error) {
 	return commandFound, a, nil
 }
 
-func (c *Command) findSuggestions(arg string) string {
-	if c.DisableSuggestions {
-		return ""
+// hasSubCommand returns true if name is the name or an alias of a sub-command of c.
+func (c *Command) hasSubCommand(name string) bool {
+	return c.subCommand(name, c.ExecOptions()) != nil
+}
+
+func (c *Command) findNext(next string) *Command {
+	opts := c.ExecOptions()
+	if cmd := c.subCommand(next, opts); cmd != nil {
+		cmd.commandCalledAs.name = next
+		return cmd
 	}
-	if c.SuggestionsMinimumDistance <= 0 {
-		c.SuggestionsMinimumDistance = 2
+	if cmd := c.renamedCommand(next); cmd != nil {
+		cmd.commandCalledAs.name = cmd.Name()
+		return cmd
 	}
-	var sb strings.Builder
-	if suggestions := c.SuggestionsFor(arg); len(suggestions) > 0 {
-		sb.WriteString("\n\nDid you mean this?\n")
-		for _, s := range suggestions {
-			_, _ = fmt.Fprintf(&sb, "\t%v\n", s)
-		}
+	if !opts.PrefixMatching {
+		return nil
 	}
-	return sb.String()
-}
 
-func (c *Command) findNext(next string) *Command {
 	matches := make([]*Command, 0)
 	for _, cmd := range c.commands {
-		if commandNameMatches(cmd.Name(), next) || cmd.HasAlias(next) {
-			cmd.commandCalledAs.name = next
-			return cmd
-		}
-		if EnablePrefixMatching && cmd.hasNameOrAliasPrefix(next) {
+		if cmd.hasNameOrAliasPrefix(next) {
 			matches = append(matches, cmd)
 		}
 	}
@@ -821,6 +1064,7 @@ func (c *Command) Traverse(args []string) (*Command, []string, error) {
 		if cmd == nil {
 			return c, args, nil
 		}
+		cmd = cmd.loadLazy()
 
 		if err := c.ParseFlags(flags); err != nil {
 			return nil, args, err
@@ -832,17 +1076,23 @@ func (c *Command) Traverse(args []string) (*Command, []string, error) {
 
 // SuggestionsFor provides suggestions for the typedName.
 func (c *Command) SuggestionsFor(typedName string) []string {
-	suggestions := []string{}
+	suggester := c.Suggester()
+	if suggester == nil {
+		suggester = LevenshteinSuggester(c.suggestionsMinimumDistance())
+	}
+
+	candidates := []string{}
+	for _, cmd := range c.commands {
+		if cmd.IsAvailableCommand() {
+			candidates = append(candidates, cmd.Name())
+		}
+	}
+	suggestions := suggester(typedName, candidates)
+
 	for _, cmd := range c.commands {
 		if cmd.IsAvailableCommand() {
-			levenshteinDistance := ld(typedName, cmd.Name(), true)
-			suggestByLevenshtein := levenshteinDistance <= c.SuggestionsMinimumDistance
-			suggestByPrefix := strings.HasPrefix(strings.ToLower(cmd.Name()), strings.ToLower(typedName))
-			if suggestByLevenshtein || suggestByPrefix {
-				suggestions = append(suggestions, cmd.Name())
-			}
 			for _, explicitSuggestion := range cmd.SuggestFor {
-				if strings.EqualFold(typedName, explicitSuggestion) {
+				if strings.EqualFold(typedName, explicitSuggestion) && !stringInSlice(cmd.Name(), suggestions) {
 					suggestions = append(suggestions, cmd.Name())
 				}
 			}
@@ -877,20 +1127,41 @@ func (c *Command) execute(a []string) (err error) {
 	if c == nil {
 		return fmt.Errorf("called Execute() on a nil Command")
 	}
+	if recoverFunc := c.RecoverFunc(); recoverFunc != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverFunc(c, r)
+			}
+		}()
+	}
 
 	if len(c.Deprecated) > 0 {
+		if err := c.checkDeprecationPolicy(); err != nil {
+			return err
+		}
 		c.Printf("Command %q is deprecated, %s\n", c.Name(), c.Deprecated)
 	}
+	c.warnRenamedCommands()
 
 	// initialize help and version flag at the last point possible to allow for user
 	// overriding
 	c.InitDefaultHelpFlag()
 	c.InitDefaultVersionFlag()
 
+	c.resetFlagSources()
 	err = c.ParseFlags(a)
 	if err != nil {
-		return c.FlagErrorFunc()(c, err)
+		c.warnParentLocalFlags(a)
+		return newUsageError(c.FlagErrorFunc()(c, err))
 	}
+	c.warnRenamedFlags()
+	if err := c.applyEnvFlags(); err != nil {
+		return err
+	}
+	if err := c.applyConfigFlags(); err != nil {
+		return err
+	}
+	c.sendEvent(FlagsParsed, c.Flags().Args(), nil)
 
 	// If help is called, regardless of other flags, return we want help.
 	// Also say we need help if the command isn't runnable.
@@ -923,26 +1194,44 @@ func (c *Command) execute(a []string) (err error) {
 	}
 
 	if !c.Runnable() {
+		// Arguments which are not sub-commands may be reported as a mistake rather than a request for help.
+		if args := c.Flags().Args(); len(args) > 0 && c.ExecOptions().StrictSubCommands && c.HasAvailableSubCommands() && !c.DisableFlagParsing {
+			return &UnknownCommandError{Command: c.CommandPath(), Name: args[0], Suggestions: c.suggestionsForError(args[0])}
+		}
 		return flag.ErrHelp
 	}
 
+	if err := c.checkExperimental(); err != nil {
+		return err
+	}
+
+	stopTimeout := c.startTimeout()
+	defer func() { err = stopTimeout(err) }()
+
 	c.preRun()
 
 	defer c.postRun()
+	defer c.startDebugTrace()()
 
 	argWoFlags := c.Flags().Args()
 	if c.DisableFlagParsing {
 		argWoFlags = a
 	}
 
+	argWoFlags, err = c.bindParams(argWoFlags)
+	if err != nil {
+		return newUsageError(err)
+	}
+
 	if err := c.ValidateArgs(argWoFlags); err != nil {
-		return err
+		return newUsageError(err)
 	}
 
+	traverseRunHooks := c.ExecOptions().TraverseRunHooks
 	parents := make([]*Command, 0, 5)
 	for p := c; p != nil; p = p.Parent() {
-		if EnableTraverseRunHooks {
-			// When EnableTraverseRunHooks is set:
+		if traverseRunHooks {
+			// When TraverseRunHooks is set:
 			// - Execute all persistent pre-runs from the root parent till this command.
 			// - Execute all persistent post-runs from this command till the root parent.
 			parents = append([]*Command{p}, parents...)
@@ -956,12 +1245,12 @@ func (c *Command) execute(a []string) (err error) {
 			if err := p.PersistentPreRunE(c, argWoFlags); err != nil {
 				return err
 			}
-			if !EnableTraverseRunHooks {
+			if !traverseRunHooks {
 				break
 			}
 		} else if p.PersistentPreRun != nil {
 			p.PersistentPreRun(c, argWoFlags)
-			if !EnableTraverseRunHooks {
+			if !traverseRunHooks {
 				break
 			}
 		}
@@ -974,19 +1263,33 @@ func (c *Command) execute(a []string) (err error) {
 		c.PreRun(c, argWoFlags)
 	}
 
+	if err := c.promptRequiredFlags(); err != nil {
+		return err
+	}
 	if err := c.ValidateRequiredFlags(); err != nil {
 		return err
 	}
 	if err := c.ValidateFlagGroups(); err != nil {
-		return err
+		return newUsageError(err)
+	}
+	if err := c.ValidateFlagValues(); err != nil {
+		return newUsageError(err)
 	}
 
+	c.sendEvent(BeforeRun, argWoFlags, nil)
 	if c.RunE != nil {
-		if err := c.RunE(c, argWoFlags); err != nil {
+		err := c.RunE(c, argWoFlags)
+		c.sendEvent(AfterRun, argWoFlags, err)
+		if err != nil {
+			if c.interrupted() {
+				// Let the persistent post-run hooks clean up after an interruption.
+				_ = c.persistentPostRun(argWoFlags)
+			}
 			return err
 		}
 	} else {
 		c.Run(c, argWoFlags)
+		c.sendEvent(AfterRun, argWoFlags, nil)
 	}
 	if c.PostRunE != nil {
 		if err := c.PostRunE(c, argWoFlags); err != nil {
@@ -995,22 +1298,27 @@ func (c *Command) execute(a []string) (err error) {
 	} else if c.PostRun != nil {
 		c.PostRun(c, argWoFlags)
 	}
+	return c.persistentPostRun(argWoFlags)
+}
+
+// persistentPostRun runs the persistent post-run hooks of the command and its parents.
+func (c *Command) persistentPostRun(args []string) error {
+	traverseRunHooks := c.ExecOptions().TraverseRunHooks
 	for p := c; p != nil; p = p.Parent() {
 		if p.PersistentPostRunE != nil {
-			if err := p.PersistentPostRunE(c, argWoFlags); err != nil {
+			if err := p.PersistentPostRunE(c, args); err != nil {
 				return err
 			}
-			if !EnableTraverseRunHooks {
+			if !traverseRunHooks {
 				break
 			}
 		} else if p.PersistentPostRun != nil {
-			p.PersistentPostRun(c, argWoFlags)
-			if !EnableTraverseRunHooks {
+			p.PersistentPostRun(c, args)
+			if !traverseRunHooks {
 				break
 			}
 		}
 	}
-
 	return nil
 }
 
@@ -1018,9 +1326,19 @@ func (c *Command) preRun() {
 	for _, x := range initializers {
 		x()
 	}
+	for _, p := range c.lineage() {
+		for _, x := range p.onInitialize {
+			x(c)
+		}
+	}
 }
 
 func (c *Command) postRun() {
+	for _, p := range c.lineage() {
+		for _, x := range p.onFinalize {
+			x(c)
+		}
+	}
 	for _, x := range finalizers {
 		x()
 	}
@@ -1066,14 +1384,31 @@ func (c *Command) ExecuteC() (cmd *Command, err error) {
 		preExecHookFn(c)
 	}
 
+	// add the commands of the providers for the duration of the execution
+	defer c.addProvidedCommands(c.ctx)()
+
 	// initialize help at the last point to allow for user overriding
 	c.InitDefaultHelpCmd()
 	// initialize completion at the last point to allow for user overriding
 	c.InitDefaultCompletionCmd()
+	// initialize the debug flag at the last point to allow for user overriding
+	if c.ExecOptions().DebugFlag {
+		c.InitDefaultDebugFlag()
+	}
+	c.initExperimentalFlag()
+	c.initAutomaticEnv()
 
 	// Now that all commands have been created, let's make sure all groups
 	// are properly created also
-	c.checkCommandGroups()
+	if err := c.checkCommandGroups(); err != nil {
+		if c.ExecOptions().StrictCommandGroups {
+			panic(err.Error())
+		}
+		if !c.SilenceErrors {
+			c.PrintErrln(c.styledErrPrefix(c), err.Error())
+		}
+		return c, err
+	}
 
 	args := c.args
 
@@ -1081,40 +1416,87 @@ func (c *Command) ExecuteC() (cmd *Command, err error) {
 	if c.args == nil && filepath.Base(os.Args[0]) != "cobra.test" {
 		args = os.Args[1:]
 	}
+	c.rawArgs = args
+
+	// add the ephemeral commands, such as the hidden command to be used for shell
+	// completion, if they are called
+	defer c.removeCommands(c.addEphemeralCommands(args)...)
+
+	if c.DropEmptyArgs && !isCompletionRequest(args) {
+		args = dropEmptyArgs(args)
+	}
+	args = c.expandAlias(args)
 
-	// initialize the hidden command to be used for shell completion
-	c.initCompleteCmd(args)
+	if path, ok := c.findPlugin(args); ok {
+		err = c.executePlugin(path, args[1:])
+		// The plugin reports its own errors.
+		var exitErr *ExitError
+		if err != nil && !errors.As(err, &exitErr) && !c.SilenceErrors {
+			c.PrintErrln(c.styledErrPrefix(c), err.Error())
+		}
+		return c, err
+	}
 
 	var flags []string
+	c.renamedCommandsUsed = nil
+	resolvedBy := ResolvedByFind
 	if c.TraverseChildren {
+		resolvedBy = ResolvedByTraverse
 		cmd, flags, err = c.Traverse(args)
 	} else {
 		cmd, flags, err = c.Find(args)
 	}
+	if err == nil {
+		err = cmd.unknownSubCommandError(flags)
+	}
+	if err != nil {
+		found := cmd
+		cmd, flags, err = resolveUnknownCommand(cmd, flags, err)
+		if cmd == nil && err == nil {
+			return found, nil
+		}
+	}
+	if err == nil {
+		if def := cmd.defaultCommandFor(flags); def != nil {
+			cmd = def
+		}
+	}
 	if err != nil {
 		// If found parse to a subcommand and then failed, talk about the subcommand
 		if cmd != nil {
 			c = cmd
 		}
 		if !c.SilenceErrors {
-			c.PrintErrln(c.ErrPrefix(), err.Error())
+			c.PrintErrln(c.styledErrPrefix(c), err.Error())
 			c.PrintErrf("Run '%v --help' for usage.\n", c.CommandPath())
 		}
-		return c, err
+		return c, newUsageError(err)
 	}
 
 	cmd.commandCalledAs.called = true
 	if cmd.commandCalledAs.name == "" {
 		cmd.commandCalledAs.name = cmd.Name()
 	}
+	cmd.resolvedBy = resolvedBy
 
 	// We have to pass global context to children command
 	// if context is present on the parent command.
 	if cmd.ctx == nil {
 		cmd.ctx = c.ctx
 	}
+	cmd.sendEvent(CommandResolved, flags, nil)
 
-	err = cmd.execute(flags)
+	flushOutput := c.bufferOutput()
+	start := time.Now()
+	err = cmd.executeWithMiddlewares(flags)
+	cmd.observeMetrics(start, err)
+	cmd.sendTelemetry(start, err)
+	if flushErr := flushOutput(); err == nil {
+		err = flushErr
+	}
+	if c.BufferOutput && isBrokenPipe(err) {
+		return cmd, nil
+	}
 	if err != nil {
 		// Always show help if requested, even if SilenceErrors is in
 		// effect
@@ -1126,7 +1508,7 @@ func (c *Command) ExecuteC() (cmd *Command, err error) {
 		// If root command has SilenceErrors flagged,
 		// all subcommands should respect it
 		if !cmd.SilenceErrors && !c.SilenceErrors {
-			c.PrintErrln(cmd.ErrPrefix(), err.Error())
+			c.PrintErrln(c.styledErrPrefix(cmd), err.Error())
 		}
 
 		// If root command has SilenceUsage flagged,
@@ -1153,6 +1535,7 @@ func (c *Command) ValidateRequiredFlags() error {
 
 	flags := c.Flags()
 	missingFlagNames := []string{}
+	var envVars map[string]string
 	flags.VisitAll(func(pflag *flag.Flag) {
 		requiredAnnotation, found := pflag.Annotations[BashCompOneRequiredFlag]
 		if !found {
@@ -1160,26 +1543,35 @@ func (c *Command) ValidateRequiredFlags() error {
 		}
 		if (requiredAnnotation[0] == "true") && !pflag.Changed {
 			missingFlagNames = append(missingFlagNames, pflag.Name)
+			if envVar, ok := pflag.Annotations[FlagEnvVarAnnotation]; ok {
+				if envVars == nil {
+					envVars = map[string]string{}
+				}
+				envVars[pflag.Name] = envVar[0]
+			}
 		}
 	})
 
 	if len(missingFlagNames) > 0 {
-		return fmt.Errorf(`required flag(s) "%s" not set`, strings.Join(missingFlagNames, `", "`))
+		return &RequiredFlagError{Command: c.CommandPath(), Flags: missingFlagNames, EnvVars: envVars}
 	}
 	return nil
 }
 
-// checkCommandGroups checks if a command has been added to a group that does not exists.
+// checkCommandGroups returns a MissingGroupError if a command has been added to a group that does not exists.
 // If so, we panic because it indicates a coding error that should be corrected.
-func (c *Command) checkCommandGroups() {
+func (c *Command) checkCommandGroups() error {
 	for _, sub := range c.commands {
 		// if Group is not defined let the developer know right away
 		if sub.GroupID != "" && !c.ContainsGroup(sub.GroupID) {
-			panic(fmt.Sprintf("group id '%s' is not defined for subcommand '%s'", sub.GroupID, sub.CommandPath()))
+			return &MissingGroupError{Command: sub.CommandPath(), GroupID: sub.GroupID}
 		}
 
-		sub.checkCommandGroups()
+		if err := sub.checkCommandGroups(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // InitDefaultHelpFlag adds default help flag to c.
@@ -1251,8 +1643,8 @@ Simply type ` + c.displayName() + ` help [path to command] for full details.`,
 					cmd = c.Root()
 				}
 				for _, subCmd := range cmd.Commands() {
-					if subCmd.IsAvailableCommand() || subCmd == cmd.helpCommand {
-						if strings.HasPrefix(subCmd.Name(), toComplete) {
+					if subCmd.IsAvailableCommand() || subCmd == cmd.helpCommand || subCmd.isHelpTopic() {
+						if c.ExecOptions().commandNameHasPrefix(subCmd.Name(), toComplete) {
 							completions = append(completions, fmt.Sprintf("%s\t%s", subCmd.Name(), subCmd.Short))
 						}
 					}
@@ -1260,6 +1652,10 @@ Simply type ` + c.displayName() + ` help [path to command] for full details.`,
 				return completions, ShellCompDirectiveNoFileComp
 			},
 			Run: func(c *Command, args []string) {
+				if parent, group := c.Root().findGroup(args); group != nil {
+					CheckErr(parent.groupHelp(c.OutOrStdout(), group))
+					return
+				}
 				cmd, _, e := c.Root().Find(args)
 				if cmd == nil || e != nil {
 					c.Printf("Unknown help topic %#q\n", args)
@@ -1273,7 +1669,7 @@ Simply type ` + c.displayName() + ` help [path to command] for full details.`,
 			GroupID: c.helpCommandGroupID,
 		}
 	}
-	c.RemoveCommand(c.helpCommand)
+	c.removeCommands(c.helpCommand)
 	c.AddCommand(c.helpCommand)
 }
 
@@ -1281,6 +1677,7 @@ Simply type ` + c.displayName() + ` help [path to command] for full details.`,
 func (c *Command) ResetCommands() {
 	c.parent = nil
 	c.commands = nil
+	c.commandIndex = nil
 	c.helpCommand = nil
 	c.parentsPflags = nil
 }
@@ -1295,9 +1692,10 @@ func (c commandSorterByName) Less(i, j int) bool { return c[i].Name() < c[j].Nam
 // Commands returns a sorted slice of child commands.
 func (c *Command) Commands() []*Command {
 	// do not sort commands if it already sorted or sorting was disabled
-	if EnableCommandSorting && !c.commandsAreSorted {
+	if c.ExecOptions().CommandSorting && !c.commandsAreSorted {
 		sort.Sort(commandSorterByName(c.commands))
 		c.commandsAreSorted = true
+		c.commandIndex = nil
 	}
 	return c.commands
 }
@@ -1328,6 +1726,7 @@ func (c *Command) AddCommand(cmds ...*Command) {
 		}
 		c.commands = append(c.commands, x)
 		c.commandsAreSorted = false
+		c.commandIndex = nil
 	}
 }
 
@@ -1361,21 +1760,104 @@ func (c *Command) AddGroup(groups ...*Group) {
 	c.commandgroups = append(c.commandgroups, groups...)
 }
 
+// groupHelpTemplate is the template of the help of a group of commands, shown by 'help <group ID>'.
+const groupHelpTemplate = `{{.Group.Title}}{{with .Group.Description}}
+{{.}}{{end}}
+{{range .Commands}}
+  {{rpad .Name .NamePadding }} {{.WrappedShort}}{{if .IsExperimental}} (experimental){{end}}{{if .IsDefaultCommand}} (default){{end}}{{end}}
+
+Use "{{.Command.CommandPath}} [command] --help" for more information about a command.
+`
+
+// findGroup returns the group whose ID is the last of args, and the command
+// found from the other args which it belongs to, unless it also has a sub-command
+// with this name.
+func (c *Command) findGroup(args []string) (*Command, *Group) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	parent := c
+	if len(args) > 1 {
+		cmd, _, err := c.Find(args[:len(args)-1])
+		if cmd == nil || err != nil {
+			return nil, nil
+		}
+		parent = cmd
+	}
+	id := args[len(args)-1]
+	if parent.findNext(id) != nil {
+		return nil, nil
+	}
+	for _, g := range parent.commandgroups {
+		if g.ID == id {
+			return parent, g
+		}
+	}
+	return nil, nil
+}
+
+// groupHelp writes the help of group, a group of the sub-commands of c, to w.
+func (c *Command) groupHelp(w io.Writer, group *Group) error {
+	var cmds []*Command
+	for _, sub := range c.Commands() {
+		if sub.GroupID == group.ID && (sub.IsAvailableCommand() || sub == c.helpCommand) {
+			cmds = append(cmds, sub)
+		}
+	}
+	return tmpl(w, groupHelpTemplate, struct {
+		Command  *Command
+		Group    *Group
+		Commands []*Command
+	}{c, group, cmds})
+}
+
 // RemoveCommand removes one or more commands from a parent command.
+// The groups of c left without commands are removed, the help command of c is
+// forgotten if it is removed, and the flag completion functions registered in the
+// removed sub-trees for the persistent flags of c and its parents are moved to c.
 func (c *Command) RemoveCommand(cmds ...*Command) {
+	var groupIDs []string
+	for _, cmd := range c.removeCommands(cmds...) {
+		if cmd.GroupID != "" {
+			groupIDs = append(groupIDs, cmd.GroupID)
+		}
+	}
+	for _, cmd := range cmds {
+		if cmd == c.helpCommand {
+			c.helpCommand = nil
+		}
+	}
+	c.removeEmptyGroups(groupIDs)
+}
+
+// removeCommands removes cmds from c and returns the ones which were children of
+// c.  Unlike RemoveCommand, it leaves the groups and the help command of c alone,
+// so that commands can be removed temporarily, e.g. the ephemeral ones.
+func (c *Command) removeCommands(cmds ...*Command) []*Command {
+	var removed []*Command
 	commands := []*Command{}
 main:
 	for _, command := range c.commands {
 		for _, cmd := range cmds {
 			if command == cmd {
+				c.moveFlagCompletions(command)
 				command.parent = nil
+				command.visitTree((*Command).updateCommandsMaxLen)
+				removed = append(removed, command)
 				continue main
 			}
 		}
 		commands = append(commands, command)
 	}
 	c.commands = commands
-	// recompute all lengths
+	c.commandIndex = nil
+	c.updateCommandsMaxLen()
+	return removed
+}
+
+// updateCommandsMaxLen recomputes the max lengths of the usage, command path and
+// name of the sub-commands of c.
+func (c *Command) updateCommandsMaxLen() {
 	c.commandsMaxUseLen = 0
 	c.commandsMaxCommandPathLen = 0
 	c.commandsMaxNameLen = 0
@@ -1395,6 +1877,48 @@ main:
 	}
 }
 
+// moveFlagCompletions moves to c the flag completion functions registered in the
+// sub-tree of sub, a child of c, for the persistent flags of c and its parents, so
+// that they are still found for the commands of c once sub is removed.
+func (c *Command) moveFlagCompletions(sub *Command) {
+	sub.visitTree(func(cmd *Command) {
+		for flag, f := range cmd.flagCompletions.snapshot() {
+			for p := c; p != nil; p = p.parent {
+				if p.PersistentFlags().Lookup(flag.Name) == flag {
+					cmd.flagCompletions.unregister(flag)
+					c.flagCompletions.register(flag, f)
+					break
+				}
+			}
+		}
+	})
+}
+
+// removeEmptyGroups removes the groups of c among groupIDs which have no commands.
+func (c *Command) removeEmptyGroups(groupIDs []string) {
+	if len(groupIDs) == 0 {
+		return
+	}
+	groups := []*Group{}
+	for _, group := range c.commandgroups {
+		if stringInSlice(group.ID, groupIDs) && !c.groupHasCommands(group.ID) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	c.commandgroups = groups
+}
+
+// groupHasCommands returns true if a sub-command of c belongs to the group groupID.
+func (c *Command) groupHasCommands(groupID string) bool {
+	for _, sub := range c.commands {
+		if sub.GroupID == groupID {
+			return true
+		}
+	}
+	return false
+}
+
 // Print is a convenience method to Print to the defined output, fallback to Stderr if not set.
 func (c *Command) Print(i ...interface{}) {
 	fmt.Fprint(c.OutOrStderr(), i...)
@@ -1433,7 +1957,15 @@ func (c *Command) CommandPath() string {
 	return c.displayName()
 }
 
+// SetDisplayName sets the name shown for the command instead of its Name, see DisplayName.
+func (c *Command) SetDisplayName(name string) {
+	c.DisplayName = name
+}
+
 func (c *Command) displayName() string {
+	if c.DisplayName != "" {
+		return c.DisplayName
+	}
 	if displayName, ok := c.Annotations[CommandDisplayNameAnnotation]; ok {
 		return displayName
 	}
@@ -1449,9 +1981,16 @@ func (c *Command) UseLine() string {
 	} else {
 		useline = use
 	}
+	useline += c.paramsUseLine(useline)
+	for _, name := range c.ArgNames {
+		if !strings.Contains(useline, name) {
+			useline += " " + name
+		}
+	}
 	if c.DisableFlagsInUseLine {
 		return useline
 	}
+	useline += c.requiredFlagsUseLine(useline)
 	if c.HasAvailableFlags() && !strings.Contains(useline, "[flags]") {
 		useline += " [flags]"
 	}
@@ -1511,8 +2050,9 @@ func (c *Command) Name() string {
 
 // HasAlias determines if a given string is an alias of the command.
 func (c *Command) HasAlias(s string) bool {
+	opts := c.ExecOptions()
 	for _, a := range c.Aliases {
-		if commandNameMatches(a, s) {
+		if opts.commandAliasMatches(a, s) {
 			return true
 		}
 	}
@@ -1531,12 +2071,13 @@ func (c *Command) CalledAs() string {
 // hasNameOrAliasPrefix returns true if the Name or any of aliases start
 // with prefix
 func (c *Command) hasNameOrAliasPrefix(prefix string) bool {
-	if strings.HasPrefix(c.Name(), prefix) {
+	opts := c.ExecOptions()
+	if opts.commandNameHasPrefix(c.Name(), prefix) {
 		c.commandCalledAs.name = c.Name()
 		return true
 	}
 	for _, alias := range c.Aliases {
-		if strings.HasPrefix(alias, prefix) {
+		if opts.commandAliasHasPrefix(alias, prefix) {
 			c.commandCalledAs.name = alias
 			return true
 		}
@@ -1551,7 +2092,7 @@ func (c *Command) NameAndAliases() string {
 
 // HasExample determines if the command has example.
 func (c *Command) HasExample() bool {
-	return len(c.Example) > 0
+	return len(c.Example) > 0 || len(c.Examples) > 0
 }
 
 // Runnable determines if the command is itself runnable.
@@ -1575,7 +2116,7 @@ func (c *Command) IsAvailableCommand() bool {
 		return false
 	}
 
-	if c.Runnable() || c.HasAvailableSubCommands() {
+	if c.Runnable() || c.HasAvailableSubCommands() || c.isLazy() {
 		return true
 	}
 
@@ -1641,8 +2182,27 @@ func (c *Command) HasParent() bool {
 }
 
 // GlobalNormalizationFunc returns the global normalization function or nil if it doesn't exist.
+// When ExecOptions.CaseInsensitiveFlags is set, the returned function also folds the
+// case of the normalized names.
 func (c *Command) GlobalNormalizationFunc() func(f *flag.FlagSet, name string) flag.NormalizedName {
-	return c.globNormFunc
+	if !c.ExecOptions().CaseInsensitiveFlags {
+		return c.globNormFunc
+	}
+	normalize := c.globNormFunc
+	return func(f *flag.FlagSet, name string) flag.NormalizedName {
+		if normalize != nil {
+			name = string(normalize(f, name))
+		}
+		return flag.NormalizedName(strings.ToLower(name))
+	}
+}
+
+// NormalizeName returns the name of the flag called name as resolved by the flag
+// parser of cmd, i.e. after applying its normalization function, if any.  It allows
+// integrations, such as configuration loaders, to match flag names like the parser.
+func NormalizeName(cmd *Command, name string) string {
+	flags := cmd.Flags()
+	return string(flags.GetNormalizeFunc()(flags, name))
 }
 
 // Flags returns the complete FlagSet that applies
@@ -1677,17 +2237,23 @@ func (c *Command) LocalNonPersistentFlags() *flag.FlagSet {
 // This function does not modify the flags of the current command, it's purpose is to return the current state.
 func (c *Command) LocalFlags() *flag.FlagSet {
 	c.mergePersistentFlags()
+	if c.flagSetsChanged() {
+		c.lflags, c.iflags = nil, nil
+	}
 
-	if c.lflags == nil {
-		c.lflags = flag.NewFlagSet(c.displayName(), flag.ContinueOnError)
-		if c.flagErrorBuf == nil {
-			c.flagErrorBuf = new(bytes.Buffer)
-		}
-		c.lflags.SetOutput(c.flagErrorBuf)
+	if c.lflags != nil {
+		c.lflags.SortFlags = c.Flags().SortFlags
+		return c.lflags
 	}
+
+	c.lflags = flag.NewFlagSet(c.displayName(), flag.ContinueOnError)
+	if c.flagErrorBuf == nil {
+		c.flagErrorBuf = new(bytes.Buffer)
+	}
+	c.lflags.SetOutput(c.flagErrorBuf)
 	c.lflags.SortFlags = c.Flags().SortFlags
-	if c.globNormFunc != nil {
-		c.lflags.SetNormalizeFunc(c.globNormFunc)
+	if normalize := c.GlobalNormalizationFunc(); normalize != nil {
+		c.lflags.SetNormalizeFunc(normalize)
 	}
 
 	addToLocal := func(f *flag.Flag) {
@@ -1704,19 +2270,18 @@ func (c *Command) LocalFlags() *flag.FlagSet {
 // InheritedFlags returns all flags which were inherited from parent commands.
 // This function does not modify the flags of the current command, it's purpose is to return the current state.
 func (c *Command) InheritedFlags() *flag.FlagSet {
-	c.mergePersistentFlags()
-
-	if c.iflags == nil {
-		c.iflags = flag.NewFlagSet(c.displayName(), flag.ContinueOnError)
-		if c.flagErrorBuf == nil {
-			c.flagErrorBuf = new(bytes.Buffer)
-		}
-		c.iflags.SetOutput(c.flagErrorBuf)
+	local := c.LocalFlags()
+	if c.iflags != nil {
+		return c.iflags
 	}
 
-	local := c.LocalFlags()
-	if c.globNormFunc != nil {
-		c.iflags.SetNormalizeFunc(c.globNormFunc)
+	c.iflags = flag.NewFlagSet(c.displayName(), flag.ContinueOnError)
+	if c.flagErrorBuf == nil {
+		c.flagErrorBuf = new(bytes.Buffer)
+	}
+	c.iflags.SetOutput(c.flagErrorBuf)
+	if normalize := c.GlobalNormalizationFunc(); normalize != nil {
+		c.iflags.SetNormalizeFunc(normalize)
 	}
 
 	c.parentsPflags.VisitAll(func(f *flag.Flag) {
@@ -1756,7 +2321,9 @@ func (c *Command) ResetFlags() {
 
 	c.lflags = nil
 	c.iflags = nil
+	c.flagSetSizes = nil
 	c.parentsPflags = nil
+	c.flagsCaseFolded = false
 }
 
 // HasFlags checks if the command contains any flags (local plus persistent from the entire structure).
@@ -1840,6 +2407,9 @@ func (c *Command) ParseFlags(args []string) error {
 
 	// do it here after merging all flags and just before parse
 	c.Flags().ParseErrorsWhitelist = flag.ParseErrorsWhitelist(c.FParseErrWhitelist)
+	if interspersed, ok := c.flagsInterspersedMode(); ok {
+		c.Flags().SetInterspersed(interspersed)
+	}
 
 	err := c.Flags().Parse(args)
 	// Print warnings if they occurred (e.g. deprecated flag messages).
@@ -1850,6 +2420,29 @@ func (c *Command) ParseFlags(args []string) error {
 	return err
 }
 
+// SetFlagsInterspersed sets whether the command and its children which do not set
+// their own mode accept flags after the arguments which are not flags.  When false,
+// the first such argument ends the flags, as with ExecOptions.POSIXStrict, which is useful
+// for commands running other programs, e.g. 'app run somecmd -x'.
+func (c *Command) SetFlagsInterspersed(interspersed bool) {
+	c.flagsInterspersed = &interspersed
+}
+
+// flagsInterspersedMode returns the flag parsing mode set by SetFlagsInterspersed on
+// the command or its closest parent, or by ExecOptions.POSIXStrict, and false if none
+// is set, in which case the mode of the flag set applies.
+func (c *Command) flagsInterspersedMode() (interspersed bool, ok bool) {
+	for p := c; p != nil; p = p.Parent() {
+		if p.flagsInterspersed != nil {
+			return *p.flagsInterspersed, true
+		}
+	}
+	if c.ExecOptions().POSIXStrict {
+		return false, true
+	}
+	return true, false
+}
+
 // Parent returns a commands parent command.
 func (c *Command) Parent() *Command {
 	return c.parent
@@ -1859,6 +2452,7 @@ func (c *Command) Parent() *Command {
 // and adds missing persistent flags of all parents.
 func (c *Command) mergePersistentFlags() {
 	c.updateParentsPflags()
+	c.applyFlagCaseInsensitivity()
 	c.Flags().AddFlagSet(c.PersistentFlags())
 	c.Flags().AddFlagSet(c.parentsPflags)
 }
@@ -1873,8 +2467,8 @@ func (c *Command) updateParentsPflags() {
 		c.parentsPflags.SortFlags = false
 	}
 
-	if c.globNormFunc != nil {
-		c.parentsPflags.SetNormalizeFunc(c.globNormFunc)
+	if normalize := c.GlobalNormalizationFunc(); normalize != nil {
+		c.parentsPflags.SetNormalizeFunc(normalize)
 	}
 
 	c.Root().PersistentFlags().AddFlagSet(flag.CommandLine)
@@ -1884,13 +2478,15 @@ func (c *Command) updateParentsPflags() {
 	})
 }
 
-// commandNameMatches checks if two command names are equal
-// taking into account case sensitivity according to
-// EnableCaseInsensitive global configuration.
-func commandNameMatches(s string, t string) bool {
-	if EnableCaseInsensitive {
-		return strings.EqualFold(s, t)
+// applyFlagCaseInsensitivity makes the long flag names of c.Flags() case-insensitive
+// when ExecOptions.CaseInsensitiveFlags is set, on top of the global normalization function.
+// The normalization function is only set again when the option changes, since setting
+// it normalizes all the flags again.
+func (c *Command) applyFlagCaseInsensitivity() {
+	folded := c.ExecOptions().CaseInsensitiveFlags
+	if folded == c.flagsCaseFolded {
+		return
 	}
-
-	return s == t
+	c.flagsCaseFolded = folded
+	c.Flags().SetNormalizeFunc(c.GlobalNormalizationFunc())
 }
@@ -0,0 +1,151 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+	"unicode"
+)
+
+// commandIndex maps the names and aliases of the sub-commands of a command to their
+// position in its commands, so that finding a sub-command does not scan all of them.
+// It is built on the first lookup and dropped when the commands are added, removed
+// or sorted.
+type commandIndex struct {
+	// caseInsensitiveCommands and caseInsensitiveAliases are the options the keys
+	// were built with: case-folded names or aliases when set, see foldKey.
+	caseInsensitiveCommands bool
+	caseInsensitiveAliases  bool
+	names                   map[string]int
+	aliases                 map[string]int
+}
+
+func newCommandIndex(commands []*Command, opts ExecOptions) *commandIndex {
+	idx := &commandIndex{
+		caseInsensitiveCommands: opts.CaseInsensitiveCommands,
+		caseInsensitiveAliases:  opts.CaseInsensitiveAliases,
+		names:                   make(map[string]int, len(commands)),
+		aliases:                 make(map[string]int),
+	}
+	// The first command matching a name wins, as with a scan of the commands.
+	for i, cmd := range commands {
+		if key := idx.nameKey(cmd.Name()); !hasKey(idx.names, key) {
+			idx.names[key] = i
+		}
+		for _, alias := range cmd.Aliases {
+			if key := idx.aliasKey(alias); !hasKey(idx.aliases, key) {
+				idx.aliases[key] = i
+			}
+		}
+	}
+	return idx
+}
+
+func hasKey(m map[string]int, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func (idx *commandIndex) nameKey(name string) string {
+	if idx.caseInsensitiveCommands {
+		return foldKey(name)
+	}
+	return name
+}
+
+func (idx *commandIndex) aliasKey(alias string) string {
+	if idx.caseInsensitiveAliases {
+		return foldKey(alias)
+	}
+	return alias
+}
+
+// foldKey returns the key of s such that foldKey(s) == foldKey(t) when
+// strings.EqualFold(s, t): each rune is replaced by the smallest rune
+// of its case folding orbit.
+func foldKey(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		b.WriteRune(min)
+	}
+	return b.String()
+}
+
+// matches reports whether the index was built with the case sensitivity of opts.
+func (idx *commandIndex) matches(opts ExecOptions) bool {
+	return idx.caseInsensitiveCommands == opts.CaseInsensitiveCommands &&
+		idx.caseInsensitiveAliases == opts.CaseInsensitiveAliases
+}
+
+// find returns the position of the first command with the given name or alias.
+func (idx *commandIndex) find(name string) (int, bool) {
+	i, okName := idx.names[idx.nameKey(name)]
+	j, okAlias := idx.aliases[idx.aliasKey(name)]
+	switch {
+	case okName && okAlias:
+		if j < i {
+			return j, true
+		}
+		return i, true
+	case okAlias:
+		return j, true
+	default:
+		return i, okName
+	}
+}
+
+// subCommand returns the sub-command of c whose name or alias is name, or nil.
+func (c *Command) subCommand(name string, opts ExecOptions) *Command {
+	if c.commandIndex == nil || !c.commandIndex.matches(opts) {
+		c.commandIndex = newCommandIndex(c.commands, opts)
+	}
+	i, ok := c.commandIndex.find(name)
+	if ok && i < len(c.commands) && opts.commandMatches(c.commands[i], name) {
+		return c.commands[i]
+	}
+
+	// The Use or Aliases of a command may have changed after it was indexed:
+	// scan the commands and rebuild the index on the next lookup if it is stale.
+	for _, cmd := range c.commands {
+		if opts.commandMatches(cmd, name) {
+			c.commandIndex = nil
+			return cmd
+		}
+	}
+	if ok {
+		c.commandIndex = nil
+	}
+	return nil
+}
+
+// commandMatches reports whether name is the name or an alias of cmd.
+func (o ExecOptions) commandMatches(cmd *Command, name string) bool {
+	if o.commandNameMatches(cmd.Name(), name) {
+		return true
+	}
+	for _, alias := range cmd.Aliases {
+		if o.commandAliasMatches(alias, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFindUsesFirstMatchingCommand(t *testing.T) {
+	root := &Command{Use: "root", Run: emptyRun}
+	first := &Command{Use: "first", Aliases: []string{"second"}, Run: emptyRun}
+	second := &Command{Use: "second", Run: emptyRun}
+	root.AddCommand(first, second)
+
+	// The commands are sorted by name, so "first" comes first.
+	if cmd, _, err := root.Find([]string{"second"}); err != nil || cmd != first {
+		t.Errorf("Expected the first matching command, got %v (%v)", cmd.Name(), err)
+	}
+
+	root.RemoveCommand(first)
+	if cmd, _, err := root.Find([]string{"second"}); err != nil || cmd != second {
+		t.Errorf("Expected the remaining command, got %v (%v)", cmd.Name(), err)
+	}
+}
+
+func TestFindAfterRenamingCommand(t *testing.T) {
+	root := &Command{Use: "root", Run: emptyRun}
+	child := &Command{Use: "old", Run: emptyRun}
+	other := &Command{Use: "new", Run: emptyRun}
+	root.AddCommand(child, other)
+
+	if cmd, _, _ := root.Find([]string{"old"}); cmd != child {
+		t.Fatalf("Expected to find the child")
+	}
+	other.Use = "other"
+	child.Use = "new"
+	if cmd, _, _ := root.Find([]string{"new"}); cmd != child {
+		t.Errorf("Expected to find the renamed child, got %v", cmd.Name())
+	}
+}
+
+func TestFindAfterAddingAlias(t *testing.T) {
+	root := &Command{Use: "root", Run: emptyRun}
+	child := &Command{Use: "child", Run: emptyRun}
+	root.AddCommand(child)
+
+	if cmd, _, _ := root.Find([]string{"kid"}); cmd == child {
+		t.Fatalf("Expected not to find the child by an unknown alias")
+	}
+	child.Aliases = []string{"kid"}
+	if cmd, _, _ := root.Find([]string{"kid"}); cmd != child {
+		t.Errorf("Expected to find the child by its new alias, got %v", cmd.Name())
+	}
+	child.Use = "renamed"
+	if cmd, _, _ := root.Find([]string{"renamed"}); cmd != child {
+		t.Errorf("Expected to find the renamed child, got %v", cmd.Name())
+	}
+}
+
+func TestFindIndexFollowsCaseInsensitivity(t *testing.T) {
+	root := &Command{Use: "root", Run: emptyRun}
+	child := &Command{Use: "child", Aliases: []string{"kid"}, Run: emptyRun}
+	root.AddCommand(child)
+
+	if root.hasSubCommand("CHILD") {
+		t.Errorf("Expected case-sensitive names by default")
+	}
+	opts := DefaultExecOptions()
+	opts.CaseInsensitiveCommands = true
+	root.SetExecOptions(opts)
+	if !root.hasSubCommand("CHILD") || root.hasSubCommand("KID") {
+		t.Errorf("Expected case-insensitive names and case-sensitive aliases")
+	}
+
+	// The index folds the case as strings.EqualFold, for which the long s is an "s".
+	root.AddCommand(&Command{Use: "status", Run: emptyRun})
+	if !root.hasSubCommand("\u017Ftatus") {
+		t.Errorf("Expected the names to match as with strings.EqualFold")
+	}
+	if _, ok := root.commandIndex.find("\u017Ftatus"); !ok {
+		t.Errorf("Expected the names to match as with strings.EqualFold")
+	}
+}
+
+func BenchmarkFind(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		root := &Command{Use: "root", Run: emptyRun}
+		for i := 0; i < n; i++ {
+			group := &Command{Use: fmt.Sprintf("group%d", i), Aliases: []string{fmt.Sprintf("g%d", i)}}
+			for j := 0; j < 10; j++ {
+				group.AddCommand(&Command{Use: fmt.Sprintf("cmd%d", j), Run: emptyRun})
+			}
+			root.AddCommand(group)
+		}
+		args := []string{fmt.Sprintf("g%d", n-1), "cmd9", "--flag", "value", "arg"}
+
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := root.Find(args); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ package cobra
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -427,6 +428,43 @@ func TestPluginWithSubCommands(t *testing.T) {
 	checkStringContains(t, helpHelp, "kubectl plugin help [command]")
 }
 
+// TestPluginDisplayName checks that the DisplayName field is used like the
+// annotation and takes precedence over it.
+func TestPluginDisplayName(t *testing.T) {
+	rootCmd := &Command{
+		Use:  "kubectl-plugin",
+		Args: NoArgs,
+		Annotations: map[string]string{
+			CommandDisplayNameAnnotation: "kubectl other",
+		},
+	}
+	rootCmd.SetDisplayName("kubectl plugin")
+	subCmd := &Command{Use: "sub [flags]", Args: NoArgs, Run: emptyRun}
+	rootCmd.AddCommand(subCmd)
+
+	if got := subCmd.CommandPath(); got != "kubectl plugin sub" {
+		t.Errorf("Expected command path %q, got %q", "kubectl plugin sub", got)
+	}
+	if got := subCmd.UseLine(); got != "kubectl plugin sub [flags]" {
+		t.Errorf("Expected use line %q, got %q", "kubectl plugin sub [flags]", got)
+	}
+
+	rootHelp, err := executeCommand(rootCmd, "-h")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, rootHelp, "kubectl plugin [command]")
+	checkStringContains(t, rootHelp, "help for kubectl plugin")
+	checkStringOmits(t, rootHelp, "kubectl other")
+
+	compHelp, err := executeCommand(rootCmd, "completion", "bash", "-h")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, compHelp, "source <(kubectl plugin completion bash)")
+	checkStringContains(t, compHelp, "/etc/bash_completion.d/kubectl-plugin")
+}
+
 // TestChildSameName checks the correct behaviour of cobra in cases,
 // when an application with name "foo" and with subcommand "foo"
 // is executed with args "foo foo".
@@ -1066,6 +1104,35 @@ func TestFlagsInUsage(t *testing.T) {
 	checkStringContains(t, output, "[flags]")
 }
 
+func TestUsageFooterAndHelpBanner(t *testing.T) {
+	rootCmd := &Command{Use: "root", Short: "Root short", Run: emptyRun}
+	childCmd := &Command{Use: "child", Short: "Child short", Run: emptyRun}
+	otherCmd := &Command{Use: "other", Run: emptyRun}
+	rootCmd.AddCommand(childCmd, otherCmd)
+
+	rootCmd.SetHelpBanner("Copyright ACME")
+	rootCmd.SetUsageFooter("Support: help@example.com")
+	otherCmd.SetUsageFooter("Other support: other@example.com")
+
+	output, err := executeCommand(rootCmd, "help", "child")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(output, "Copyright ACME\n\nChild short\n\nUsage:") {
+		t.Errorf("Expected the banner before the description, got:\n%s", output)
+	}
+	if !strings.HasSuffix(output, "\n\nSupport: help@example.com\n") {
+		t.Errorf("Expected the footer at the end of the usage, got:\n%s", output)
+	}
+
+	output, err = executeCommand(rootCmd, "other", "--unknown")
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+	checkStringContains(t, output, "\n\nOther support: other@example.com\n")
+	checkStringOmits(t, output, "Copyright ACME")
+}
+
 func TestHelpExecutedOnNonRunnableChild(t *testing.T) {
 	rootCmd := &Command{Use: "root", Run: emptyRun}
 	childCmd := &Command{Use: "child", Long: "Long description"}
@@ -1148,6 +1215,28 @@ func TestVersionTemplate(t *testing.T) {
 	checkStringContains(t, output, "customized version: 1.0.0")
 }
 
+func TestVersionInfo(t *testing.T) {
+	rootCmd := &Command{Use: "root", Version: "1.0.0", Run: emptyRun}
+	rootCmd.SetVersionInfo(map[string]string{"date": "2023-05-01", "commit": "abc123"})
+
+	output, err := executeCommand(rootCmd, "--version")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if expected := "root version 1.0.0\ncommit: abc123\ndate: 2023-05-01\n"; output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+
+	rootCmd.SetVersionTemplate(`{{.Version}} ({{index .VersionInfo "commit"}})`)
+	output, err = executeCommand(rootCmd, "--version")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if expected := "1.0.0 (abc123)"; output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+}
+
 func TestShorthandVersionTemplate(t *testing.T) {
 	rootCmd := &Command{Use: "root", Version: "1.0.0", Run: emptyRun}
 	rootCmd.SetVersionTemplate(`customized version: {{.Version}}`)
@@ -1193,6 +1282,54 @@ func TestRootAndSubErrPrefix(t *testing.T) {
 	}
 }
 
+func TestRecoverFunc(t *testing.T) {
+	rootCmd := &Command{Use: "root", SilenceUsage: true}
+	childCmd := &Command{Use: "child", Run: func(*Command, []string) { panic("boom") }}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.SetRecoverFunc(func(cmd *Command, recovered interface{}) error {
+		return fmt.Errorf("%s crashed: %v", cmd.Name(), recovered)
+	})
+
+	output, err := executeCommand(rootCmd, "child")
+	if err == nil || err.Error() != "child crashed: boom" {
+		t.Errorf("Expected the panic to be converted to an error, got %v", err)
+	}
+	checkStringContains(t, output, "Error: child crashed: boom")
+
+	// Without a recover function, panics are not recovered.
+	rootCmd.SetRecoverFunc(nil)
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("Expected the panic to propagate, got %v", r)
+		}
+	}()
+	_, _ = executeCommand(rootCmd, "child")
+	t.Error("Expected a panic")
+}
+
+func TestErrPrefixInheritedFromClosestParent(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	remoteCmd := &Command{Use: "remote"}
+	addCmd := &Command{Use: "add", Run: emptyRun}
+	rootCmd.AddCommand(remoteCmd)
+	remoteCmd.AddCommand(addCmd)
+	rootCmd.SetErrPrefix("root error prefix:")
+	remoteCmd.SetErrPrefix("remote error prefix:")
+	rootCmd.SetExecOptions(ExecOptions{StrictSubCommands: true})
+
+	if output, err := executeCommand(rootCmd, "remote", "add", "--unknown-flag"); err == nil {
+		t.Errorf("Expected error")
+	} else {
+		checkStringContains(t, output, "remote error prefix: unknown flag: --unknown-flag")
+	}
+
+	if output, err := executeCommand(rootCmd, "remote", "unknown"); err == nil {
+		t.Errorf("Expected error")
+	} else {
+		checkStringContains(t, output, `remote error prefix: unknown command "unknown" for "root remote"`)
+	}
+}
+
 func TestVersionFlagExecutedOnSubcommand(t *testing.T) {
 	rootCmd := &Command{Use: "root", Version: "1.0.0"}
 	rootCmd.AddCommand(&Command{Use: "sub", Run: emptyRun})
@@ -1205,6 +1342,36 @@ func TestVersionFlagExecutedOnSubcommand(t *testing.T) {
 	checkStringContains(t, output, "root version 1.0.0")
 }
 
+func TestSubcommandOwnVersionFlag(t *testing.T) {
+	rootCmd := &Command{Use: "root", Version: "1.0.0"}
+	pluginCmd := &Command{Use: "plugin", Version: "0.3.0", OwnVersionFlag: true, Run: emptyRun}
+	rootCmd.AddCommand(pluginCmd)
+	rootCmd.SetVersionInfo(map[string]string{"commit": "abc123"})
+
+	output, err := executeCommand(rootCmd, "plugin", "--version")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if expected := "plugin version 0.3.0\nroot version 1.0.0\ncommit: abc123\n"; output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+
+	output, err = executeCommand(rootCmd, "--version")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if expected := "root version 1.0.0\ncommit: abc123\n"; output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+
+	pluginCmd.OwnVersionFlag = false
+	output, err = executeCommand(rootCmd, "plugin", "--version")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringOmits(t, output, "root version")
+}
+
 func TestShorthandVersionFlagExecutedOnSubcommand(t *testing.T) {
 	rootCmd := &Command{Use: "root", Version: "1.0.0"}
 	rootCmd.AddCommand(&Command{Use: "sub", Run: emptyRun})
@@ -1464,6 +1631,98 @@ func TestCaseInsensitive(t *testing.T) {
 	EnableCaseInsensitive = defaultCaseInsensitive
 }
 
+func TestGranularCaseInsensitivity(t *testing.T) {
+	tests := []struct {
+		desc        string
+		commands    bool
+		aliases     bool
+		flags       bool
+		args        []string
+		expectedErr bool
+	}{
+		{desc: "commands only: name", commands: true, args: []string{"DEPLOY"}},
+		{desc: "commands only: alias", commands: true, args: []string{"DEP"}, expectedErr: true},
+		{desc: "commands only: flag", commands: true, args: []string{"deploy", "--Force"}, expectedErr: true},
+		{desc: "aliases only: name", aliases: true, args: []string{"DEPLOY"}, expectedErr: true},
+		{desc: "aliases only: alias", aliases: true, args: []string{"DEP"}},
+		{desc: "flags only: flag", flags: true, args: []string{"deploy", "--FORCE"}},
+		{desc: "flags only: name", flags: true, args: []string{"Deploy"}, expectedErr: true},
+		{desc: "flags only: shorthand", flags: true, args: []string{"deploy", "-F"}, expectedErr: true},
+		{desc: "all", commands: true, aliases: true, flags: true, args: []string{"Dep", "--Force"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			rootCmd := &Command{Use: "root", Run: emptyRun}
+			rootCmd.SetExecOptions(ExecOptions{
+				CaseInsensitiveCommands: tc.commands,
+				CaseInsensitiveAliases:  tc.aliases,
+				CaseInsensitiveFlags:    tc.flags,
+			})
+			deployCmd := &Command{Use: "deploy", Aliases: []string{"dep"}, Args: NoArgs, Run: emptyRun}
+			deployCmd.Flags().BoolP("force", "f", false, "force")
+			rootCmd.AddCommand(deployCmd)
+
+			_, err := executeCommand(rootCmd, tc.args...)
+			if tc.expectedErr && err == nil {
+				t.Error("Expected error but got nil")
+			}
+			if !tc.expectedErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCaseInsensitiveFlagsWithNormalizationFunc(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.SetExecOptions(ExecOptions{CaseInsensitiveFlags: true})
+	rootCmd.Flags().Bool("dry-run", false, "dry run")
+
+	if _, err := executeCommand(rootCmd, "--DRY-RUN"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Setting a normalization function after the flags were folded must keep them case-insensitive.
+	rootCmd.SetGlobalNormalizationFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		return pflag.NormalizedName(strings.ReplaceAll(name, "_", "-"))
+	})
+	if _, err := executeCommand(rootCmd, "--DRY_RUN"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !rootCmd.Flags().Changed("dry-run") {
+		t.Error("Expected --DRY_RUN to set the dry-run flag")
+	}
+
+	rootCmd.SetExecOptions(ExecOptions{})
+	if _, err := executeCommand(rootCmd, "--DRY_RUN"); err == nil {
+		t.Error("Expected the flags to be case-sensitive again")
+	}
+	if _, err := executeCommand(rootCmd, "--dry_run"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCaseInsensitiveCompletions(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.SetExecOptions(ExecOptions{CaseInsensitiveCommands: true, CaseInsensitiveFlags: true})
+	deployCmd := &Command{Use: "deploy", Run: emptyRun}
+	deployCmd.Flags().Bool("force", false, "force")
+	rootCmd.AddCommand(deployCmd)
+
+	output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "DEP")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "deploy\n")
+
+	output, err = executeCommand(rootCmd, ShellCompNoDescRequestCmd, "deploy", "--FO")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "--force\n")
+}
+
 // This test make sure we keep backwards-compatibility with respect
 // to command names case sensitivity behavior.
 func TestCaseSensitivityBackwardCompatibility(t *testing.T) {
@@ -1490,6 +1749,57 @@ func TestRemoveCommand(t *testing.T) {
 	}
 }
 
+func TestRemoveCommandCleansUp(t *testing.T) {
+	rootCmd := &Command{Use: "root", Args: NoArgs, Run: emptyRun}
+	rootCmd.PersistentFlags().String("output", "", "output format")
+	rootCmd.AddGroup(&Group{ID: "extra", Title: "Extra Commands:"}, &Group{ID: "core", Title: "Core Commands:"})
+	getCmd := &Command{Use: "get", GroupID: "core", Run: emptyRun}
+	childCmd := &Command{Use: "child-with-a-long-name", GroupID: "extra", Run: emptyRun}
+	nestedCmd := &Command{Use: "nested", Run: emptyRun}
+	helpCmd := &Command{Use: "help", Run: emptyRun}
+	childCmd.AddCommand(nestedCmd)
+	rootCmd.AddCommand(getCmd, childCmd)
+	rootCmd.SetHelpCommand(helpCmd)
+	if err := childCmd.RegisterFlagCompletionFunc("output", FixedCompletions([]string{"json", "yaml"}, ShellCompDirectiveNoFileComp)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rootCmd.RemoveCommand(childCmd, helpCmd)
+
+	if len(rootCmd.Groups()) != 1 || rootCmd.Groups()[0].ID != "core" {
+		t.Errorf("Expected only the group core to remain, got %v", rootCmd.Groups())
+	}
+	if rootCmd.helpCommand != nil {
+		t.Errorf("Expected the removed help command to be forgotten")
+	}
+	if rootCmd.commandsMaxNameLen != len("get") {
+		t.Errorf("Expected the max name length of the remaining commands, got %d", rootCmd.commandsMaxNameLen)
+	}
+	if childCmd.commandsMaxCommandPathLen != len("child-with-a-long-name nested") {
+		t.Errorf("Expected the max command path length of the removed command to be updated, got %d", childCmd.commandsMaxCommandPathLen)
+	}
+
+	output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "get", "--output", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"json",
+		"yaml",
+		":4",
+		"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+
+	output, err = executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringOmits(t, output, "Extra Commands:")
+	checkStringContains(t, output, "Core Commands:")
+}
+
 func TestReplaceCommandWithRemove(t *testing.T) {
 	childUsed := 0
 	rootCmd := &Command{Use: "root", Run: emptyRun}
@@ -1888,6 +2198,117 @@ func TestUsageHelpGroup(t *testing.T) {
 	checkStringContains(t, output, "\ngroup\n  help")
 }
 
+func TestUsageGroupDescription(t *testing.T) {
+	var rootCmd = &Command{Use: "root", Short: "test", Run: emptyRun}
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.AddGroup(&Group{ID: "manage", Title: "Management Commands:", Description: "Commands to manage the resources."})
+	rootCmd.AddCommand(&Command{Use: "create", Short: "Create a resource", GroupID: "manage", Run: emptyRun})
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "\nManagement Commands:\nCommands to manage the resources.\n  create      Create a resource\n")
+}
+
+func TestHelpAndUsageTemplatesInheritance(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	ownCmd := &Command{Use: "own", Run: emptyRun}
+	rootCmd.AddCommand(childCmd, ownCmd)
+	rootCmd.SetHelpTemplate("root help for {{.Name}}\n")
+	rootCmd.SetUsageTemplate("root usage for {{.Name}}\n")
+	ownCmd.SetHelpTemplate("own help\n")
+	ownCmd.SetUsageTemplate("own usage\n")
+
+	output, err := executeCommand(rootCmd, "child", "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if output != "root help for child\n" {
+		t.Errorf("Expected the help template of the parent, got %q", output)
+	}
+	output, _ = executeCommand(rootCmd, "child", "--unknown")
+	checkStringContains(t, output, "root usage for child\n")
+
+	output, err = executeCommand(rootCmd, "own", "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if output != "own help\n" {
+		t.Errorf("Expected the help template of the command, got %q", output)
+	}
+	output, _ = executeCommand(rootCmd, "own", "--unknown")
+	checkStringContains(t, output, "own usage\n")
+}
+
+func TestHelpAndUsageFuncsInheritance(t *testing.T) {
+	var helpCalled, usageCalled string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.SetHelpFunc(func(c *Command, _ []string) { helpCalled = c.Name() })
+	rootCmd.SetUsageFunc(func(c *Command) error { usageCalled = c.Name(); return nil })
+
+	if _, err := executeCommand(rootCmd, "child", "--help"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if helpCalled != "child" {
+		t.Errorf("Expected the help function of the parent to be called for child, got %q", helpCalled)
+	}
+	if _, err := executeCommand(rootCmd, "child", "--unknown"); err == nil {
+		t.Error("Expected an error")
+	}
+	if usageCalled != "child" {
+		t.Errorf("Expected the usage function of the parent to be called for child, got %q", usageCalled)
+	}
+
+	childCmd.SetHelpFunc(func(c *Command, _ []string) { helpCalled = "own" })
+	if _, err := executeCommand(rootCmd, "child", "--help"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if helpCalled != "own" {
+		t.Errorf("Expected the help function of the command to be called, got %q", helpCalled)
+	}
+}
+
+func TestHelpGroup(t *testing.T) {
+	var rootCmd = &Command{Use: "root", Short: "test", Run: emptyRun}
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	remoteCmd := &Command{Use: "remote", Short: "Manage remotes"}
+	rootCmd.AddCommand(remoteCmd)
+
+	remoteCmd.AddGroup(&Group{ID: "edit", Title: "Edit Commands:", Description: "Commands which modify the remotes."})
+	remoteCmd.AddCommand(
+		&Command{Use: "add", Short: "Add a remote", GroupID: "edit", Run: emptyRun},
+		&Command{Use: "remove", Short: "Remove a remote", GroupID: "edit", Run: emptyRun},
+		&Command{Use: "list", Short: "List the remotes", Run: emptyRun},
+	)
+
+	output, err := executeCommand(rootCmd, "help", "remote", "edit")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := `Edit Commands:
+Commands which modify the remotes.
+
+  add         Add a remote
+  remove      Remove a remote
+
+Use "root remote [command] --help" for more information about a command.
+`
+	if output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+
+	output, err = executeCommand(rootCmd, "help", "edit")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "Unknown help topic")
+}
+
 func TestUsageCompletionGroup(t *testing.T) {
 	var rootCmd = &Command{Use: "root", Short: "test", Run: emptyRun}
 
@@ -1951,15 +2372,8 @@ func TestWrongGroupFirstLevel(t *testing.T) {
 	// Use the wrong group ID
 	rootCmd.AddCommand(&Command{Use: "cmd", GroupID: "wrong", Run: emptyRun})
 
-	defer func() {
-		if recover() == nil {
-			t.Errorf("The code should have panicked due to a missing group")
-		}
-	}()
-	_, err := executeCommand(rootCmd, "--help")
-	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
-	}
+	output, err := executeCommand(rootCmd, "--help")
+	checkMissingGroupError(t, output, err, "wrong", "root cmd")
 }
 
 func TestWrongGroupNestedLevel(t *testing.T) {
@@ -1971,15 +2385,8 @@ func TestWrongGroupNestedLevel(t *testing.T) {
 	// Use the wrong group ID
 	childCmd.AddCommand(&Command{Use: "cmd", GroupID: "wrong", Run: emptyRun})
 
-	defer func() {
-		if recover() == nil {
-			t.Errorf("The code should have panicked due to a missing group")
-		}
-	}()
-	_, err := executeCommand(rootCmd, "child", "--help")
-	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
-	}
+	output, err := executeCommand(rootCmd, "child", "--help")
+	checkMissingGroupError(t, output, err, "wrong", "root child cmd")
 }
 
 func TestWrongGroupForHelp(t *testing.T) {
@@ -1991,15 +2398,8 @@ func TestWrongGroupForHelp(t *testing.T) {
 	// Use the wrong group ID
 	rootCmd.SetHelpCommandGroupID("wrong")
 
-	defer func() {
-		if recover() == nil {
-			t.Errorf("The code should have panicked due to a missing group")
-		}
-	}()
-	_, err := executeCommand(rootCmd, "--help")
-	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
-	}
+	output, err := executeCommand(rootCmd, "--help")
+	checkMissingGroupError(t, output, err, "wrong", "root help")
 }
 
 func TestWrongGroupForCompletion(t *testing.T) {
@@ -2011,15 +2411,30 @@ func TestWrongGroupForCompletion(t *testing.T) {
 	// Use the wrong group ID
 	rootCmd.SetCompletionCommandGroupID("wrong")
 
+	output, err := executeCommand(rootCmd, "--help")
+	checkMissingGroupError(t, output, err, "wrong", "root completion")
+}
+
+func checkMissingGroupError(t *testing.T, output string, err error, groupID, path string) {
+	t.Helper()
+	var groupErr *MissingGroupError
+	if !errors.As(err, &groupErr) || groupErr.GroupID != groupID || groupErr.Command != path {
+		t.Fatalf("Expected a MissingGroupError for group %q of %q, got %v", groupID, path, err)
+	}
+	checkStringContains(t, output, fmt.Sprintf("Error: group id '%s' is not defined for subcommand '%s'", groupID, path))
+}
+
+func TestWrongGroupStrict(t *testing.T) {
+	var rootCmd = &Command{Use: "root", Short: "test", Run: emptyRun}
+	rootCmd.SetExecOptions(ExecOptions{StrictCommandGroups: true})
+	rootCmd.AddCommand(&Command{Use: "cmd", GroupID: "wrong", Run: emptyRun})
+
 	defer func() {
 		if recover() == nil {
 			t.Errorf("The code should have panicked due to a missing group")
 		}
 	}()
-	_, err := executeCommand(rootCmd, "--help")
-	if err != nil {
-		t.Errorf("Unexpected error: %v", err)
-	}
+	_, _ = executeCommand(rootCmd, "--help")
 }
 
 func TestSetOutput(t *testing.T) {
@@ -2817,3 +3232,106 @@ func TestUnknownFlagShouldReturnSameErrorRegardlessOfArgPosition(t *testing.T) {
 		})
 	}
 }
+
+func TestSetFlagsInterspersed(t *testing.T) {
+	var gotArgs []string
+	root := &Command{Use: "app", Run: emptyRun}
+	runCmd := &Command{
+		Use: "run",
+		Run: func(_ *Command, args []string) { gotArgs = args },
+	}
+	runCmd.Flags().BoolP("detach", "d", false, "")
+	runCmd.Flags().BoolP("x", "x", false, "")
+	root.AddCommand(runCmd)
+	root.SetFlagsInterspersed(false)
+
+	_, err := executeCommand(root, "run", "-d", "somecmd", "-x", "--other")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(gotArgs, " "); got != "somecmd -x --other" {
+		t.Errorf("Expected args 'somecmd -x --other', got %q", got)
+	}
+	if x, _ := runCmd.Flags().GetBool("x"); x {
+		t.Errorf("Expected -x to be passed as an argument")
+	}
+	if d, _ := runCmd.Flags().GetBool("detach"); !d {
+		t.Errorf("Expected -d to be parsed as a flag")
+	}
+}
+
+func TestSetFlagsInterspersedOverridesPOSIXStrict(t *testing.T) {
+	var gotArgs []string
+	root := &Command{Use: "app", Run: emptyRun}
+	root.SetExecOptions(ExecOptions{POSIXStrict: true})
+	root.Flags().Bool("x", false, "")
+	posixCmd := &Command{
+		Use: "posix",
+		Run: func(_ *Command, args []string) { gotArgs = args },
+	}
+	posixCmd.Flags().Bool("x", false, "")
+	interspersedCmd := &Command{
+		Use: "interspersed",
+		Run: func(_ *Command, args []string) { gotArgs = args },
+	}
+	interspersedCmd.Flags().Bool("x", false, "")
+	interspersedCmd.SetFlagsInterspersed(true)
+	root.AddCommand(posixCmd, interspersedCmd)
+
+	if _, err := executeCommand(root, "posix", "a", "--x"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(gotArgs, " "); got != "a --x" {
+		t.Errorf("Expected args 'a --x', got %q", got)
+	}
+
+	if _, err := executeCommand(root, "interspersed", "a", "--x"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := strings.Join(gotArgs, " "); got != "a" {
+		t.Errorf("Expected args 'a', got %q", got)
+	}
+}
+
+func TestSetExecOptions(t *testing.T) {
+	newTree := func() *Command {
+		root := &Command{Use: "root", Run: emptyRun}
+		root.AddCommand(&Command{Use: "status", Aliases: []string{"st"}, Run: emptyRun})
+		return root
+	}
+
+	defaultRoot := newTree()
+	if _, err := executeCommand(defaultRoot, "STAT"); err == nil {
+		t.Errorf("Expected an error without options")
+	}
+
+	root := newTree()
+	opts := DefaultExecOptions()
+	opts.PrefixMatching = true
+	opts.CaseInsensitiveCommands = true
+	root.SetExecOptions(opts)
+	for _, arg := range []string{"STAT", "Status", "stat"} {
+		if _, err := executeCommand(root, arg); err != nil {
+			t.Errorf("Unexpected error for %q: %v", arg, err)
+		}
+	}
+	if child, _, _ := root.Find([]string{"status"}); !child.ExecOptions().PrefixMatching {
+		t.Errorf("Expected the child to inherit the options")
+	}
+	if EnablePrefixMatching || EnableCaseInsensitive {
+		t.Errorf("Expected the globals to be unchanged")
+	}
+}
+
+func TestDefaultExecOptions(t *testing.T) {
+	EnableCaseInsensitive = true
+	defer func() { EnableCaseInsensitive = false }()
+
+	opts := (&Command{}).ExecOptions()
+	if !opts.CaseInsensitiveCommands || !opts.CaseInsensitiveAliases {
+		t.Errorf("Expected EnableCaseInsensitive to set both options, got %+v", opts)
+	}
+	if !opts.CommandSorting || opts.PrefixMatching {
+		t.Errorf("Unexpected default options %+v", opts)
+	}
+}
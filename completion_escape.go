@@ -0,0 +1,59 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "strings"
+
+// splitFlagAssignment splits an argument of the form '--flag=value' or '-f=value'
+// into the flag name and its value.  Only the first '=' separates the name from
+// the value, so values may themselves contain '=' (e.g. '--selector=app=foo'),
+// ':' (e.g. '--path=C:\dir') or glob characters.
+// For a group of shorthand flags such as '-asd=value', the name of the last
+// shorthand flag ('d') is returned, since it is the one receiving the value.
+// See https://github.com/spf13/cobra/issues/1257
+// The ok return value is false if arg is not a flag or does not contain an '='.
+func splitFlagAssignment(arg string) (name, value string, ok bool) {
+	if len(arg) < 2 || arg[0] != '-' {
+		return "", "", false
+	}
+	index := strings.Index(arg, "=")
+	if index < 0 {
+		return "", "", false
+	}
+
+	flagPart := arg[:index]
+	if strings.HasPrefix(flagPart, "--") {
+		// Flag has full name
+		name = flagPart[2:]
+	} else if len(flagPart) > 1 {
+		// Flag is shorthand
+		name = flagPart[len(flagPart)-1:]
+	}
+	if name == "" {
+		return "", "", false
+	}
+	return name, arg[index+1:], true
+}
+
+// flagNameFromArg returns the name of the flag in an argument of the form
+// '--flag' or '-f' which expects its value in the following argument.
+// For a group of shorthand flags such as '-asd', the last shorthand
+// flag name ('d') is returned.
+func flagNameFromArg(arg string) string {
+	if strings.HasPrefix(arg, "--") {
+		return arg[2:]
+	}
+	return arg[len(arg)-1:]
+}
@@ -0,0 +1,120 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSplitFlagAssignment(t *testing.T) {
+	testcases := []struct {
+		arg   string
+		name  string
+		value string
+		ok    bool
+	}{
+		{arg: "--selector=app=foo", name: "selector", value: "app=foo", ok: true},
+		{arg: "--selector=", name: "selector", value: "", ok: true},
+		{arg: "--path=C:\\dir", name: "path", value: "C:\\dir", ok: true},
+		{arg: "--host=localhost:8080", name: "host", value: "localhost:8080", ok: true},
+		{arg: "--glob=*.[ch]", name: "glob", value: "*.[ch]", ok: true},
+		{arg: "-s=a=b", name: "s", value: "a=b", ok: true},
+		{arg: "-asd=x:y", name: "d", value: "x:y", ok: true},
+		{arg: "--selector", ok: false},
+		{arg: "-s", ok: false},
+		{arg: "-=foo", ok: false},
+		{arg: "--=foo", ok: false},
+		{arg: "app=foo", ok: false},
+		{arg: "", ok: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.arg, func(t *testing.T) {
+			name, value, ok := splitFlagAssignment(tc.arg)
+			if ok != tc.ok || name != tc.name || value != tc.value {
+				t.Errorf("expected (%q, %q, %v), got (%q, %q, %v)", tc.name, tc.value, tc.ok, name, value, ok)
+			}
+		})
+	}
+}
+
+func TestFlagCompletionValueWithSpecialChars(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().StringP("selector", "s", "", "selector")
+	rootCmd.Flags().String("path", "", "path")
+	var gotToComplete []string
+	completionFunc := func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+		gotToComplete = append(gotToComplete, toComplete)
+		return []string{toComplete + "1"}, ShellCompDirectiveNoFileComp
+	}
+	assertNoErr(t, rootCmd.RegisterFlagCompletionFunc("selector", completionFunc))
+	assertNoErr(t, rootCmd.RegisterFlagCompletionFunc("path", completionFunc))
+
+	testcases := []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"--selector=app=foo"}, expected: "app=foo1"},
+		{args: []string{"-s=app=foo"}, expected: "app=foo1"},
+		{args: []string{"--selector", "app=foo"}, expected: "app=foo1"},
+		{args: []string{"--path=C:\\x"}, expected: "C:\\x1"},
+		{args: []string{"--path", "host:/dir"}, expected: "host:/dir1"},
+	}
+
+	for _, tc := range testcases {
+		gotToComplete = nil
+		output, err := executeCommand(rootCmd, append([]string{ShellCompNoDescRequestCmd}, tc.args...)...)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		expected := strings.Join([]string{
+			tc.expected,
+			":4",
+			"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+
+		if output != expected {
+			t.Errorf("%v: expected: %q, got: %q", tc.args, expected, output)
+		}
+		if len(gotToComplete) != 1 || gotToComplete[0]+"1" != tc.expected {
+			t.Errorf("%v: unexpected toComplete passed to completion function: %q", tc.args, gotToComplete)
+		}
+	}
+}
+
+func TestFlagPrefixWithEqualInScripts(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+
+	buf := new(bytes.Buffer)
+	assertNoErr(t, rootCmd.GenFishCompletion(buf, true))
+	check(t, buf.String(), `string match -r -- '^-[^=]*=' "$lastArg"`)
+	checkOmit(t, buf.String(), `'-.*='`)
+
+	buf.Reset()
+	assertNoErr(t, rootCmd.GenZshCompletion(buf))
+	check(t, buf.String(), `=~ '^-[^=]*='`)
+	checkOmit(t, buf.String(), `'-.*='`)
+
+	buf.Reset()
+	assertNoErr(t, rootCmd.GenPowerShellCompletion(buf))
+	check(t, buf.String(), `$WordToComplete -Like "-*=*"`)
+	check(t, buf.String(), `[WildcardPattern]::Escape($WordToComplete)`)
+
+	buf.Reset()
+	assertNoErr(t, rootCmd.GenBashCompletionV2(buf, true))
+	check(t, buf.String(), `cur="${cur#*=}"`)
+}
@@ -0,0 +1,49 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CompletionShells are the shells supported by GenCompletionScript.
+var CompletionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// GenCompletionScript writes to w the completion script of the program called name
+// for shell, one of CompletionShells.  Bash uses the completion V2 script.
+//
+// The scripts only depend on the name of the program, which they call to obtain
+// the completions, so they can be generated, for instance at release time, without
+// building or executing its tree of commands.
+func GenCompletionScript(w io.Writer, name, shell string, includeDesc bool) error {
+	buf := new(bytes.Buffer)
+	switch shell {
+	case "bash":
+		genBashComp(buf, name, includeDesc)
+	case "zsh":
+		genZshComp(buf, name, includeDesc)
+	case "fish":
+		genFishComp(buf, name, includeDesc)
+	case "powershell":
+		genPowerShellComp(buf, name, includeDesc)
+	default:
+		return fmt.Errorf("unsupported shell %q, must be one of %s", shell, strings.Join(CompletionShells, ", "))
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,49 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenCompletionScript(t *testing.T) {
+	rootCmd := &Command{Use: "prog", Run: emptyRun}
+
+	testcases := []struct {
+		shell string
+		gen   func(*bytes.Buffer) error
+	}{
+		{"bash", func(b *bytes.Buffer) error { return rootCmd.GenBashCompletionV2(b, false) }},
+		{"zsh", func(b *bytes.Buffer) error { return rootCmd.GenZshCompletionNoDesc(b) }},
+		{"fish", func(b *bytes.Buffer) error { return rootCmd.GenFishCompletion(b, false) }},
+		{"powershell", func(b *bytes.Buffer) error { return rootCmd.GenPowerShellCompletion(b) }},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.shell, func(t *testing.T) {
+			expected, got := new(bytes.Buffer), new(bytes.Buffer)
+			assertNoErr(t, tc.gen(expected))
+			assertNoErr(t, GenCompletionScript(got, "prog", tc.shell, false))
+			if got.String() != expected.String() {
+				t.Error("Expected the script generated by the command")
+			}
+		})
+	}
+
+	err := GenCompletionScript(new(bytes.Buffer), "prog", "tcsh", true)
+	if err == nil || err.Error() != `unsupported shell "tcsh", must be one of bash, zsh, fish, powershell` {
+		t.Errorf("Expected an unsupported shell error, got %v", err)
+	}
+}
@@ -15,12 +15,17 @@
 package cobra
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -34,11 +39,61 @@ const (
 	ShellCompNoDescRequestCmd = "__completeNoDesc"
 )
 
-// Global map of flag completion functions. Make sure to use flagCompletionMutex before you try to read and write from it.
-var flagCompletionFunctions = map[*pflag.Flag]func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective){}
+// flagCompletionFuncs maps flags to their completion function.
+type flagCompletionFuncs map[*pflag.Flag]func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)
 
-// lock for reading and writing from flagCompletionFunctions
-var flagCompletionMutex = &sync.RWMutex{}
+// flagCompletionRegistry holds the flag completion functions registered on a command.
+// Registering a function replaces the map by an updated copy, so that readers can use
+// a snapshot of it without holding any lock.  The zero value is an empty registry.
+type flagCompletionRegistry struct {
+	funcs atomic.Value // flagCompletionFuncs
+}
+
+// flagCompletionWriteMutex serializes the registrations of all the registries,
+// which holds no lock of their own so that commands can be copied.
+var flagCompletionWriteMutex sync.Mutex
+
+// snapshot returns the functions registered so far; it must not be modified.
+func (r *flagCompletionRegistry) snapshot() flagCompletionFuncs {
+	funcs, _ := r.funcs.Load().(flagCompletionFuncs)
+	return funcs
+}
+
+// register adds the completion function of flag, unless one is already registered.
+func (r *flagCompletionRegistry) register(flag *pflag.Flag, f func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)) bool {
+	flagCompletionWriteMutex.Lock()
+	defer flagCompletionWriteMutex.Unlock()
+
+	current := r.snapshot()
+	if _, exists := current[flag]; exists {
+		return false
+	}
+	updated := make(flagCompletionFuncs, len(current)+1)
+	for k, v := range current {
+		updated[k] = v
+	}
+	updated[flag] = f
+	r.funcs.Store(updated)
+	return true
+}
+
+// unregister removes the completion function of flag, if any.
+func (r *flagCompletionRegistry) unregister(flag *pflag.Flag) {
+	flagCompletionWriteMutex.Lock()
+	defer flagCompletionWriteMutex.Unlock()
+
+	current := r.snapshot()
+	if _, exists := current[flag]; !exists {
+		return
+	}
+	updated := make(flagCompletionFuncs, len(current))
+	for k, v := range current {
+		if k != flag {
+			updated[k] = v
+		}
+	}
+	r.funcs.Store(updated)
+}
 
 // ShellCompDirective is a bit map representing the different behaviors the shell
 // can be instructed to have once completions have been provided.
@@ -83,6 +138,12 @@ const (
 	// in which the completions are provided
 	ShellCompDirectiveKeepOrder
 
+	// ShellCompDirectiveShellCommand indicates that the first completion provided
+	// is a shell command, such as "kubectl get ns -o name", which the completion
+	// script executes in the shell of the user: each line it prints is a completion.
+	// MarkFlagCompletionCommand is a shortcut to using this directive for flags.
+	ShellCompDirectiveShellCommand
+
 	// ===========================================================================
 
 	// All directives using iota should be above this one.
@@ -101,6 +162,8 @@ const (
 	compCmdNoDescFlagName    = "no-descriptions"
 	compCmdNoDescFlagDesc    = "disable completion descriptions"
 	compCmdNoDescFlagDefault = false
+	compCmdShellFlagName     = "shell"
+	compCmdShellFlagDesc     = "shell to generate the autocompletion script for, detected if not set"
 )
 
 // CompletionOptions are the options to control shell completion
@@ -115,6 +178,61 @@ type CompletionOptions struct {
 	DisableDescriptions bool
 	// HiddenDefaultCmd makes the default 'completion' command hidden
 	HiddenDefaultCmd bool
+
+	// The options below can be set on any command and apply to the command
+	// and all its sub-commands.  They are merged with the options of the
+	// parent commands up to the root: an option set on any of them applies.
+
+	// DisableCompletion turns off all completions, including file completion,
+	// for the command and its sub-commands
+	DisableCompletion bool
+	// NoFileCompletion turns off file completion for the command and its sub-commands
+	// whenever no other completion directive is requested; it avoids having to set
+	// a ValidArgsFunction of NoFileCompletions on every command of the sub-tree.
+	NoFileCompletion bool
+	// Timeout limits the time the completion functions of the command and its
+	// sub-commands may take: the context of the command is cancelled after it.
+	// The timeout of the closest command setting one applies.
+	Timeout time.Duration
+}
+
+// completionTimeout returns the completion timeout of the command or of its closest parent setting one.
+func (c *Command) completionTimeout() time.Duration {
+	for p := c; p != nil; p = p.Parent() {
+		if p.CompletionOptions.Timeout > 0 {
+			return p.CompletionOptions.Timeout
+		}
+	}
+	return 0
+}
+
+// completionDisabled returns true if completion was disabled for the command or one of its parents.
+func (c *Command) completionDisabled() bool {
+	for p := c; p != nil; p = p.Parent() {
+		if p.CompletionOptions.DisableCompletion {
+			return true
+		}
+	}
+	return false
+}
+
+// noFileCompletion returns true if file completion was turned off for the command or one of its parents.
+func (c *Command) noFileCompletion() bool {
+	for p := c; p != nil; p = p.Parent() {
+		if p.CompletionOptions.NoFileCompletion {
+			return true
+		}
+	}
+	return false
+}
+
+// applyCompletionOptions adjusts the directive according to the completion options
+// which apply to the command.
+func (c *Command) applyCompletionOptions(directive ShellCompDirective) ShellCompDirective {
+	if directive == ShellCompDirectiveDefault && c.noFileCompletion() {
+		return ShellCompDirectiveNoFileComp
+	}
+	return directive
 }
 
 // NoFileCompletions can be used to disable file completion for commands that should
@@ -131,19 +249,28 @@ func FixedCompletions(choices []string, directive ShellCompDirective) func(cmd *
 	}
 }
 
+// CompletionFuncContext is a completion function which receives the context of the
+// command, cancelled once the completion timeout expires, see CompletionOptions.Timeout.
+type CompletionFuncContext func(ctx context.Context, cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)
+
+// CompletionWithContext adapts f to be used as a ValidArgsFunction or a flag
+// completion function, for completions which should stop when they are cancelled,
+// e.g. because they query a remote server.
+func CompletionWithContext(f CompletionFuncContext) func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+	return func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+		return f(cmd.Context(), cmd, args, toComplete)
+	}
+}
+
 // RegisterFlagCompletionFunc should be called to register a function to provide completion for a flag.
 func (c *Command) RegisterFlagCompletionFunc(flagName string, f func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)) error {
 	flag := c.Flag(flagName)
 	if flag == nil {
 		return fmt.Errorf("RegisterFlagCompletionFunc: flag '%s' does not exist", flagName)
 	}
-	flagCompletionMutex.Lock()
-	defer flagCompletionMutex.Unlock()
-
-	if _, exists := flagCompletionFunctions[flag]; exists {
+	if !c.flagCompletions.register(flag, f) {
 		return fmt.Errorf("RegisterFlagCompletionFunc: flag '%s' already registered", flagName)
 	}
-	flagCompletionFunctions[flag] = f
 	return nil
 }
 
@@ -153,12 +280,45 @@ func (c *Command) GetFlagCompletionFunc(flagName string) (func(*Command, []strin
 	if flag == nil {
 		return nil, false
 	}
+	return c.findFlagCompletionFunc(flag)
+}
 
-	flagCompletionMutex.RLock()
-	defer flagCompletionMutex.RUnlock()
+// RegisterArgCompletionFunc registers the function providing the completions of the
+// positional argument at index, starting from 0, of the command.  It takes precedence
+// over the completion function of a parameter, see Params, and over ValidArgsFunction,
+// which still provide the completions of the other positions.
+func (c *Command) RegisterArgCompletionFunc(index int, f func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)) error {
+	if index < 0 {
+		return fmt.Errorf("RegisterArgCompletionFunc: invalid argument index %d", index)
+	}
+	if _, exists := c.argCompletions[index]; exists {
+		return fmt.Errorf("RegisterArgCompletionFunc: argument %d already registered", index)
+	}
+	if c.argCompletions == nil {
+		c.argCompletions = make(map[int]func(*Command, []string, string) ([]string, ShellCompDirective))
+	}
+	c.argCompletions[index] = f
+	return nil
+}
+
+// findFlagCompletionFunc returns the completion function registered for flag.
+// Functions are looked up on c and its parents first, and then on the whole tree
+// since persistent flags may have had their function registered on any command
+// inheriting them.
+func (c *Command) findFlagCompletionFunc(flag *pflag.Flag) (func(*Command, []string, string) ([]string, ShellCompDirective), bool) {
+	for p := c; p != nil; p = p.Parent() {
+		if f, exists := p.flagCompletions.snapshot()[flag]; exists {
+			return f, true
+		}
+	}
 
-	completionFunc, exists := flagCompletionFunctions[flag]
-	return completionFunc, exists
+	var completionFunc func(*Command, []string, string) ([]string, ShellCompDirective)
+	c.Root().visitTree(func(cmd *Command) {
+		if completionFunc == nil {
+			completionFunc = cmd.flagCompletions.snapshot()[flag]
+		}
+	})
+	return completionFunc, completionFunc != nil
 }
 
 // Returns a string listing the different directive enabled in the specified parameter
@@ -182,6 +342,9 @@ func (d ShellCompDirective) string() string {
 	if d&ShellCompDirectiveKeepOrder != 0 {
 		directives = append(directives, "ShellCompDirectiveKeepOrder")
 	}
+	if d&ShellCompDirectiveShellCommand != 0 {
+		directives = append(directives, "ShellCompDirectiveShellCommand")
+	}
 	if len(directives) == 0 {
 		directives = append(directives, "ShellCompDirectiveDefault")
 	}
@@ -192,9 +355,12 @@ func (d ShellCompDirective) string() string {
 	return strings.Join(directives, ", ")
 }
 
-// initCompleteCmd adds a special hidden command that can be used to request custom completions.
-func (c *Command) initCompleteCmd(args []string) {
-	completeCmd := &Command{
+// newCompleteCmd returns the special hidden command that can be used to request custom completions.
+// It is added to the tree as an ephemeral command to avoid side-effects of having such a command:
+// for example, it would cause problems to a cobra program that only consists of the root command,
+// since this command would cause the root command to suddenly have a subcommand.
+func newCompleteCmd() *Command {
+	return &Command{
 		Use:                   fmt.Sprintf("%s [command-line]", ShellCompRequestCmd),
 		Aliases:               []string{ShellCompNoDescRequestCmd},
 		DisableFlagsInUseLine: true,
@@ -207,7 +373,7 @@ func (c *Command) initCompleteCmd(args []string) {
 		Run: func(cmd *Command, args []string) {
 			finalCmd, completions, directive, err := cmd.getCompletions(args)
 			if err != nil {
-				CompErrorln(err.Error())
+				compDebug(cmd.ErrOrStderr(), "[Error] "+err.Error()+"\n", true)
 				// Keep going for multiple reasons:
 				// 1- There could be some valid completions even though there was an error
 				// 2- Even without completions, we need to print the directive
@@ -258,19 +424,44 @@ func (c *Command) initCompleteCmd(args []string) {
 			fmt.Fprintf(finalCmd.ErrOrStderr(), "Completion ended with directive: %s\n", directive.string())
 		},
 	}
-	c.AddCommand(completeCmd)
-	subCmd, _, err := c.Find(args)
-	if err != nil || subCmd.Name() != ShellCompRequestCmd {
-		// Only create this special command if it is actually being called.
-		// This reduces possible side-effects of creating such a command;
-		// for example, having this command would cause problems to a
-		// cobra program that only consists of the root command, since this
-		// command would cause the root command to suddenly have a subcommand.
-		c.RemoveCommand(completeCmd)
+}
+
+// CompletionFilter post-processes the completions computed for cmd before they are
+// returned to the shell, for instance to remove some of them or to change the directive.
+type CompletionFilter func(cmd *Command, completions []string, directive ShellCompDirective) ([]string, ShellCompDirective)
+
+// SetCompletionFilter sets the filter applied to all the completions computed for c
+// and its children which do not set their own, including the completions of flags
+// and sub-command names.
+func (c *Command) SetCompletionFilter(f CompletionFilter) {
+	c.completionFilter = f
+}
+
+// CompletionFilter returns the filter set by SetCompletionFilter for this command
+// or a parent, or nil if none was set.
+func (c *Command) CompletionFilter() CompletionFilter {
+	if c.completionFilter != nil {
+		return c.completionFilter
+	}
+	if c.HasParent() {
+		return c.parent.CompletionFilter()
 	}
+	return nil
 }
 
 func (c *Command) getCompletions(args []string) (*Command, []string, ShellCompDirective, error) {
+	finalCmd, completions, directive, err := c.collectCompletions(args)
+	if err != nil {
+		return finalCmd, completions, directive, err
+	}
+	if filter := finalCmd.CompletionFilter(); filter != nil {
+		completions, directive = filter(finalCmd, completions, directive)
+	}
+	return finalCmd, completions, directive, nil
+}
+
+// collectCompletions computes the completions of the last argument of args.
+func (c *Command) collectCompletions(args []string) (*Command, []string, ShellCompDirective, error) {
 	// The last argument, which is not completely typed by the user,
 	// should not be part of the list of arguments
 	toComplete := args[len(args)-1]
@@ -286,12 +477,13 @@ func (c *Command) getCompletions(args []string) (*Command, []string, ShellCompDi
 	} else {
 		// For Root commands that don't specify any value for their Args fields, when we call
 		// Find(), if those Root commands don't have any sub-commands, they will accept arguments.
-		// However, because we have added the __complete sub-command in the current code path, the
-		// call to Find() -> legacyArgs() will return an error if there are any arguments.
-		// To avoid this, we first remove the __complete command to get back to having no sub-commands.
+		// However, because we have added the __complete sub-command and the other ephemeral commands
+		// in the current code path, the call to Find() -> legacyArgs() will return an error if there
+		// are any arguments.  To avoid this, we first remove the ephemeral commands to get back to
+		// having no sub-commands.
 		rootCmd := c.Root()
-		if len(rootCmd.Commands()) == 1 {
-			rootCmd.RemoveCommand(c)
+		if ephemeral := rootCmd.ephemeralChildren(); len(ephemeral) == len(rootCmd.Commands()) {
+			rootCmd.removeCommands(ephemeral...)
 		}
 
 		finalCmd, finalArgs, err = rootCmd.Find(trimmedArgs)
@@ -302,6 +494,10 @@ func (c *Command) getCompletions(args []string) (*Command, []string, ShellCompDi
 	}
 	finalCmd.ctx = c.ctx
 
+	if finalCmd.completionDisabled() {
+		return finalCmd, []string{}, ShellCompDirectiveNoFileComp, nil
+	}
+
 	// These flags are normally added when `execute()` is called on `finalCmd`,
 	// however, when doing completion, we don't call `finalCmd.execute()`.
 	// Let's add the --help and --version flag ourselves but only if the finalCmd
@@ -312,6 +508,12 @@ func (c *Command) getCompletions(args []string) (*Command, []string, ShellCompDi
 		finalCmd.InitDefaultVersionFlag()
 	}
 
+	// Find() does not merge the persistent flags of the parents into the flags of
+	// finalCmd when no argument follows it, and persistent flags may have been added
+	// to the parents since they were last merged; make sure they are all known
+	// before looking for the flag being completed.
+	finalCmd.mergePersistentFlags()
+
 	// Check if we are doing flag value completion before parsing the flags.
 	// This is important because if we are completing a flag value, we need to also
 	// remove the flag name argument from the list of finalArgs or else the parsing
@@ -404,7 +606,7 @@ func (c *Command) getCompletions(args []string) (*Command, []string, ShellCompDi
 					strings.Contains(flag.Value.Type(), "Array") {
 					// If the flag is not already present, or if it can be specified multiple times (Array or Slice)
 					// we suggest it as a completion
-					completions = append(completions, getFlagNameCompletions(flag, toComplete)...)
+					completions = append(completions, getFlagNameCompletions(finalCmd, flag, toComplete)...)
 				}
 			}
 
@@ -461,12 +663,20 @@ func (c *Command) getCompletions(args []string) (*Command, []string, ShellCompDi
 				// - there are no local, non-persistent flags on the command-line or TraverseChildren is true
 				for _, subCmd := range finalCmd.Commands() {
 					if subCmd.IsAvailableCommand() || subCmd == finalCmd.helpCommand {
-						if strings.HasPrefix(subCmd.Name(), toComplete) {
+						if finalCmd.ExecOptions().commandNameHasPrefix(subCmd.Name(), toComplete) {
 							completions = append(completions, fmt.Sprintf("%s\t%s", subCmd.Name(), subCmd.Short))
 						}
 						directive = ShellCompDirectiveNoFileComp
 					}
 				}
+				if !finalCmd.HasParent() {
+					for _, plugin := range finalCmd.Plugins() {
+						if finalCmd.ExecOptions().commandNameHasPrefix(plugin.Name, toComplete) && !finalCmd.hasSubCommand(plugin.Name) {
+							completions = append(completions, fmt.Sprintf("%s\tplugin", plugin.Name))
+						}
+						directive = ShellCompDirectiveNoFileComp
+					}
+				}
 			}
 
 			// Complete required flags even without the '-' prefix
@@ -497,7 +707,7 @@ func (c *Command) getCompletions(args []string) (*Command, []string, ShellCompDi
 
 				// If there are ValidArgs specified (even if they don't match), we stop completion.
 				// Only one of ValidArgs or ValidArgsFunction can be used for a single command.
-				return finalCmd, completions, directive, nil
+				return finalCmd, completions, finalCmd.applyCompletionOptions(directive), nil
 			}
 
 			// Let the logic continue so as to add any ValidArgsFunction completions,
@@ -509,21 +719,34 @@ func (c *Command) getCompletions(args []string) (*Command, []string, ShellCompDi
 	// Find the completion function for the flag or command
 	var completionFn func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)
 	if flag != nil && flagCompletion {
-		flagCompletionMutex.RLock()
-		completionFn = flagCompletionFunctions[flag]
-		flagCompletionMutex.RUnlock()
+		completionFn, _ = finalCmd.findFlagCompletionFunc(flag)
 	} else {
-		completionFn = finalCmd.ValidArgsFunction
+		completionFn = finalCmd.argCompletions[len(finalArgs)]
+		if completionFn == nil {
+			completionFn = finalCmd.paramCompletionFunc(finalArgs)
+		}
+		if completionFn == nil {
+			completionFn = finalCmd.ValidArgsFunction
+		}
 	}
 	if completionFn != nil {
 		// Go custom completion defined for this flag or command.
 		// Call the registered completion function to get the completions.
+		if timeout := finalCmd.completionTimeout(); timeout > 0 {
+			ctx := finalCmd.Context()
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			finalCmd.ctx = ctx
+		}
 		var comps []string
 		comps, directive = completionFn(finalCmd, finalArgs, toComplete)
 		completions = append(completions, comps...)
 	}
 
-	return finalCmd, completions, directive, nil
+	return finalCmd, completions, finalCmd.applyCompletionOptions(directive), nil
 }
 
 func helpOrVersionFlagPresent(cmd *Command) bool {
@@ -538,14 +761,15 @@ func helpOrVersionFlagPresent(cmd *Command) bool {
 	return false
 }
 
-func getFlagNameCompletions(flag *pflag.Flag, toComplete string) []string {
+func getFlagNameCompletions(cmd *Command, flag *pflag.Flag, toComplete string) []string {
 	if nonCompletableFlag(flag) {
 		return []string{}
 	}
 
 	var completions []string
 	flagName := "--" + flag.Name
-	if strings.HasPrefix(flagName, toComplete) {
+	if strings.HasPrefix(flagName, toComplete) ||
+		(cmd.ExecOptions().CaseInsensitiveFlags && strings.HasPrefix(strings.ToLower(flagName), strings.ToLower(toComplete))) {
 		// Flag without the =
 		completions = append(completions, fmt.Sprintf("%s\t%s", flagName, flag.Usage))
 
@@ -578,7 +802,7 @@ func completeRequireFlags(finalCmd *Command, toComplete string) []string {
 		if _, present := flag.Annotations[BashCompOneRequiredFlag]; present {
 			if !flag.Changed {
 				// If the flag is not already present, we suggest it as a completion
-				completions = append(completions, getFlagNameCompletions(flag, toComplete)...)
+				completions = append(completions, getFlagNameCompletions(finalCmd, flag, toComplete)...)
 			}
 		}
 	}
@@ -612,24 +836,15 @@ func checkIfFlagCompletion(finalCmd *Command, args []string, lastArg string) (*p
 	// a '-' we know it is a flag.  We cannot use isFlagArg() here as that function
 	// requires the flag name to be complete
 	if len(lastArg) > 0 && lastArg[0] == '-' {
-		if index := strings.Index(lastArg, "="); index >= 0 {
-			// Flag with an =
-			if strings.HasPrefix(lastArg[:index], "--") {
-				// Flag has full name
-				flagName = lastArg[2:index]
-			} else {
-				// Flag is shorthand
-				// We have to get the last shorthand flag name
-				// e.g. `-asd` => d to provide the correct completion
-				// https://github.com/spf13/cobra/issues/1257
-				flagName = lastArg[index-1 : index]
-			}
-			lastArg = lastArg[index+1:]
-			flagWithEqual = true
-		} else {
+		name, value, ok := splitFlagAssignment(lastArg)
+		if !ok {
 			// Normal flag completion
 			return nil, args, lastArg, nil
 		}
+		// Flag with an =; the value may itself contain '=' or ':'
+		flagName = name
+		lastArg = value
+		flagWithEqual = true
 	}
 
 	if len(flagName) == 0 {
@@ -640,16 +855,7 @@ func checkIfFlagCompletion(finalCmd *Command, args []string, lastArg string) (*p
 				// If the flag contains an = it means it has already been fully processed,
 				// so we don't need to deal with it here.
 				if index := strings.Index(prevArg, "="); index < 0 {
-					if strings.HasPrefix(prevArg, "--") {
-						// Flag has full name
-						flagName = prevArg[2:]
-					} else {
-						// Flag is shorthand
-						// We have to get the last shorthand flag name
-						// e.g. `-asd` => d to provide the correct completion
-						// https://github.com/spf13/cobra/issues/1257
-						flagName = prevArg[len(prevArg)-1:]
-					}
+					flagName = flagNameFromArg(prevArg)
 					// Remove the uncompleted flag or else there could be an error created
 					// for an invalid value for that flag
 					trimmedArgs = args[:len(args)-1]
@@ -706,7 +912,9 @@ func (c *Command) InitDefaultCompletionCmd() {
 		Short: "Generate the autocompletion script for the specified shell",
 		Long: fmt.Sprintf(`Generate the autocompletion script for %[1]s for the specified shell.
 See each sub-command's help for details on how to use the generated script.
-`, c.Root().Name()),
+
+When no shell is specified, the script for the calling shell is generated.
+`, c.Root().displayName()),
 		Args:              NoArgs,
 		ValidArgsFunction: NoFileCompletions,
 		Hidden:            c.CompletionOptions.HiddenDefaultCmd,
@@ -716,6 +924,37 @@ See each sub-command's help for details on how to use the generated script.
 
 	out := c.OutOrStdout()
 	noDesc := c.CompletionOptions.DisableDescriptions
+	var shell string
+	completionCmd.Flags().StringVar(&shell, compCmdShellFlagName, "", compCmdShellFlagDesc)
+	_ = completionCmd.RegisterFlagCompletionFunc(compCmdShellFlagName, func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+		var shells []string
+		for _, sub := range cmd.Commands() {
+			shells = append(shells, sub.Name())
+		}
+		return shells, ShellCompDirectiveNoFileComp
+	})
+	if haveNoDescFlag {
+		completionCmd.Flags().BoolVar(&noDesc, compCmdNoDescFlagName, compCmdNoDescFlagDefault, compCmdNoDescFlagDesc)
+	}
+	completionCmd.RunE = func(cmd *Command, args []string) error {
+		name := shell
+		if name == "" {
+			name = detectShell()
+		}
+		var shells []string
+		for _, sub := range cmd.Commands() {
+			if sub.Name() == name {
+				return sub.RunE(sub, args)
+			}
+			shells = append(shells, sub.Name())
+		}
+		hint := fmt.Sprintf(`run "%[1]s <shell>" or "%[1]s --%[2]s <shell>" with one of: %[3]s`,
+			cmd.CommandPath(), compCmdShellFlagName, strings.Join(shells, ", "))
+		if name == "" {
+			return fmt.Errorf("cannot detect your shell, %s", hint)
+		}
+		return fmt.Errorf("unsupported shell %q, %s", name, hint)
+	}
 	shortDesc := "Generate the autocompletion script for %s"
 	bash := &Command{
 		Use:   "bash",
@@ -727,20 +966,20 @@ If it is not installed already, you can install it via your OS's package manager
 
 To load completions in your current shell session:
 
-	source <(%[1]s completion bash)
+	source <(%[2]s completion bash)
 
 To load completions for every new session, execute once:
 
 #### Linux:
 
-	%[1]s completion bash > /etc/bash_completion.d/%[1]s
+	%[2]s completion bash > /etc/bash_completion.d/%[1]s
 
 #### macOS:
 
-	%[1]s completion bash > $(brew --prefix)/etc/bash_completion.d/%[1]s
+	%[2]s completion bash > $(brew --prefix)/etc/bash_completion.d/%[1]s
 
 You will need to start a new shell for this setup to take effect.
-`, c.Root().Name()),
+`, c.Root().Name(), c.Root().displayName()),
 		Args:                  NoArgs,
 		DisableFlagsInUseLine: true,
 		ValidArgsFunction:     NoFileCompletions,
@@ -764,20 +1003,20 @@ to enable it.  You can execute the following once:
 
 To load completions in your current shell session:
 
-	source <(%[1]s completion zsh)
+	source <(%[2]s completion zsh)
 
 To load completions for every new session, execute once:
 
 #### Linux:
 
-	%[1]s completion zsh > "${fpath[1]}/_%[1]s"
+	%[2]s completion zsh > "${fpath[1]}/_%[1]s"
 
 #### macOS:
 
-	%[1]s completion zsh > $(brew --prefix)/share/zsh/site-functions/_%[1]s
+	%[2]s completion zsh > $(brew --prefix)/share/zsh/site-functions/_%[1]s
 
 You will need to start a new shell for this setup to take effect.
-`, c.Root().Name()),
+`, c.Root().Name(), c.Root().displayName()),
 		Args:              NoArgs,
 		ValidArgsFunction: NoFileCompletions,
 		RunE: func(cmd *Command, args []string) error {
@@ -798,14 +1037,14 @@ You will need to start a new shell for this setup to take effect.
 
 To load completions in your current shell session:
 
-	%[1]s completion fish | source
+	%[2]s completion fish | source
 
 To load completions for every new session, execute once:
 
-	%[1]s completion fish > ~/.config/fish/completions/%[1]s.fish
+	%[2]s completion fish > ~/.config/fish/completions/%[1]s.fish
 
 You will need to start a new shell for this setup to take effect.
-`, c.Root().Name()),
+`, c.Root().Name(), c.Root().displayName()),
 		Args:              NoArgs,
 		ValidArgsFunction: NoFileCompletions,
 		RunE: func(cmd *Command, args []string) error {
@@ -823,11 +1062,11 @@ You will need to start a new shell for this setup to take effect.
 
 To load completions in your current shell session:
 
-	%[1]s completion powershell | Out-String | Invoke-Expression
+	%[2]s completion powershell | Out-String | Invoke-Expression
 
 To load completions for every new session, add the output of the above command
 to your powershell profile.
-`, c.Root().Name()),
+`, c.Root().Name(), c.Root().displayName()),
 		Args:              NoArgs,
 		ValidArgsFunction: NoFileCompletions,
 		RunE: func(cmd *Command, args []string) error {
@@ -845,6 +1084,33 @@ to your powershell profile.
 	completionCmd.AddCommand(bash, zsh, fish, powershell)
 }
 
+// parentProcessName returns the name of the parent process, or an empty string
+// if it cannot be determined.  It is a variable so that tests can replace it.
+var parentProcessName = func() string {
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", os.Getppid()))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+// detectShell returns the name of the calling shell, as used by the sub-commands
+// of the completion command, from the parent process or else from $SHELL.
+// It returns an empty string if the shell cannot be detected.
+func detectShell() string {
+	for _, name := range []string{parentProcessName(), os.Getenv("SHELL")} {
+		name = strings.TrimPrefix(filepath.Base(name), "-")
+		name = strings.TrimSuffix(name, ".exe")
+		switch name {
+		case "bash", "zsh", "fish":
+			return name
+		case "pwsh", "powershell":
+			return "powershell"
+		}
+	}
+	return ""
+}
+
 func findFlag(cmd *Command, name string) *pflag.Flag {
 	flagSet := cmd.Flags()
 	if len(name) == 1 {
@@ -869,6 +1135,12 @@ func findFlag(cmd *Command, name string) *pflag.Flag {
 // Note that completion printouts should never be on stdout as they would
 // be wrongly interpreted as actual completion choices by the completion script.
 func CompDebug(msg string, printToStdErr bool) {
+	compDebug(os.Stderr, msg, printToStdErr)
+}
+
+// compDebug is CompDebug printing to w, which should be the error output of
+// the command, rather than to the standard error.
+func compDebug(w io.Writer, msg string, print bool) {
 	msg = fmt.Sprintf("[Debug] %s", msg)
 
 	// Such logs are only printed when the user has set the environment
@@ -882,9 +1154,9 @@ func CompDebug(msg string, printToStdErr bool) {
 		}
 	}
 
-	if printToStdErr {
+	if print {
 		// Must print to stderr for this not to be read by the completion script.
-		fmt.Fprint(os.Stderr, msg)
+		fmt.Fprint(w, msg)
 	}
 }
 
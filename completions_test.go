@@ -22,6 +22,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func validArgsFunc(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
@@ -261,6 +262,7 @@ func TestNoCmdNameCompletionInGo(t *testing.T) {
 	}
 
 	expected = strings.Join([]string{
+		"[Debug] [Error] Error while parsing flags from args [--localroot value]: unknown flag: --localroot",
 		":0",
 		"Completion ended with directive: ShellCompDirectiveDefault", ""}, "\n")
 
@@ -2076,6 +2078,60 @@ func TestFlagCompletionForPersistentFlagsCalledFromSubCmd(t *testing.T) {
 	}
 }
 
+func TestCompletionPersistentFlagsOfAncestors(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	aCmd := &Command{Use: "a", Run: emptyRun}
+	bCmd := &Command{Use: "b", Run: emptyRun}
+	cCmd := &Command{Use: "c", Run: emptyRun}
+	rootCmd.AddCommand(aCmd)
+	aCmd.AddCommand(bCmd)
+	bCmd.AddCommand(cCmd)
+
+	// Complete once so that the flags of the tree get merged.
+	if _, err := executeCommand(rootCmd, ShellCompRequestCmd, "a", "b", "c", "--"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Persistent flags added to the ancestors afterwards must still be offered.
+	rootCmd.PersistentFlags().StringP("region", "r", "", "region")
+	aCmd.PersistentFlags().Bool("dry", false, "dry run")
+	_ = rootCmd.RegisterFlagCompletionFunc("region", FixedCompletions([]string{"eu", "us"}, ShellCompDirectiveNoFileComp))
+
+	output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "a", "b", "c", "--")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"--dry",
+		"--region",
+		"--help",
+		":4",
+		"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+
+	for _, args := range [][]string{
+		{"a", "b", "c", "-r", ""},
+		{"a", "b", "c", "--region", ""},
+		{"a", "b", "c", "--region="},
+		{"a", "--dry", "b", "c", "-r="},
+	} {
+		output, err := executeCommand(rootCmd, append([]string{ShellCompRequestCmd}, args...)...)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		expected := strings.Join([]string{
+			"eu",
+			"us",
+			":4",
+			"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+		if output != expected {
+			t.Errorf("args %q: expected: %q, got: %q", args, expected, output)
+		}
+	}
+}
+
 // This test tries to register flag completion concurrently to make sure the
 // code handles concurrency properly.
 // This was reported as a problem when tests are run concurrently:
@@ -2151,6 +2207,74 @@ func TestFlagCompletionConcurrentRegistration(t *testing.T) {
 	}
 }
 
+// This test registers and looks up flag completion functions on many commands
+// concurrently, including from a completion function, to make sure neither
+// races nor deadlocks occur.  It is most useful when run with -race.
+func TestFlagCompletionConcurrentRegistrationStress(t *testing.T) {
+	const (
+		numCmds  = 8
+		numFlags = 50
+	)
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	var cmds []*Command
+	for c := 0; c < numCmds; c++ {
+		cmd := &Command{Use: fmt.Sprintf("cmd%d", c), Run: emptyRun}
+		for f := 0; f < numFlags; f++ {
+			cmd.Flags().String(fmt.Sprintf("flag%d", f), "", "")
+		}
+		rootCmd.AddCommand(cmd)
+		cmds = append(cmds, cmd)
+	}
+	rootCmd.Flags().String("root", "", "")
+	rootCmd.Flags().String("late", "", "")
+
+	// The completion function of the root flag registers another completion function.
+	_ = rootCmd.RegisterFlagCompletionFunc("root", func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+		_ = cmd.RegisterFlagCompletionFunc("late", FixedCompletions([]string{"late"}, ShellCompDirectiveNoFileComp))
+		return []string{"root"}, ShellCompDirectiveNoFileComp
+	})
+
+	var wg sync.WaitGroup
+	for c, cmd := range cmds {
+		for f := 0; f < numFlags; f++ {
+			cmd, value := cmd, fmt.Sprintf("%d-%d", c, f)
+			flagName := fmt.Sprintf("flag%d", f)
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_ = cmd.RegisterFlagCompletionFunc(flagName, FixedCompletions([]string{value}, ShellCompDirectiveNoFileComp))
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = cmd.GetFlagCompletionFunc(flagName)
+			}()
+		}
+	}
+	wg.Wait()
+
+	if _, err := executeCommand(rootCmd, ShellCompRequestCmd, "--root", ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output, err := executeCommand(rootCmd, ShellCompRequestCmd, "--late", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "late\n:4\n")
+
+	for c, cmd := range cmds {
+		for f := 0; f < numFlags; f++ {
+			completionFn, exists := cmd.GetFlagCompletionFunc(fmt.Sprintf("flag%d", f))
+			if !exists {
+				t.Fatalf("No completion function for flag%d of %s", f, cmd.Name())
+			}
+			comps, _ := completionFn(cmd, nil, "")
+			if expected := fmt.Sprintf("%d-%d", c, f); len(comps) != 1 || comps[0] != expected {
+				t.Fatalf("Expected completion %q, got %v", expected, comps)
+			}
+		}
+	}
+}
+
 func TestFlagCompletionInGoWithDesc(t *testing.T) {
 	rootCmd := &Command{
 		Use: "root",
@@ -2611,6 +2735,53 @@ func TestCompleteCompletion(t *testing.T) {
 	}
 }
 
+func TestCompletionCmdDetectShell(t *testing.T) {
+	defer func(orig func() string) { parentProcessName = orig }(parentProcessName)
+
+	newRootCmd := func() *Command {
+		rootCmd := &Command{Use: "root", Args: NoArgs, Run: emptyRun}
+		rootCmd.AddCommand(&Command{Use: "sub", Run: emptyRun})
+		return rootCmd
+	}
+
+	testcases := []struct {
+		desc     string
+		parent   string
+		shellEnv string
+		args     []string
+		expected string
+	}{
+		{"parent process", "zsh", "/bin/bash", nil, "#compdef root"},
+		{"login shell parent process", "-bash", "", nil, "# bash completion V2 for root"},
+		{"SHELL environment variable", "make", "/usr/bin/fish", nil, "# fish completion for root"},
+		{"shell flag", "zsh", "/bin/zsh", []string{"--shell", "powershell"}, "# powershell completion for root"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.desc, func(t *testing.T) {
+			parentProcessName = func() string { return tc.parent }
+			t.Setenv("SHELL", tc.shellEnv)
+			output, err := executeCommand(newRootCmd(), append([]string{compCmdName}, tc.args...)...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			checkStringContains(t, output, tc.expected)
+		})
+	}
+
+	parentProcessName = func() string { return "" }
+	t.Setenv("SHELL", "")
+	_, err := executeCommand(newRootCmd(), compCmdName)
+	expected := `cannot detect your shell, run "root completion <shell>" or "root completion --shell <shell>" with one of: bash, fish, powershell, zsh`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	_, err = executeCommand(newRootCmd(), compCmdName, "--shell", "tcsh")
+	if err == nil || !strings.HasPrefix(err.Error(), `unsupported shell "tcsh"`) {
+		t.Errorf("expected an unsupported shell error, got %v", err)
+	}
+}
+
 func TestMultipleShorthandFlagCompletion(t *testing.T) {
 	rootCmd := &Command{
 		Use:       "root",
@@ -3709,3 +3880,225 @@ func TestDisableDescriptions(t *testing.T) {
 		})
 	}
 }
+
+func TestPerCommandCompletionOptions(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	secretCmd := &Command{
+		Use:               "secret",
+		Run:               emptyRun,
+		CompletionOptions: CompletionOptions{DisableCompletion: true},
+	}
+	secretChildCmd := &Command{Use: "child", ValidArgs: []string{"one", "two"}, Run: emptyRun}
+	secretCmd.AddCommand(secretChildCmd)
+	batchCmd := &Command{
+		Use:               "batch",
+		Run:               emptyRun,
+		CompletionOptions: CompletionOptions{NoFileCompletion: true},
+	}
+	batchChildCmd := &Command{Use: "child", Run: emptyRun}
+	batchChildCmd.Flags().String("input", "", "input")
+	batchCmd.AddCommand(batchChildCmd)
+	filesCmd := &Command{Use: "files", Run: emptyRun}
+	rootCmd.AddCommand(secretCmd, batchCmd, filesCmd)
+
+	testcases := []struct {
+		desc     string
+		args     []string
+		expected []string
+	}{
+		{
+			desc:     "disabled completion",
+			args:     []string{"secret", ""},
+			expected: []string{":4", "Completion ended with directive: ShellCompDirectiveNoFileComp"},
+		},
+		{
+			desc:     "disabled completion is inherited",
+			args:     []string{"secret", "child", ""},
+			expected: []string{":4", "Completion ended with directive: ShellCompDirectiveNoFileComp"},
+		},
+		{
+			desc:     "no file completion is inherited",
+			args:     []string{"batch", "child", ""},
+			expected: []string{":4", "Completion ended with directive: ShellCompDirectiveNoFileComp"},
+		},
+		{
+			desc:     "no file completion for flag values",
+			args:     []string{"batch", "child", "--input", ""},
+			expected: []string{":4", "Completion ended with directive: ShellCompDirectiveNoFileComp"},
+		},
+		{
+			desc:     "other commands are not affected",
+			args:     []string{"files", ""},
+			expected: []string{":0", "Completion ended with directive: ShellCompDirectiveDefault"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.desc, func(t *testing.T) {
+			output, err := executeCommand(rootCmd, append([]string{ShellCompNoDescRequestCmd}, tc.args...)...)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+
+			expected := strings.Join(append(tc.expected, ""), "\n")
+			if output != expected {
+				t.Errorf("expected: %q, got: %q", expected, output)
+			}
+		})
+	}
+}
+
+func TestCompletionFilter(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	getCmd := &Command{
+		Use: "get",
+		ValidArgsFunction: func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+			return []string{"pod", "_internal", "service"}, ShellCompDirectiveDefault
+		},
+		Run: emptyRun,
+	}
+	rootCmd.AddCommand(getCmd)
+
+	// The filter set on the root applies to the completions of its children.
+	rootCmd.SetCompletionFilter(func(cmd *Command, completions []string, directive ShellCompDirective) ([]string, ShellCompDirective) {
+		var filtered []string
+		for _, comp := range completions {
+			if !strings.HasPrefix(comp, "_") {
+				filtered = append(filtered, comp)
+			}
+		}
+		return filtered, directive | ShellCompDirectiveNoFileComp
+	})
+
+	output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "get", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"pod",
+		"service",
+		":4",
+		"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
+
+func TestCompletionTimeout(t *testing.T) {
+	var deadline bool
+	rootCmd := &Command{
+		Use:               "root",
+		CompletionOptions: CompletionOptions{Timeout: time.Minute},
+		Run:               emptyRun,
+	}
+	childCmd := &Command{
+		Use: "child",
+		ValidArgsFunction: CompletionWithContext(func(ctx context.Context, cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+			_, deadline = ctx.Deadline()
+			return []string{"one", "two"}, ShellCompDirectiveNoFileComp
+		}),
+		Run: emptyRun,
+	}
+	childCmd.Flags().String("server", "", "")
+	assertNoErr(t, childCmd.RegisterFlagCompletionFunc("server", CompletionWithContext(
+		func(ctx context.Context, cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+			<-ctx.Done()
+			return nil, ShellCompDirectiveError
+		})))
+	rootCmd.AddCommand(childCmd)
+
+	output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "child", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "one\ntwo\n:4\n")
+	if !deadline {
+		t.Error("Expected the context of the completion function to have a deadline")
+	}
+
+	// The timeout of the closest command applies: the completion function returns once it expires.
+	childCmd.CompletionOptions.Timeout = 10 * time.Millisecond
+	output, err = executeCommand(rootCmd, ShellCompNoDescRequestCmd, "child", "--server", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, ":1\n")
+}
+
+func TestCompletionErrorRedirected(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	rootCmd.AddCommand(childCmd)
+
+	outBuf, errBuf := new(bytes.Buffer), new(bytes.Buffer)
+	rootCmd.SetOut(outBuf)
+	rootCmd.SetErr(errBuf)
+	rootCmd.SetArgs([]string{ShellCompNoDescRequestCmd, "child", "--unknown", ""})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checkStringContains(t, errBuf.String(), "[Debug] [Error] Error while parsing flags from args [--unknown]: unknown flag: --unknown\n")
+	checkStringOmits(t, outBuf.String(), "[Error]")
+}
+
+func TestMarkFlagCompletionCommand(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().String("namespace", "", "namespace")
+	assertNoErr(t, MarkFlagCompletionCommand(rootCmd, "namespace", "kubectl get ns -o name"))
+
+	output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "--namespace", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"kubectl get ns -o name",
+		":68",
+		"Completion ended with directive: ShellCompDirectiveNoFileComp, ShellCompDirectiveShellCommand", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+
+	if err := MarkFlagCompletionCommand(rootCmd, "unknown", "true"); err == nil {
+		t.Error("Expected an error for an unknown flag")
+	}
+}
+
+func TestRegisterArgCompletionFunc(t *testing.T) {
+	rootCmd := &Command{
+		Use: "root",
+		ValidArgsFunction: func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+			return []string{"other"}, ShellCompDirectiveDefault
+		},
+		Run: emptyRun,
+	}
+	assertNoErr(t, rootCmd.RegisterArgCompletionFunc(0, FixedCompletions([]string{"repo"}, ShellCompDirectiveNoFileComp)))
+	assertNoErr(t, rootCmd.RegisterArgCompletionFunc(1, func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+		return []string{args[0] + "-main", args[0] + "-dev"}, ShellCompDirectiveNoFileComp
+	}))
+
+	testcases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{""}, []string{"repo", ":4"}},
+		{[]string{"cobra", ""}, []string{"cobra-main", "cobra-dev", ":4"}},
+		{[]string{"cobra", "main", ""}, []string{"other", ":0"}},
+	}
+	for _, tc := range testcases {
+		output, err := executeCommand(rootCmd, append([]string{ShellCompNoDescRequestCmd}, tc.args...)...)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		checkStringContains(t, output, strings.Join(tc.expected, "\n")+"\n")
+	}
+
+	if err := rootCmd.RegisterArgCompletionFunc(1, NoFileCompletions); err == nil {
+		t.Error("Expected an error registering an argument twice")
+	}
+	if err := rootCmd.RegisterArgCompletionFunc(-1, NoFileCompletions); err == nil {
+		t.Error("Expected an error registering a negative index")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package completiontest helps testing the shell completion of programs: it
+// requests the completions of a command line as the completion scripts do.
+package completiontest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// RequestCompletions returns the completions of the last word of line, as typed
+// by the user, e.g. "prog get pods --namespace ", and the directive returned with
+// them.  The first word of line, the name of the program, is ignored and a line
+// ending with a space completes a new, empty, word.  Words are separated by spaces;
+// quotes are not interpreted.
+//
+// The completions are requested by executing root with the hidden __complete
+// command, whose output is set to a buffer.  They include their description,
+// following a tab character, and the ActiveHelp messages.
+func RequestCompletions(root *cobra.Command, line string) ([]string, cobra.ShellCompDirective, error) {
+	words := strings.Fields(line)
+	if len(words) > 0 {
+		words = words[1:]
+	}
+	if line == "" || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+
+	var out bytes.Buffer
+	root.SetArgs(append([]string{cobra.ShellCompRequestCmd}, words...))
+	root.SetOut(&out)
+	root.SetErr(io.Discard)
+	if err := root.Execute(); err != nil {
+		return nil, cobra.ShellCompDirectiveError, err
+	}
+	return parseCompletions(out.String())
+}
+
+// parseCompletions parses the output of the __complete command: one completion
+// per line, followed by the directive, as in ":4".
+func parseCompletions(out string) ([]string, cobra.ShellCompDirective, error) {
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, ":") {
+		return nil, cobra.ShellCompDirectiveError, fmt.Errorf("missing completion directive in %q", out)
+	}
+	directive, err := strconv.Atoi(last[1:])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError, fmt.Errorf("invalid completion directive %q", last)
+	}
+	return lines[:len(lines)-1], cobra.ShellCompDirective(directive), nil
+}
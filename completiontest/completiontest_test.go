@@ -0,0 +1,66 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package completiontest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newRoot() *cobra.Command {
+	root := &cobra.Command{Use: "prog"}
+	get := &cobra.Command{
+		Use:       "get",
+		Short:     "Get a resource",
+		ValidArgs: []string{"pods\tThe pods", "services"},
+		Run:       func(*cobra.Command, []string) {},
+	}
+	get.Flags().String("namespace", "", "namespace")
+	_ = get.RegisterFlagCompletionFunc("namespace", cobra.FixedCompletions([]string{"default", "kube-system"}, cobra.ShellCompDirectiveNoFileComp))
+	root.AddCommand(get, &cobra.Command{Use: "gather", Run: func(*cobra.Command, []string) {}})
+	return root
+}
+
+func TestRequestCompletions(t *testing.T) {
+	testcases := []struct {
+		line        string
+		completions []string
+		directive   cobra.ShellCompDirective
+	}{
+		{"prog g", []string{"gather", "get\tGet a resource"}, cobra.ShellCompDirectiveNoFileComp},
+		{"prog get ", []string{"pods\tThe pods", "services"}, cobra.ShellCompDirectiveNoFileComp},
+		{"prog get pods --namespace ", []string{"default", "kube-system"}, cobra.ShellCompDirectiveNoFileComp},
+	}
+	for _, tc := range testcases {
+		completions, directive, err := RequestCompletions(newRoot(), tc.line)
+		if err != nil {
+			t.Fatalf("%q: Unexpected error: %v", tc.line, err)
+		}
+		if !reflect.DeepEqual(completions, tc.completions) {
+			t.Errorf("%q: expected completions %q, got %q", tc.line, tc.completions, completions)
+		}
+		if directive != tc.directive {
+			t.Errorf("%q: expected directive %d, got %d", tc.line, tc.directive, directive)
+		}
+	}
+}
+
+func TestParseCompletionsWithoutDirective(t *testing.T) {
+	if _, _, err := parseCompletions("one\ntwo\n"); err == nil {
+		t.Error("Expected an error without a directive")
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigDecoder decodes the content of a configuration file into v.
+type ConfigDecoder func(data []byte, v interface{}) error
+
+// Config describes the configuration file from which the flags which are not set
+// on the command-line nor from their environment variable take their value.
+// The precedence is: command-line, environment variable, configuration file, default value.
+//
+// The configuration file holds an object whose keys are flag names, e.g. in YAML:
+//
+//	region: eu-west-1
+//	tags: [a, b]
+//
+// Keys which do not match a flag of the executed command are ignored.
+type Config struct {
+	// FlagName is the name of the persistent flag giving the path of the configuration
+	// file.  It defaults to "config".
+	FlagName string
+	// Paths are the files looked up, in order, when the flag is not set.
+	// Missing files are ignored.
+	Paths []string
+	// Decoders are the decoders to use, by file extension (e.g. ".toml"), in
+	// addition to the built-in JSON (".json") and YAML (".yaml", ".yml") ones.
+	Decoders map[string]ConfigDecoder
+	// Transform, if set, is called with the decoded configuration before it is
+	// applied to the flags of cmd, and may modify it.
+	Transform func(cmd *Command, values map[string]interface{}) error
+}
+
+// defaultConfigDecoders are the decoders available to all configurations.
+var defaultConfigDecoders = map[string]ConfigDecoder{
+	".json": decodeJSON,
+	".yaml": yaml.Unmarshal,
+	".yml":  yaml.Unmarshal,
+}
+
+// decodeJSON decodes JSON keeping numbers as written, so that large integers are
+// not turned into floats.
+func decodeJSON(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+// SetConfig makes the flags of c and its sub-commands take their value from the
+// configuration file described by cfg, and adds the persistent flag giving its path to c.
+func (c *Command) SetConfig(cfg *Config) {
+	c.configuration = cfg
+	name := cfg.flagName()
+	if c.PersistentFlags().Lookup(name) == nil {
+		c.PersistentFlags().String(name, "", "configuration file")
+		_ = c.MarkPersistentFlagFilename(name)
+	}
+}
+
+// Config returns the configuration set on the command or its closest parent, if any.
+func (c *Command) Config() *Config {
+	for p := c; p != nil; p = p.Parent() {
+		if p.configuration != nil {
+			return p.configuration
+		}
+	}
+	return nil
+}
+
+// ConfigFileUsed returns the path of the configuration file applied to the flags
+// of the command when it was executed, if any.
+func (c *Command) ConfigFileUsed() string {
+	return c.configFileUsed
+}
+
+func (cfg *Config) flagName() string {
+	if cfg.FlagName == "" {
+		return "config"
+	}
+	return cfg.FlagName
+}
+
+func (cfg *Config) decoder(path string) (ConfigDecoder, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if decoder, ok := cfg.Decoders[ext]; ok {
+		return decoder, nil
+	}
+	if decoder, ok := defaultConfigDecoders[ext]; ok {
+		return decoder, nil
+	}
+	return nil, fmt.Errorf("unsupported configuration file format %q", ext)
+}
+
+// load returns the path and the content of the configuration file to use for cmd,
+// or an empty path if there is none.
+func (cfg *Config) load(cmd *Command) (string, map[string]interface{}, error) {
+	path, _ := cmd.Flags().GetString(cfg.flagName())
+	explicit := path != ""
+
+	var data []byte
+	var err error
+	if explicit {
+		data, err = os.ReadFile(path)
+	} else {
+		err = os.ErrNotExist
+		for _, p := range cfg.Paths {
+			if data, err = os.ReadFile(p); !errors.Is(err, os.ErrNotExist) {
+				path = p
+				break
+			}
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil, nil
+		}
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot read configuration file: %w", err)
+	}
+
+	decoder, err := cfg.decoder(path)
+	if err != nil {
+		return "", nil, err
+	}
+	values := map[string]interface{}{}
+	if err := decoder(data, &values); err != nil {
+		return "", nil, fmt.Errorf("cannot decode configuration file %s: %w", path, err)
+	}
+	if cfg.Transform != nil {
+		if err := cfg.Transform(cmd, values); err != nil {
+			return "", nil, err
+		}
+	}
+	return path, values, nil
+}
+
+// applyConfigFlags sets the flags which were set neither on the command-line nor
+// from their environment variable from the configuration file, if any.
+func (c *Command) applyConfigFlags() error {
+	c.configFileUsed = ""
+	cfg := c.Config()
+	if cfg == nil {
+		return nil
+	}
+	path, values, err := cfg.load(c)
+	if err != nil || path == "" {
+		return err
+	}
+	c.configFileUsed = path
+
+	// The keys of the file are matched with the flags through the normalization
+	// function, e.g. "my_flag" may set --my-flag.
+	normalized := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		normalized[NormalizeName(c, key)] = value
+	}
+	c.Flags().VisitAll(func(f *flag.Flag) {
+		value, ok := normalized[NormalizeName(c, f.Name)]
+		if err != nil || !ok || f.Changed || f.Name == cfg.flagName() {
+			return
+		}
+		var s string
+		if s, err = configValueString(value); err != nil {
+			err = fmt.Errorf("invalid value for flag %q in configuration file %s: %w", f.Name, path, err)
+			return
+		}
+		if setErr := setFlagValue(f, s); setErr != nil {
+			err = fmt.Errorf("invalid value %q for flag %q in configuration file %s: %w", s, f.Name, path, setErr)
+			return
+		}
+		c.setFlagSource(f, FlagSourceConfig)
+	})
+	return err
+}
+
+// configValueString formats a decoded configuration value as a flag value:
+// lists are formatted as comma-separated values.
+func configValueString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			s, err := configValueString(e)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, s)
+		}
+		var b strings.Builder
+		w := csv.NewWriter(&b)
+		if err := w.Write(values); err != nil {
+			return "", err
+		}
+		w.Flush()
+		return strings.TrimSuffix(b.String(), "\n"), w.Error()
+	case map[string]interface{}:
+		return "", fmt.Errorf("objects are not supported")
+	case nil:
+		return "", nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
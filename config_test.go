@@ -0,0 +1,215 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	flag "github.com/spf13/pflag"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	yamlPath := writeConfigFile(t, "app.yaml", "region: config\nretries: 3\ntags: [a, b]\n")
+
+	tests := []struct {
+		env            string
+		args           []string
+		expectedRegion string
+		expectedSource FlagSource
+	}{
+		{"", []string{}, "config", FlagSourceConfig},
+		{"env", []string{}, "env", FlagSourceEnv},
+		{"env", []string{"--region", "cli"}, "cli", FlagSourceCommandLine},
+	}
+	for _, tc := range tests {
+		var region string
+		var retries int
+		var tags []string
+		var source FlagSource
+		rootCmd := &Command{Use: "root"}
+		rootCmd.SetConfig(&Config{Paths: []string{filepath.Join(t.TempDir(), "missing.json"), yamlPath}})
+		childCmd := &Command{Use: "child", Run: func(cmd *Command, _ []string) { source = cmd.FlagSource("region") }}
+		childCmd.Flags().StringVar(&region, "region", "default", "")
+		childCmd.Flags().IntVar(&retries, "retries", 1, "")
+		childCmd.Flags().StringSliceVar(&tags, "tags", nil, "")
+		rootCmd.AddCommand(childCmd)
+		_ = BindEnv(childCmd, "region", "APP_REGION")
+
+		t.Setenv("APP_REGION", tc.env)
+		if _, err := executeCommand(rootCmd, append([]string{"child"}, tc.args...)...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if region != tc.expectedRegion || source != tc.expectedSource {
+			t.Errorf("env %q, args %v: expected %q from %s, got %q from %s", tc.env, tc.args, tc.expectedRegion, tc.expectedSource, region, source)
+		}
+		if retries != 3 || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+			t.Errorf("Expected retries 3 and tags [a b], got %d and %v", retries, tags)
+		}
+		if childCmd.ConfigFileUsed() != yamlPath {
+			t.Errorf("Expected config file %q, got %q", yamlPath, childCmd.ConfigFileUsed())
+		}
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	jsonPath := writeConfigFile(t, "app.json", `{"region": "json"}`)
+
+	var region string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().StringVar(&region, "region", "default", "")
+	rootCmd.SetConfig(&Config{})
+
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if region != "default" || rootCmd.ConfigFileUsed() != "" {
+		t.Errorf("Expected no config file to be used, got %q and region %q", rootCmd.ConfigFileUsed(), region)
+	}
+
+	if _, err := executeCommand(rootCmd, "--config", jsonPath); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if region != "json" {
+		t.Errorf("Expected region %q, got %q", "json", region)
+	}
+
+	_, err := executeCommand(rootCmd, "--config", filepath.Join(t.TempDir(), "missing.json"))
+	if err == nil || !strings.Contains(err.Error(), "cannot read configuration file") {
+		t.Errorf("Expected an error for a missing config file, got %v", err)
+	}
+}
+
+func TestConfigDecodersAndTransform(t *testing.T) {
+	path := writeConfigFile(t, "app.ini", "region=ini\n")
+
+	var region string
+	var count int
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().StringVar(&region, "region", "default", "")
+	rootCmd.Flags().IntVar(&count, "count", 0, "")
+	rootCmd.SetConfig(&Config{
+		FlagName: "settings",
+		Decoders: map[string]ConfigDecoder{
+			".ini": func(data []byte, v interface{}) error {
+				values := *v.(*map[string]interface{})
+				for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+					kv := strings.SplitN(line, "=", 2)
+					values[kv[0]] = kv[1]
+				}
+				return nil
+			},
+		},
+		Transform: func(_ *Command, values map[string]interface{}) error {
+			values["count"] = 42
+			return nil
+		},
+	})
+
+	if _, err := executeCommand(rootCmd, "--settings", path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if region != "ini" || count != 42 {
+		t.Errorf("Expected region %q and count 42, got %q and %d", "ini", region, count)
+	}
+
+	_, err := executeCommand(rootCmd, "--settings", writeConfigFile(t, "app.toml", ""))
+	if err == nil || !strings.Contains(err.Error(), `unsupported configuration file format ".toml"`) {
+		t.Errorf("Expected an error for an unsupported format, got %v", err)
+	}
+}
+
+func TestConfigInvalidValue(t *testing.T) {
+	path := writeConfigFile(t, "app.yaml", "count: many\n")
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().Int("count", 0, "")
+	rootCmd.SetConfig(&Config{})
+
+	_, err := executeCommand(rootCmd, "--config", path)
+	expected := `invalid value "many" for flag "count" in configuration file ` + path
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected error containing %q, got %v", expected, err)
+	}
+}
+
+func TestConfigLargeNumbers(t *testing.T) {
+	var size int
+	var ratio float64
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().IntVar(&size, "size", 0, "")
+	rootCmd.Flags().Float64Var(&ratio, "ratio", 0, "")
+	rootCmd.SetConfig(&Config{})
+
+	for _, path := range []string{
+		writeConfigFile(t, "app.json", `{"size": 1000000, "ratio": 0.000001}`),
+		writeConfigFile(t, "app.ini", "size=1000000\nratio=0.000001\n"),
+	} {
+		size, ratio = 0, 0
+		rootCmd.configuration.Decoders = map[string]ConfigDecoder{
+			// Decodes all the values as floats, like JSON decoders usually do.
+			".ini": func(data []byte, v interface{}) error {
+				values := *v.(*map[string]interface{})
+				for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+					kv := strings.SplitN(line, "=", 2)
+					f, err := strconv.ParseFloat(kv[1], 64)
+					if err != nil {
+						return err
+					}
+					values[kv[0]] = f
+				}
+				return nil
+			},
+		}
+		if _, err := executeCommand(rootCmd, "--config", path); err != nil {
+			t.Fatalf("Unexpected error for %s: %v", filepath.Base(path), err)
+		}
+		if size != 1000000 || ratio != 0.000001 {
+			t.Errorf("Expected size 1000000 and ratio 0.000001 from %s, got %d and %v", filepath.Base(path), size, ratio)
+		}
+	}
+}
+
+func TestConfigNormalizedNames(t *testing.T) {
+	path := writeConfigFile(t, "app.yaml", "log_level: debug\n")
+
+	var logLevel string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().StringVar(&logLevel, "log-level", "info", "")
+	rootCmd.SetGlobalNormalizationFunc(func(f *flag.FlagSet, name string) flag.NormalizedName {
+		return flag.NormalizedName(strings.ReplaceAll(name, "_", "-"))
+	})
+	rootCmd.SetConfig(&Config{Paths: []string{path}})
+
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if logLevel != "debug" {
+		t.Errorf("Expected the key to match the flag through the normalization function, got %q", logLevel)
+	}
+}
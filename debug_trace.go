@@ -0,0 +1,172 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+const (
+	debugFlagName = "boot-debug"
+	// DebugTime reports the time spent executing the command.
+	DebugTime = "time"
+	// DebugMem reports the allocations and garbage collections which occurred
+	// while executing the command, as well as the memory obtained from the OS.
+	DebugMem = "mem"
+	// DebugFlags reports common mistakes in the definition of flags: local flags of
+	// a parent used with a sub-command, which should be persistent, flags shadowing
+	// a persistent flag of a parent with a different type or default value and flags
+	// defined while the command is running.
+	DebugFlags = "flags"
+)
+
+// InitDefaultDebugFlag adds the hidden '--boot-debug' persistent flag to c.
+// It accepts a comma-separated list of DebugTime, DebugMem and DebugFlags and prints
+// the corresponding statistics and warnings about the execution of the command to
+// its error output.
+// It is called automatically by executing the c if ExecOptions.DebugFlag is set.
+// If c already has a 'boot-debug' flag, it will do nothing.
+func (c *Command) InitDefaultDebugFlag() {
+	if c.PersistentFlags().Lookup(debugFlagName) != nil {
+		return
+	}
+	c.PersistentFlags().StringSlice(debugFlagName, nil,
+		fmt.Sprintf("print debug statistics about the execution of the command (%s, %s, %s)", DebugTime, DebugMem, DebugFlags))
+	_ = c.PersistentFlags().MarkHidden(debugFlagName)
+	_ = c.PersistentFlags().SetAnnotation(debugFlagName, FlagSetByCobraAnnotation, []string{"true"})
+	_ = c.RegisterFlagCompletionFunc(debugFlagName, FixedCompletions([]string{DebugTime, DebugMem, DebugFlags}, ShellCompDirectiveNoFileComp))
+}
+
+// debugModes returns the modes requested through the '--boot-debug' flag.
+func (c *Command) debugModes() []string {
+	f := c.Flags().Lookup(debugFlagName)
+	if f == nil || !f.Changed {
+		return nil
+	}
+	modes, err := c.Flags().GetStringSlice(debugFlagName)
+	if err != nil {
+		return nil
+	}
+	return modes
+}
+
+// startDebugTrace starts recording the statistics requested through the
+// '--boot-debug' flag and returns a function printing them.
+func (c *Command) startDebugTrace() func() {
+	modes := c.debugModes()
+	if len(modes) == 0 {
+		return func() {}
+	}
+	traceTime := stringInSlice(DebugTime, modes)
+	traceMem := stringInSlice(DebugMem, modes)
+	traceFlags := stringInSlice(DebugFlags, modes)
+
+	var defined map[*flag.Flag]bool
+	if traceFlags {
+		c.warnShadowedFlags()
+		defined = c.definedFlags()
+	}
+	var before runtime.MemStats
+	if traceMem {
+		runtime.ReadMemStats(&before)
+	}
+	start := time.Now()
+
+	return func() {
+		if traceTime {
+			c.PrintErrf("[debug] %s: time=%s\n", c.CommandPath(), time.Since(start))
+		}
+		if traceMem {
+			var after runtime.MemStats
+			runtime.ReadMemStats(&after)
+			c.PrintErrf("[debug] %s: mem alloc=%d total_alloc=%d mallocs=%d frees=%d heap_inuse=%d sys=%d num_gc=%d gc_pause=%s\n",
+				c.CommandPath(),
+				after.HeapAlloc,
+				after.TotalAlloc-before.TotalAlloc,
+				after.Mallocs-before.Mallocs,
+				after.Frees-before.Frees,
+				after.HeapInuse,
+				after.Sys,
+				after.NumGC-before.NumGC,
+				time.Duration(after.PauseTotalNs-before.PauseTotalNs))
+		}
+		if traceFlags {
+			for f := range c.definedFlags() {
+				if !defined[f] {
+					c.debugWarnf("flag --%s was defined while the command was running, define it before executing the command", f.Name)
+				}
+			}
+		}
+	}
+}
+
+// debugWarnf prints a warning of the DebugFlags mode.
+func (c *Command) debugWarnf(format string, a ...interface{}) {
+	c.PrintErrf("[debug] %s: warning: %s\n", c.CommandPath(), fmt.Sprintf(format, a...))
+}
+
+// definedFlags returns the flags currently defined for c: its own and the
+// persistent flags of its parents.
+func (c *Command) definedFlags() map[*flag.Flag]bool {
+	flags := map[*flag.Flag]bool{}
+	add := func(f *flag.Flag) { flags[f] = true }
+	c.Flags().VisitAll(add)
+	for p := c; p != nil; p = p.parent {
+		p.PersistentFlags().VisitAll(add)
+	}
+	return flags
+}
+
+// warnShadowedFlags warns about the flags of c and its parents which shadow a
+// persistent flag of one of their own parents, see ValidateFlagDefinitions.
+func (c *Command) warnShadowedFlags() {
+	for x := c; x != nil; x = x.parent {
+		for _, shadow := range x.flagShadows() {
+			c.debugWarnf("%s", shadow)
+		}
+	}
+}
+
+// warnParentLocalFlags warns about the flags of args which are unknown to c
+// but are local flags of one of its parents, and should be made persistent for
+// c to inherit them.  It is called when parsing the flags of c failed.
+func (c *Command) warnParentLocalFlags(args []string) {
+	if !stringInSlice(DebugFlags, c.debugModes()) {
+		return
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			return
+		}
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		name := strings.SplitN(arg[2:], "=", 2)[0]
+		if c.Flags().Lookup(name) != nil {
+			continue
+		}
+		for p := c.parent; p != nil; p = p.parent {
+			if p.LocalNonPersistentFlags().Lookup(name) != nil {
+				c.debugWarnf("flag --%s is a local flag of %q, make it persistent for its sub-commands to inherit it", name, p.CommandPath())
+				break
+			}
+		}
+	}
+}
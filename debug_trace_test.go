@@ -0,0 +1,88 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"testing"
+)
+
+func TestDebugFlag(t *testing.T) {
+	getCmd := func() *Command {
+		rootCmd := &Command{Use: "root", Run: emptyRun}
+		rootCmd.SetExecOptions(ExecOptions{DebugFlag: true})
+		childCmd := &Command{Use: "child", Run: func(*Command, []string) { _ = make([]byte, 1<<20) }}
+		rootCmd.AddCommand(childCmd)
+		return rootCmd
+	}
+
+	output, err := executeCommand(getCmd(), "child", "--boot-debug=mem,time")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "[debug] root child: time=")
+	checkStringContains(t, output, "[debug] root child: mem alloc=")
+	checkStringContains(t, output, "num_gc=")
+
+	output, err = executeCommand(getCmd(), "child", "--boot-debug", "time")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "[debug] root child: time=")
+	checkStringOmits(t, output, "mem alloc=")
+
+	output, err = executeCommand(getCmd(), "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringOmits(t, output, "boot-debug")
+}
+
+func TestDebugFlagDisabled(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+
+	_, err := executeCommand(rootCmd, "--boot-debug=mem")
+	if err == nil {
+		t.Errorf("Expected an unknown flag error")
+	}
+}
+
+func TestDebugFlagsMode(t *testing.T) {
+	getCmd := func() *Command {
+		rootCmd := &Command{Use: "root", Run: emptyRun}
+		rootCmd.SetExecOptions(ExecOptions{DebugFlag: true})
+		rootCmd.Flags().String("local", "", "")
+		rootCmd.PersistentFlags().String("region", "", "")
+		childCmd := &Command{Use: "child", Run: func(cmd *Command, _ []string) { cmd.Flags().Bool("late", false, "") }}
+		childCmd.Flags().String("region", "us", "")
+		rootCmd.AddCommand(childCmd)
+		return rootCmd
+	}
+
+	output, err := executeCommand(getCmd(), "child", "--boot-debug=flags")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, `[debug] root child: warning: flag --region of "root child" shadows the persistent flag of "root" (default "us" instead of "")`)
+	checkStringContains(t, output, "[debug] root child: warning: flag --late was defined while the command was running")
+
+	output, err = executeCommand(getCmd(), "child", "--boot-debug=flags", "--local=value")
+	if err == nil {
+		t.Error("Expected an unknown flag error")
+	}
+	checkStringContains(t, output, `[debug] root child: warning: flag --local is a local flag of "root", make it persistent`)
+
+	output, _ = executeCommand(getCmd(), "child", "--local=value")
+	checkStringOmits(t, output, "[debug]")
+}
@@ -0,0 +1,86 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// SetDefaultCommand sets the sub-command, by name or alias, which is executed when
+// c, which must not be runnable itself, is executed without a sub-command nor
+// arguments, instead of printing the help of c.  The flags are passed to the
+// default command, unless one of them is a local flag of c, such as --help or
+// --version, in which case c handles them.  Arguments which are not sub-commands
+// are still reported as unknown commands.
+func (c *Command) SetDefaultCommand(name string) {
+	c.defaultCommand = name
+}
+
+// DefaultCommand returns the sub-command set by SetDefaultCommand, or nil.
+func (c *Command) DefaultCommand() *Command {
+	if c.defaultCommand == "" {
+		return nil
+	}
+	return c.subCommand(c.defaultCommand, c.ExecOptions())
+}
+
+// IsDefaultCommand returns true if c is the default command of its parent.
+func (c *Command) IsDefaultCommand() bool {
+	return c.HasParent() && c.parent.DefaultCommand() == c
+}
+
+// defaultCommandFor returns the default command to execute instead of c with
+// args, or nil if c is to be executed.
+func (c *Command) defaultCommandFor(args []string) *Command {
+	if c.Runnable() || c.DisableFlagParsing {
+		return nil
+	}
+	def := c.DefaultCommand()
+	if def == nil {
+		return nil
+	}
+	def = def.loadLazy()
+	if len(stripFlags(args, def)) > 0 {
+		return nil
+	}
+
+	c.InitDefaultHelpFlag()
+	c.InitDefaultVersionFlag()
+	local := c.LocalNonPersistentFlags()
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if isFlagOf(arg, local) {
+			return nil
+		}
+	}
+	def.commandCalledAs.name = def.Name()
+	return def
+}
+
+// isFlagOf returns true if arg is a flag, or starts with a shorthand flag, of fs.
+func isFlagOf(arg string, fs *flag.FlagSet) bool {
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		name := strings.SplitN(arg[2:], "=", 2)[0]
+		return fs.Lookup(name) != nil
+	case strings.HasPrefix(arg, "-") && len(arg) > 1:
+		return fs.ShorthandLookup(arg[1:2]) != nil
+	}
+	return false
+}
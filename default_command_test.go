@@ -0,0 +1,107 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newDefaultCommandTree(port *int, served *bool) *Command {
+	rootCmd := &Command{Use: "root", Version: "1.0"}
+	rootCmd.PersistentFlags().Bool("debug", false, "")
+	serveCmd := &Command{Use: "serve", Aliases: []string{"s"}, Short: "Start the server", Args: NoArgs,
+		Run: func(*Command, []string) { *served = true }}
+	serveCmd.Flags().IntVar(port, "port", 8080, "")
+	rootCmd.AddCommand(serveCmd, &Command{Use: "status", Short: "Show the status", Run: emptyRun})
+	rootCmd.SetDefaultCommand("s")
+	return rootCmd
+}
+
+func TestDefaultCommand(t *testing.T) {
+	var port int
+	var served bool
+	rootCmd := newDefaultCommandTree(&port, &served)
+
+	if _, err := executeCommand(rootCmd, "--port", "9090", "--debug"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !served || port != 9090 {
+		t.Errorf("Expected the default command to run with the flags, got served %v, port %d", served, port)
+	}
+
+	served = false
+	if output, err := executeCommand(newDefaultCommandTree(&port, &served)); err != nil || output != "" {
+		t.Errorf("Unexpected output %q or error: %v", output, err)
+	}
+	if !served {
+		t.Errorf("Expected the default command to run without arguments")
+	}
+
+	serveCmd, _, _ := rootCmd.Find([]string{"serve"})
+	statusCmd, _, _ := rootCmd.Find([]string{"status"})
+	if !serveCmd.IsDefaultCommand() || statusCmd.IsDefaultCommand() {
+		t.Errorf("Expected only serve to be the default command")
+	}
+}
+
+func TestDefaultCommandNotUsed(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"help", []string{"--help"}, "serve       Start the server (default)"},
+		{"version", []string{"--version"}, "root version 1.0"},
+		{"sub-command", []string{"status"}, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var port int
+			var served bool
+			output, err := executeCommand(newDefaultCommandTree(&port, &served), tc.args...)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if served {
+				t.Errorf("Expected the default command not to run")
+			}
+			checkStringContains(t, output, tc.expected)
+		})
+	}
+
+	var port int
+	var served bool
+	_, err := executeCommand(newDefaultCommandTree(&port, &served), "sevre")
+	var unknownErr *UnknownCommandError
+	if !errors.As(err, &unknownErr) || served {
+		t.Errorf("Expected an unknown command error, got %v", err)
+	}
+}
+
+func TestDefaultCommandOfRunnableCommand(t *testing.T) {
+	var ran []string
+	rootCmd := &Command{Use: "root", Run: func(*Command, []string) { ran = append(ran, "root") }}
+	rootCmd.AddCommand(&Command{Use: "serve", Run: func(*Command, []string) { ran = append(ran, "serve") }})
+	rootCmd.SetDefaultCommand("serve")
+
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if strings.Join(ran, " ") != "root" {
+		t.Errorf("Expected the runnable command to run, got %v", ran)
+	}
+}
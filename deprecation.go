@@ -0,0 +1,152 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeprecationPolicy controls when running a deprecated command stops printing
+// a warning and instead fails with a DeprecatedCommandError.
+// A policy set on a command applies to all its deprecated descendants which
+// do not define their own policy.
+type DeprecationPolicy struct {
+	// HardAfter is the version (e.g. "v2.0.0") starting from which deprecated commands
+	// can no longer be run.  It is compared to the Version of the command or of its
+	// closest parent defining one.
+	HardAfter string
+	// HardAfterDate is the date starting from which deprecated commands can no longer be run.
+	HardAfterDate time.Time
+}
+
+// IsZero returns true if the policy does not enforce anything.
+func (p DeprecationPolicy) IsZero() bool {
+	return p.HardAfter == "" && p.HardAfterDate.IsZero()
+}
+
+// DeprecatedCommandError is returned when executing a deprecated command
+// after the enforcement version or date of its DeprecationPolicy.
+type DeprecatedCommandError struct {
+	// Command is the path of the deprecated command.
+	Command string
+	// Message is the deprecation message of the command.
+	Message string
+	// Version is the version of the program, set if the error is due to DeprecationPolicy.HardAfter.
+	Version string
+	// Policy is the policy which was enforced.
+	Policy DeprecationPolicy
+}
+
+func (e *DeprecatedCommandError) Error() string {
+	return fmt.Sprintf("command %q is deprecated and can no longer be used, %s", e.Command, e.Message)
+}
+
+// timeNow is used to evaluate DeprecationPolicy.HardAfterDate; it is a variable for testing.
+var timeNow = time.Now
+
+// deprecationPolicy returns the DeprecationPolicy which applies to the command:
+// its own or the one of its closest parent defining one.
+func (c *Command) deprecationPolicy() DeprecationPolicy {
+	for p := c; p != nil; p = p.Parent() {
+		if !p.DeprecationPolicy.IsZero() {
+			return p.DeprecationPolicy
+		}
+	}
+	return DeprecationPolicy{}
+}
+
+// version returns the Version of the command or of its closest parent defining one.
+func (c *Command) version() string {
+	for p := c; p != nil; p = p.Parent() {
+		if p.Version != "" {
+			return p.Version
+		}
+	}
+	return ""
+}
+
+// checkDeprecationPolicy returns a DeprecatedCommandError if the command is deprecated
+// and the DeprecationPolicy which applies to it is enforced.
+func (c *Command) checkDeprecationPolicy() error {
+	if len(c.Deprecated) == 0 {
+		return nil
+	}
+	policy := c.deprecationPolicy()
+	if policy.IsZero() {
+		return nil
+	}
+
+	err := &DeprecatedCommandError{Command: c.CommandPath(), Message: c.Deprecated, Policy: policy}
+	if !policy.HardAfterDate.IsZero() && !timeNow().Before(policy.HardAfterDate) {
+		return err
+	}
+	if policy.HardAfter != "" {
+		version := c.version()
+		if cmp, ok := compareVersions(version, policy.HardAfter); ok && cmp >= 0 {
+			err.Version = version
+			return err
+		}
+	}
+	return nil
+}
+
+// compareVersions compares two versions of the form [v]MAJOR[.MINOR[.PATCH]],
+// ignoring any pre-release or build suffix.  It returns -1, 0 or 1 and
+// false if one of the versions cannot be parsed.
+func compareVersions(a, b string) (int, bool) {
+	va, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	vb, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return parts, false
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) > len(parts) {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
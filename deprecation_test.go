@@ -0,0 +1,80 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeprecationPolicy(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	testcases := []struct {
+		desc        string
+		version     string
+		policy      DeprecationPolicy
+		expectedErr bool
+	}{
+		{desc: "no policy", version: "v3.0.0"},
+		{desc: "version before", version: "v1.9.9", policy: DeprecationPolicy{HardAfter: "v2.0.0"}},
+		{desc: "version equal", version: "v2.0.0", policy: DeprecationPolicy{HardAfter: "v2.0.0"}, expectedErr: true},
+		{desc: "version after", version: "2.1", policy: DeprecationPolicy{HardAfter: "v2.0.0"}, expectedErr: true},
+		{desc: "pre-release suffix", version: "v2.0.0-rc1", policy: DeprecationPolicy{HardAfter: "v2"}, expectedErr: true},
+		{desc: "unparsable version", version: "dev", policy: DeprecationPolicy{HardAfter: "v2.0.0"}},
+		{desc: "no version", policy: DeprecationPolicy{HardAfter: "v2.0.0"}},
+		{desc: "date before", policy: DeprecationPolicy{HardAfterDate: now.Add(time.Hour)}},
+		{desc: "date after", policy: DeprecationPolicy{HardAfterDate: now.Add(-time.Hour)}, expectedErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rootCmd := &Command{Use: "root", Version: tc.version, DeprecationPolicy: tc.policy}
+			oldCmd := &Command{Use: "old", Deprecated: "use new instead", Run: emptyRun}
+			rootCmd.AddCommand(oldCmd)
+
+			output, err := executeCommand(rootCmd, "old")
+			if !tc.expectedErr {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				checkStringContains(t, output, `Command "old" is deprecated, use new instead`)
+				return
+			}
+
+			var deprecatedErr *DeprecatedCommandError
+			if !errors.As(err, &deprecatedErr) {
+				t.Fatalf("Expected a DeprecatedCommandError, got %v", err)
+			}
+			if deprecatedErr.Command != "root old" || deprecatedErr.Message != "use new instead" {
+				t.Errorf("Unexpected error content: %+v", deprecatedErr)
+			}
+			checkStringContains(t, output, `command "root old" is deprecated and can no longer be used, use new instead`)
+		})
+	}
+}
+
+func TestDeprecationPolicyOverride(t *testing.T) {
+	rootCmd := &Command{Use: "root", Version: "v2.0.0", DeprecationPolicy: DeprecationPolicy{HardAfter: "v1.0.0"}}
+	oldCmd := &Command{Use: "old", Deprecated: "use new instead", Run: emptyRun, DeprecationPolicy: DeprecationPolicy{HardAfter: "v3.0.0"}}
+	rootCmd.AddCommand(oldCmd)
+
+	if _, err := executeCommand(rootCmd, "old"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
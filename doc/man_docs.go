@@ -51,7 +51,7 @@ func GenManTreeFromOpts(cmd *cobra.Command, opts GenManTreeOptions) error {
 		header = &GenManHeader{}
 	}
 	for _, c := range cmd.Commands() {
-		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() || c.IsHiddenFromDocs() {
 			continue
 		}
 		if err := GenManTreeFromOpts(c, opts); err != nil {
@@ -76,7 +76,7 @@ func GenManTreeFromOpts(cmd *cobra.Command, opts GenManTreeOptions) error {
 	defer f.Close()
 
 	headerCopy := *header
-	return GenMan(cmd, &headerCopy, f)
+	return genManPage(cmd, &headerCopy, f, separator)
 }
 
 // GenManTreeOptions is the options for generating the man pages.
@@ -103,6 +103,12 @@ type GenManHeader struct {
 // GenMan will generate a man page for the given command and write it to
 // w. The header argument may be nil, however obviously w may not.
 func GenMan(cmd *cobra.Command, header *GenManHeader, w io.Writer) error {
+	return genManPage(cmd, header, w, "-")
+}
+
+// genManPage writes the man page of cmd to w, referring to the pages of other
+// commands by their path with spaces replaced by separator, as in their file name.
+func genManPage(cmd *cobra.Command, header *GenManHeader, w io.Writer, separator string) error {
 	if header == nil {
 		header = &GenManHeader{}
 	}
@@ -110,7 +116,7 @@ func GenMan(cmd *cobra.Command, header *GenManHeader, w io.Writer) error {
 		return err
 	}
 
-	b := genMan(cmd, header)
+	b := genMan(cmd, header, separator)
 	_, err := w.Write(md2man.Render(b))
 	return err
 }
@@ -154,6 +160,9 @@ func manPreamble(buf io.StringWriter, header *GenManHeader, cmd *cobra.Command,
 	cobra.WriteStringAndCheck(buf, fmt.Sprintf("**%s**\n\n", cmd.UseLine()))
 	cobra.WriteStringAndCheck(buf, "# DESCRIPTION\n")
 	cobra.WriteStringAndCheck(buf, description+"\n\n")
+	if cmd.IsExperimental() {
+		cobra.WriteStringAndCheck(buf, "**Warning:** this command is experimental: it may change or be removed in a future release.\n\n")
+	}
 }
 
 func manPrintFlags(buf io.StringWriter, flags *pflag.FlagSet) {
@@ -185,42 +194,67 @@ func manPrintFlags(buf io.StringWriter, flags *pflag.FlagSet) {
 }
 
 func manPrintOptions(buf io.StringWriter, command *cobra.Command) {
-	flags := command.NonInheritedFlags()
+	flags := envVarFlags(command.NonInheritedFlags())
 	if flags.HasAvailableFlags() {
 		cobra.WriteStringAndCheck(buf, "# OPTIONS\n")
 		manPrintFlags(buf, flags)
 		cobra.WriteStringAndCheck(buf, "\n")
 	}
-	flags = command.InheritedFlags()
+	flags = envVarFlags(command.InheritedFlags())
 	if flags.HasAvailableFlags() {
 		cobra.WriteStringAndCheck(buf, "# OPTIONS INHERITED FROM PARENT COMMANDS\n")
 		manPrintFlags(buf, flags)
 		cobra.WriteStringAndCheck(buf, "\n")
 	}
+	if aliases := deprecatedAliases(command); len(aliases) > 0 {
+		cobra.WriteStringAndCheck(buf, "# DEPRECATED ALIASES\n")
+		for _, alias := range aliases {
+			cobra.WriteStringAndCheck(buf, alias+"\n\n")
+		}
+	}
 }
 
-func genMan(cmd *cobra.Command, header *GenManHeader) []byte {
+func genMan(cmd *cobra.Command, header *GenManHeader, separator string) []byte {
 	cmd.InitDefaultHelpCmd()
 	cmd.InitDefaultHelpFlag()
 
 	// something like `rootcmd-subcmd1-subcmd2`
 	dashCommandName := strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+	pageName := func(c *cobra.Command) string {
+		return strings.ReplaceAll(c.CommandPath(), " ", separator)
+	}
 
 	buf := new(bytes.Buffer)
 
 	manPreamble(buf, header, cmd, dashCommandName)
 	manPrintOptions(buf, cmd)
-	if len(cmd.Example) > 0 {
+	if cmd.HasExample() {
 		buf.WriteString("# EXAMPLE\n")
-		buf.WriteString(fmt.Sprintf("```\n%s\n```\n", cmd.Example))
+		buf.WriteString(fmt.Sprintf("```\n%s\n```\n", cmd.ExamplesString()))
+	}
+	if len(cmd.DocsURL) > 0 {
+		buf.WriteString("# DOCUMENTATION\n")
+		buf.WriteString(fmt.Sprintf("<%s>\n", cmd.DocsURL))
+	}
+	if len(cmd.Groups()) > 0 {
+		buf.WriteString("# COMMAND GROUPS\n")
+		for _, group := range cmd.Groups() {
+			buf.WriteString("### " + groupName(group) + "\n")
+			if len(group.Description) > 0 {
+				buf.WriteString(group.Description + "\n\n")
+			}
+			refs := make([]string, 0)
+			for _, c := range groupedCommands(cmd, group) {
+				refs = append(refs, fmt.Sprintf("**%s(%s)**", pageName(c), header.Section))
+			}
+			buf.WriteString(strings.Join(refs, ", ") + "\n")
+		}
 	}
 	if hasSeeAlso(cmd) {
 		buf.WriteString("# SEE ALSO\n")
 		seealsos := make([]string, 0)
 		if cmd.HasParent() {
-			parentPath := cmd.Parent().CommandPath()
-			dashParentPath := strings.ReplaceAll(parentPath, " ", "-")
-			seealso := fmt.Sprintf("**%s(%s)**", dashParentPath, header.Section)
+			seealso := fmt.Sprintf("**%s(%s)**", pageName(cmd.Parent()), header.Section)
 			seealsos = append(seealsos, seealso)
 			cmd.VisitParents(func(c *cobra.Command) {
 				if c.DisableAutoGenTag {
@@ -231,10 +265,14 @@ func genMan(cmd *cobra.Command, header *GenManHeader) []byte {
 		children := cmd.Commands()
 		sort.Sort(byName(children))
 		for _, c := range children {
-			if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+			if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() || c.IsHiddenFromDocs() {
 				continue
 			}
-			seealso := fmt.Sprintf("**%s-%s(%s)**", dashCommandName, c.Name(), header.Section)
+			seealso := fmt.Sprintf("**%s(%s)**", pageName(c), header.Section)
+			seealsos = append(seealsos, seealso)
+		}
+		for _, related := range cmd.SeeAlsoCommands() {
+			seealso := fmt.Sprintf("**%s(%s)**", pageName(related), header.Section)
 			seealsos = append(seealsos, seealso)
 		}
 		buf.WriteString(strings.Join(seealsos, ", ") + "\n")
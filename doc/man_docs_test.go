@@ -187,6 +187,32 @@ func TestGenManTree(t *testing.T) {
 	}
 }
 
+func TestGenManTreeFromOptsSeeAlsoMatchesFileNames(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root", Run: emptyRun}
+	subCmd := &cobra.Command{Use: "sub", Run: emptyRun}
+	rootCmd.AddCommand(subCmd)
+	tmpdir := t.TempDir()
+
+	if err := GenManTreeFromOpts(rootCmd, GenManTreeOptions{Header: &GenManHeader{}, Path: tmpdir}); err != nil {
+		t.Fatalf("GenManTreeFromOpts failed: %s", err.Error())
+	}
+
+	root, err := os.ReadFile(filepath.Join(tmpdir, "root.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpdir, "root_sub.1")); err != nil {
+		t.Fatalf("Expected file 'root_sub.1' to exist")
+	}
+	checkStringContains(t, string(root), "\\fBroot_sub(1)\\fP")
+
+	sub, err := os.ReadFile(filepath.Join(tmpdir, "root_sub.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, string(sub), "\\fBroot(1)\\fP")
+}
+
 func assertLineFound(scanner *bufio.Scanner, expectedLine string) error {
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -233,3 +259,58 @@ func BenchmarkGenManToFile(b *testing.B) {
 		}
 	}
 }
+
+func TestGenManDocsURL(t *testing.T) {
+	cmd := &cobra.Command{Use: "root", Run: emptyRun, DocsURL: "https://example.com/docs/root"}
+
+	buf := new(bytes.Buffer)
+	if err := GenMan(cmd, &GenManHeader{}, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, ".SH DOCUMENTATION")
+	checkStringContains(t, output, "https://example.com/docs/root")
+}
+
+func TestGenManSeeAlsoCommands(t *testing.T) {
+	root := &cobra.Command{Use: "root", Run: emptyRun}
+	cfg := &cobra.Command{Use: "config", Run: emptyRun}
+	get := &cobra.Command{Use: "get", Run: emptyRun, SeeAlso: []string{"root config"}}
+	root.AddCommand(cfg, get)
+
+	buf := new(bytes.Buffer)
+	if err := GenMan(get, &GenManHeader{Section: "1"}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStringContains(t, buf.String(), `\fBroot(1)\fP, \fBroot-config(1)\fP`)
+}
+
+func TestGenManExperimental(t *testing.T) {
+	cmd := &cobra.Command{Use: "root", Run: emptyRun, Experimental: true}
+
+	buf := new(bytes.Buffer)
+	if err := GenMan(cmd, &GenManHeader{}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStringContains(t, buf.String(), "this command is experimental")
+}
+
+func TestGenManCommandGroups(t *testing.T) {
+	cmd := &cobra.Command{Use: "root", Run: emptyRun}
+	cmd.AddGroup(&cobra.Group{ID: "manage", Title: "Management Commands:", Description: "Commands to manage the resources."})
+	cmd.AddCommand(&cobra.Command{Use: "create", Short: "Create a resource", GroupID: "manage", Run: emptyRun})
+
+	buf := new(bytes.Buffer)
+	if err := GenMan(cmd, &GenManHeader{Section: "1"}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	checkStringContains(t, output, ".SH COMMAND GROUPS")
+	checkStringContains(t, output, ".SS Management Commands")
+	checkStringContains(t, output, "Commands to manage the resources.")
+	checkStringContains(t, output, "\\fBroot-create(1)\\fP")
+}
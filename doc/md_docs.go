@@ -30,7 +30,7 @@ import (
 const markdownExtension = ".md"
 
 func printOptions(buf *bytes.Buffer, cmd *cobra.Command, name string) error {
-	flags := cmd.NonInheritedFlags()
+	flags := envVarFlags(cmd.NonInheritedFlags())
 	flags.SetOutput(buf)
 	if flags.HasAvailableFlags() {
 		buf.WriteString("### Options\n\n```\n")
@@ -38,13 +38,19 @@ func printOptions(buf *bytes.Buffer, cmd *cobra.Command, name string) error {
 		buf.WriteString("```\n\n")
 	}
 
-	parentFlags := cmd.InheritedFlags()
+	parentFlags := envVarFlags(cmd.InheritedFlags())
 	parentFlags.SetOutput(buf)
 	if parentFlags.HasAvailableFlags() {
 		buf.WriteString("### Options inherited from parent commands\n\n```\n")
 		parentFlags.PrintDefaults()
 		buf.WriteString("```\n\n")
 	}
+
+	if aliases := deprecatedAliases(cmd); len(aliases) > 0 {
+		buf.WriteString("### Deprecated aliases\n\n```\n")
+		buf.WriteString(strings.Join(aliases, "\n") + "\n")
+		buf.WriteString("```\n\n")
+	}
 	return nil
 }
 
@@ -63,23 +69,46 @@ func GenMarkdownCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string)
 
 	buf.WriteString("## " + name + "\n\n")
 	buf.WriteString(cmd.Short + "\n\n")
+	if cmd.IsExperimental() {
+		buf.WriteString("> **Warning**\n> This command is experimental: it may change or be removed in a future release.\n\n")
+	}
 	if len(cmd.Long) > 0 {
 		buf.WriteString("### Synopsis\n\n")
 		buf.WriteString(cmd.Long + "\n\n")
 	}
 
+	if len(cmd.DocsURL) > 0 {
+		buf.WriteString(fmt.Sprintf("Docs: <%s>\n\n", cmd.DocsURL))
+	}
+
 	if cmd.Runnable() {
 		buf.WriteString(fmt.Sprintf("```\n%s\n```\n\n", cmd.UseLine()))
 	}
 
-	if len(cmd.Example) > 0 {
+	if cmd.HasExample() {
 		buf.WriteString("### Examples\n\n")
-		buf.WriteString(fmt.Sprintf("```\n%s\n```\n\n", cmd.Example))
+		buf.WriteString(fmt.Sprintf("```\n%s\n```\n\n", cmd.ExamplesString()))
 	}
 
 	if err := printOptions(buf, cmd, name); err != nil {
 		return err
 	}
+	if len(cmd.Groups()) > 0 {
+		buf.WriteString("### Command groups\n\n")
+		for _, group := range cmd.Groups() {
+			buf.WriteString("#### " + groupName(group) + "\n\n")
+			if len(group.Description) > 0 {
+				buf.WriteString(group.Description + "\n\n")
+			}
+			for _, child := range groupedCommands(cmd, group) {
+				cname := name + " " + child.Name()
+				link := cname + markdownExtension
+				link = strings.ReplaceAll(link, " ", "_")
+				buf.WriteString(fmt.Sprintf("* [%s](%s)\t - %s\n", cname, linkHandler(link), child.Short))
+			}
+			buf.WriteString("\n")
+		}
+	}
 	if hasSeeAlso(cmd) {
 		buf.WriteString("### SEE ALSO\n\n")
 		if cmd.HasParent() {
@@ -99,7 +128,7 @@ func GenMarkdownCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string)
 		sort.Sort(byName(children))
 
 		for _, child := range children {
-			if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() || child.IsHiddenFromDocs() {
 				continue
 			}
 			cname := name + " " + child.Name()
@@ -107,6 +136,13 @@ func GenMarkdownCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string)
 			link = strings.ReplaceAll(link, " ", "_")
 			buf.WriteString(fmt.Sprintf("* [%s](%s)\t - %s\n", cname, linkHandler(link), child.Short))
 		}
+
+		for _, related := range cmd.SeeAlsoCommands() {
+			rname := related.CommandPath()
+			link := rname + markdownExtension
+			link = strings.ReplaceAll(link, " ", "_")
+			buf.WriteString(fmt.Sprintf("* [%s](%s)\t - %s\n", rname, linkHandler(link), related.Short))
+		}
 		buf.WriteString("\n")
 	}
 	if !cmd.DisableAutoGenTag {
@@ -132,7 +168,7 @@ func GenMarkdownTree(cmd *cobra.Command, dir string) error {
 // with custom filePrepender and linkHandler.
 func GenMarkdownTreeCustom(cmd *cobra.Command, dir string, filePrepender, linkHandler func(string) string) error {
 	for _, c := range cmd.Commands() {
-		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() || c.IsHiddenFromDocs() {
 			continue
 		}
 		if err := GenMarkdownTreeCustom(c, dir, filePrepender, linkHandler); err != nil {
@@ -42,6 +42,24 @@ func TestGenMdDoc(t *testing.T) {
 	checkStringContains(t, output, "Options inherited from parent commands")
 }
 
+func TestGenMdDocEnvVar(t *testing.T) {
+	cmd := &cobra.Command{Use: "app", Run: emptyRun}
+	cmd.Flags().String("token", "", "API token")
+	if err := cobra.BindEnv(cmd, "token", "APP_TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(cmd, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStringContains(t, buf.String(), "API token [$APP_TOKEN]")
+	if usage := cmd.Flags().Lookup("token").Usage; usage != "API token" {
+		t.Errorf("expected the usage of the flag to be unchanged, got %q", usage)
+	}
+}
+
 func TestGenMdDocWithNoLongOrSynopsis(t *testing.T) {
 	// We generate on subcommand so we have both subcommands and parents.
 	buf := new(bytes.Buffer)
@@ -56,6 +74,21 @@ func TestGenMdDocWithNoLongOrSynopsis(t *testing.T) {
 	checkStringOmits(t, output, "### Synopsis")
 }
 
+func TestGenMdDocWithExamples(t *testing.T) {
+	cmd := &cobra.Command{
+		Use:      "get",
+		Examples: []cobra.Example{{Description: "Get everything", Command: "get --all"}},
+		Run:      emptyRun,
+	}
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(cmd, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, "### Examples\n\n```\n  # Get everything\n  get --all\n```\n")
+}
+
 func TestGenMdNoHiddenParents(t *testing.T) {
 	// We generate on subcommand so we have both subcommands and parents.
 	for _, name := range []string{"rootflag", "strtwo"} {
@@ -109,6 +142,84 @@ func TestGenMdTree(t *testing.T) {
 	}
 }
 
+func TestGenMdTreeHonorsCommandSettings(t *testing.T) {
+	rootCmd := &cobra.Command{
+		Use:               "kubectl-plugin",
+		Annotations:       map[string]string{cobra.CommandDisplayNameAnnotation: "kubectl plugin"},
+		DisableAutoGenTag: true,
+		Run:               emptyRun,
+	}
+	rootCmd.AddCommand(
+		&cobra.Command{Use: "visible", Short: "A visible command", Run: emptyRun},
+		&cobra.Command{Use: "secret", Short: "A hidden command", Hidden: true, Run: emptyRun},
+	)
+	tmpdir := t.TempDir()
+
+	if err := GenMarkdownTree(rootCmd, tmpdir); err != nil {
+		t.Fatalf("GenMarkdownTree failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpdir, "kubectl_plugin_secret.md")); !os.IsNotExist(err) {
+		t.Error("Expected no file for the hidden command")
+	}
+	for _, name := range []string{"kubectl_plugin.md", "kubectl_plugin_visible.md"} {
+		content, err := os.ReadFile(filepath.Join(tmpdir, name))
+		if err != nil {
+			t.Fatalf("Expected file %q to exist", name)
+		}
+		checkStringOmits(t, string(content), "Auto generated")
+		checkStringOmits(t, string(content), "A hidden command")
+	}
+}
+
+func TestGenMdTreeHiddenFromDocs(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root", DisableAutoGenTag: true, Run: emptyRun}
+	internal := &cobra.Command{Use: "internal", Short: "Internal commands", Run: emptyRun}
+	internal.SetHiddenFromDocs(true)
+	internal.AddCommand(&cobra.Command{Use: "debug", Run: emptyRun})
+	rootCmd.AddCommand(internal, &cobra.Command{Use: "visible", Run: emptyRun})
+	tmpdir := t.TempDir()
+
+	if err := GenMarkdownTree(rootCmd, tmpdir); err != nil {
+		t.Fatalf("GenMarkdownTree failed: %v", err)
+	}
+
+	for _, name := range []string{"root_internal.md", "root_internal_debug.md"} {
+		if _, err := os.Stat(filepath.Join(tmpdir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected no file %q for the command hidden from the docs", name)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(tmpdir, "root.md"))
+	if err != nil {
+		t.Fatalf("Expected file root.md to exist")
+	}
+	checkStringContains(t, string(content), "root visible")
+	checkStringOmits(t, string(content), "Internal commands")
+}
+
+func TestGenMdDeprecatedAliases(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root", Run: emptyRun}
+	showCmd := &cobra.Command{Use: "show", Run: emptyRun}
+	showCmd.Flags().String("output", "", "output format")
+	rootCmd.AddCommand(showCmd)
+	if err := cobra.RenameCommand(rootCmd, "view", "show"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cobra.RenameFlag(showCmd, "format", "output"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(showCmd, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, "### Deprecated aliases")
+	checkStringContains(t, output, "root view: deprecated alias of root show")
+	checkStringContains(t, output, "--format: deprecated alias of --output")
+}
+
 func BenchmarkGenMarkdownToFile(b *testing.B) {
 	file, err := ioutil.TempFile("", "")
 	if err != nil {
@@ -124,3 +235,52 @@ func BenchmarkGenMarkdownToFile(b *testing.B) {
 		}
 	}
 }
+
+func TestGenMdDocsURL(t *testing.T) {
+	cmd := &cobra.Command{Use: "root", Run: emptyRun, DocsURL: "https://example.com/docs/root"}
+
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(cmd, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStringContains(t, buf.String(), "Docs: <https://example.com/docs/root>")
+}
+
+func TestGenMdSeeAlso(t *testing.T) {
+	root := &cobra.Command{Use: "root", Run: emptyRun}
+	cfg := &cobra.Command{Use: "config", Short: "Manage the configuration", Run: emptyRun}
+	get := &cobra.Command{Use: "get", Run: emptyRun, SeeAlso: []string{"root config"}}
+	root.AddCommand(cfg, get)
+
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(get, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStringContains(t, buf.String(), "* [root config](root_config.md)\t - Manage the configuration\n")
+}
+
+func TestGenMdExperimental(t *testing.T) {
+	cmd := &cobra.Command{Use: "root", Run: emptyRun, Experimental: true}
+
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(cmd, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStringContains(t, buf.String(), "> **Warning**\n> This command is experimental")
+}
+
+func TestGenMdCommandGroups(t *testing.T) {
+	cmd := &cobra.Command{Use: "root", Run: emptyRun}
+	cmd.AddGroup(&cobra.Group{ID: "manage", Title: "Management Commands:", Description: "Commands to manage the resources."})
+	cmd.AddCommand(&cobra.Command{Use: "create", Short: "Create a resource", GroupID: "manage", Run: emptyRun})
+
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(cmd, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStringContains(t, buf.String(), "### Command groups\n\n#### Management Commands\n\nCommands to manage the resources.\n\n* [root create](root_create.md)\t - Create a resource\n")
+}
@@ -28,7 +28,7 @@ import (
 )
 
 func printOptionsReST(buf *bytes.Buffer, cmd *cobra.Command, name string) error {
-	flags := cmd.NonInheritedFlags()
+	flags := envVarFlags(cmd.NonInheritedFlags())
 	flags.SetOutput(buf)
 	if flags.HasAvailableFlags() {
 		buf.WriteString("Options\n")
@@ -37,7 +37,7 @@ func printOptionsReST(buf *bytes.Buffer, cmd *cobra.Command, name string) error
 		buf.WriteString("\n")
 	}
 
-	parentFlags := cmd.InheritedFlags()
+	parentFlags := envVarFlags(cmd.InheritedFlags())
 	parentFlags.SetOutput(buf)
 	if parentFlags.HasAvailableFlags() {
 		buf.WriteString("Options inherited from parent commands\n")
@@ -45,6 +45,15 @@ func printOptionsReST(buf *bytes.Buffer, cmd *cobra.Command, name string) error
 		parentFlags.PrintDefaults()
 		buf.WriteString("\n")
 	}
+
+	if aliases := deprecatedAliases(cmd); len(aliases) > 0 {
+		buf.WriteString("Deprecated aliases\n")
+		buf.WriteString("~~~~~~~~~~~~~~~~~~\n\n::\n\n")
+		for _, alias := range aliases {
+			buf.WriteString("  " + alias + "\n")
+		}
+		buf.WriteString("\n")
+	}
 	return nil
 }
 
@@ -77,6 +86,9 @@ func GenReSTCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string, str
 	buf.WriteString(name + "\n")
 	buf.WriteString(strings.Repeat("-", len(name)) + "\n\n")
 	buf.WriteString(short + "\n\n")
+	if cmd.IsExperimental() {
+		buf.WriteString(".. warning::\n\n   This command is experimental: it may change or be removed in a future release.\n\n")
+	}
 	buf.WriteString("Synopsis\n")
 	buf.WriteString("~~~~~~~~\n\n")
 	buf.WriteString("\n" + long + "\n\n")
@@ -85,15 +97,31 @@ func GenReSTCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string, str
 		buf.WriteString(fmt.Sprintf("::\n\n  %s\n\n", cmd.UseLine()))
 	}
 
-	if len(cmd.Example) > 0 {
+	if cmd.HasExample() {
 		buf.WriteString("Examples\n")
 		buf.WriteString("~~~~~~~~\n\n")
-		buf.WriteString(fmt.Sprintf("::\n\n%s\n\n", indentString(cmd.Example, "  ")))
+		buf.WriteString(fmt.Sprintf("::\n\n%s\n\n", indentString(cmd.ExamplesString(), "  ")))
 	}
 
 	if err := printOptionsReST(buf, cmd, name); err != nil {
 		return err
 	}
+	if len(cmd.Groups()) > 0 {
+		buf.WriteString("Command groups\n")
+		buf.WriteString("~~~~~~~~~~~~~~\n\n")
+		for _, group := range cmd.Groups() {
+			buf.WriteString("**" + groupName(group) + "**\n\n")
+			if len(group.Description) > 0 {
+				buf.WriteString(group.Description + "\n\n")
+			}
+			for _, child := range groupedCommands(cmd, group) {
+				cname := name + " " + child.Name()
+				ref = strings.ReplaceAll(cname, " ", "_")
+				buf.WriteString(fmt.Sprintf("* %s \t - %s\n", linkHandler(cname, ref), child.Short))
+			}
+			buf.WriteString("\n")
+		}
+	}
 	if hasSeeAlso(cmd) {
 		buf.WriteString("SEE ALSO\n")
 		buf.WriteString("~~~~~~~~\n\n")
@@ -113,13 +141,19 @@ func GenReSTCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string, str
 		sort.Sort(byName(children))
 
 		for _, child := range children {
-			if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() || child.IsHiddenFromDocs() {
 				continue
 			}
 			cname := name + " " + child.Name()
 			ref = strings.ReplaceAll(cname, " ", "_")
 			buf.WriteString(fmt.Sprintf("* %s \t - %s\n", linkHandler(cname, ref), child.Short))
 		}
+
+		for _, related := range cmd.SeeAlsoCommands() {
+			rname := related.CommandPath()
+			ref = strings.ReplaceAll(rname, " ", "_")
+			buf.WriteString(fmt.Sprintf("* %s \t - %s\n", linkHandler(rname, ref), related.Short))
+		}
 		buf.WriteString("\n")
 	}
 	if !cmd.DisableAutoGenTag {
@@ -144,7 +178,7 @@ func GenReSTTree(cmd *cobra.Command, dir string) error {
 // with custom filePrepender and linkHandler.
 func GenReSTTreeCustom(cmd *cobra.Command, dir string, filePrepender func(string) string, linkHandler func(string, string) string) error {
 	for _, c := range cmd.Commands() {
-		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() || c.IsHiddenFromDocs() {
 			continue
 		}
 		if err := GenReSTTreeCustom(c, dir, filePrepender, linkHandler); err != nil {
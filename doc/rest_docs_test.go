@@ -41,6 +41,19 @@ func TestGenRSTDoc(t *testing.T) {
 	checkStringOmits(t, output, deprecatedCmd.Short)
 }
 
+func TestGenRSTCommandGroups(t *testing.T) {
+	cmd := &cobra.Command{Use: "root", Run: emptyRun}
+	cmd.AddGroup(&cobra.Group{ID: "manage", Title: "Management Commands:", Description: "Commands to manage the resources."})
+	cmd.AddCommand(&cobra.Command{Use: "create", Short: "Create a resource", GroupID: "manage", Run: emptyRun})
+
+	buf := new(bytes.Buffer)
+	if err := GenReST(cmd, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStringContains(t, buf.String(), "Command groups\n~~~~~~~~~~~~~~\n\n**Management Commands**\n\nCommands to manage the resources.\n\n* `root create <root_create.rst>`_ \t - Create a resource\n")
+}
+
 func TestGenRSTNoHiddenParents(t *testing.T) {
 	// We generate on a subcommand so we have both subcommands and parents
 	for _, name := range []string{"rootflag", "strtwo"} {
@@ -96,6 +109,36 @@ func TestGenRSTTree(t *testing.T) {
 	}
 }
 
+func TestGenRSTTreeHonorsCommandSettings(t *testing.T) {
+	rootCmd := &cobra.Command{
+		Use:               "kubectl-plugin",
+		Annotations:       map[string]string{cobra.CommandDisplayNameAnnotation: "kubectl plugin"},
+		DisableAutoGenTag: true,
+		Run:               emptyRun,
+	}
+	rootCmd.AddCommand(
+		&cobra.Command{Use: "visible", Short: "A visible command", Run: emptyRun},
+		&cobra.Command{Use: "secret", Short: "A hidden command", Hidden: true, Run: emptyRun},
+	)
+	tmpdir := t.TempDir()
+
+	if err := GenReSTTree(rootCmd, tmpdir); err != nil {
+		t.Fatalf("GenReSTTree failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpdir, "kubectl_plugin_secret.rst")); !os.IsNotExist(err) {
+		t.Error("Expected no file for the hidden command")
+	}
+	for _, name := range []string{"kubectl_plugin.rst", "kubectl_plugin_visible.rst"} {
+		content, err := os.ReadFile(filepath.Join(tmpdir, name))
+		if err != nil {
+			t.Fatalf("Expected file %q to exist", name)
+		}
+		checkStringOmits(t, string(content), "Auto generated")
+		checkStringOmits(t, string(content), "A hidden command")
+	}
+}
+
 func BenchmarkGenReSTToFile(b *testing.B) {
 	file, err := ioutil.TempFile("", "")
 	if err != nil {
@@ -15,20 +15,22 @@
 package doc
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Test to see if we have a reason to print See Also information in docs
 // Basically this is a test for a parent command or a subcommand which is
 // both not deprecated and not the autogenerated help command.
 func hasSeeAlso(cmd *cobra.Command) bool {
-	if cmd.HasParent() {
+	if cmd.HasParent() || len(cmd.SeeAlsoCommands()) > 0 {
 		return true
 	}
 	for _, c := range cmd.Commands() {
-		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() || c.IsHiddenFromDocs() {
 			continue
 		}
 		return true
@@ -36,6 +38,54 @@ func hasSeeAlso(cmd *cobra.Command) bool {
 	return false
 }
 
+// groupedCommands returns the available sub-commands of cmd which belong to group.
+func groupedCommands(cmd *cobra.Command, group *cobra.Group) []*cobra.Command {
+	var cmds []*cobra.Command
+	for _, c := range cmd.Commands() {
+		if c.GroupID == group.ID && c.IsAvailableCommand() && !c.IsAdditionalHelpTopicCommand() && !c.IsHiddenFromDocs() {
+			cmds = append(cmds, c)
+		}
+	}
+	return cmds
+}
+
+// deprecatedAliases describes the old names of cmd and of its flags, which are kept
+// working by cobra.RenameCommand and cobra.RenameFlag.
+func deprecatedAliases(cmd *cobra.Command) []string {
+	var aliases []string
+	for _, path := range cmd.DeprecatedAliases() {
+		aliases = append(aliases, fmt.Sprintf("%s: deprecated alias of %s", path, cmd.CommandPath()))
+	}
+	cmd.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if newName := f.Annotations[cobra.FlagRenamedToAnnotation]; len(newName) > 0 {
+			aliases = append(aliases, fmt.Sprintf("--%s: deprecated alias of --%s", f.Name, newName[0]))
+		}
+	})
+	return aliases
+}
+
+// envVarFlags returns a copy of flags in which the usage of the flags bound to an
+// environment variable, see cobra.BindEnv, ends with the name of the variable, as
+// in the help, e.g. "[$APP_TOKEN]".
+func envVarFlags(flags *pflag.FlagSet) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	fs.SortFlags = flags.SortFlags
+	flags.VisitAll(func(f *pflag.Flag) {
+		if envVar := f.Annotations[cobra.FlagEnvVarAnnotation]; len(envVar) > 0 {
+			cp := *f
+			cp.Usage += fmt.Sprintf(" [$%s]", envVar[0])
+			f = &cp
+		}
+		fs.AddFlag(f)
+	})
+	return fs
+}
+
+// groupName returns the title of group without its trailing colon.
+func groupName(group *cobra.Group) string {
+	return strings.TrimSuffix(strings.TrimSpace(group.Title), ":")
+}
+
 // Temporary workaround for yaml lib generating incorrect yaml with long strings
 // that do not contain \n.
 func forceMultiLine(s string) string {
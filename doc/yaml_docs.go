@@ -15,7 +15,6 @@
 package doc
 
 import (
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -30,19 +29,22 @@ import (
 type cmdOption struct {
 	Name         string
 	Shorthand    string `yaml:",omitempty"`
+	Type         string `yaml:",omitempty"`
 	DefaultValue string `yaml:"default_value,omitempty"`
 	Usage        string `yaml:",omitempty"`
 }
 
 type cmdDoc struct {
 	Name             string
-	Synopsis         string      `yaml:",omitempty"`
-	Description      string      `yaml:",omitempty"`
-	Usage            string      `yaml:",omitempty"`
-	Options          []cmdOption `yaml:",omitempty"`
-	InheritedOptions []cmdOption `yaml:"inherited_options,omitempty"`
-	Example          string      `yaml:",omitempty"`
-	SeeAlso          []string    `yaml:"see_also,omitempty"`
+	Synopsis         string          `yaml:",omitempty"`
+	Description      string          `yaml:",omitempty"`
+	Usage            string          `yaml:",omitempty"`
+	Options          []cmdOption     `yaml:",omitempty"`
+	InheritedOptions []cmdOption     `yaml:"inherited_options,omitempty"`
+	Commands         []string        `yaml:",omitempty"`
+	Example          string          `yaml:",omitempty"`
+	Examples         []cobra.Example `yaml:",omitempty"`
+	SeeAlso          []string        `yaml:"see_also,omitempty"`
 }
 
 // GenYamlTree creates yaml structured ref files for this command and all descendants
@@ -59,7 +61,7 @@ func GenYamlTree(cmd *cobra.Command, dir string) error {
 // GenYamlTreeCustom creates yaml structured ref files.
 func GenYamlTreeCustom(cmd *cobra.Command, dir string, filePrepender, linkHandler func(string) string) error {
 	for _, c := range cmd.Commands() {
-		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() || c.IsHiddenFromDocs() {
 			continue
 		}
 		if err := GenYamlTreeCustom(c, dir, filePrepender, linkHandler); err != nil {
@@ -107,16 +109,24 @@ func GenYamlCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string) str
 	if len(cmd.Example) > 0 {
 		yamlDoc.Example = cmd.Example
 	}
+	yamlDoc.Examples = cmd.Examples
 
-	flags := cmd.NonInheritedFlags()
+	flags := envVarFlags(cmd.NonInheritedFlags())
 	if flags.HasFlags() {
 		yamlDoc.Options = genFlagResult(flags)
 	}
-	flags = cmd.InheritedFlags()
+	flags = envVarFlags(cmd.InheritedFlags())
 	if flags.HasFlags() {
 		yamlDoc.InheritedOptions = genFlagResult(flags)
 	}
 
+	for _, child := range cmd.Commands() {
+		if child.IsAvailableCommand() && !child.IsAdditionalHelpTopicCommand() && !child.IsHiddenFromDocs() {
+			yamlDoc.Commands = append(yamlDoc.Commands, child.Name())
+		}
+	}
+	sort.Strings(yamlDoc.Commands)
+
 	if hasSeeAlso(cmd) {
 		result := []string{}
 		if cmd.HasParent() {
@@ -126,18 +136,20 @@ func GenYamlCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string) str
 		children := cmd.Commands()
 		sort.Sort(byName(children))
 		for _, child := range children {
-			if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() || child.IsHiddenFromDocs() {
 				continue
 			}
 			result = append(result, child.CommandPath()+" - "+child.Short)
 		}
+		for _, related := range cmd.SeeAlsoCommands() {
+			result = append(result, related.CommandPath()+" - "+related.Short)
+		}
 		yamlDoc.SeeAlso = result
 	}
 
 	final, err := yaml.Marshal(&yamlDoc)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return err
 	}
 
 	if _, err := w.Write(final); err != nil {
@@ -157,6 +169,7 @@ func genFlagResult(flags *pflag.FlagSet) []cmdOption {
 			opt := cmdOption{
 				flag.Name,
 				flag.Shorthand,
+				flag.Value.Type(),
 				flag.DefValue,
 				forceMultiLine(flag.Usage),
 			}
@@ -164,6 +177,7 @@ func genFlagResult(flags *pflag.FlagSet) []cmdOption {
 		} else {
 			opt := cmdOption{
 				Name:         flag.Name,
+				Type:         flag.Value.Type(),
 				DefaultValue: forceMultiLine(flag.DefValue),
 				Usage:        forceMultiLine(flag.Usage),
 			}
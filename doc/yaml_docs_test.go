@@ -84,6 +84,21 @@ func TestGenYamlDocRunnable(t *testing.T) {
 	checkStringContains(t, output, "usage: "+rootCmd.Use)
 }
 
+func TestGenYamlDocWithExamples(t *testing.T) {
+	cmd := &cobra.Command{
+		Use:      "get",
+		Examples: []cobra.Example{{Description: "Get everything", Command: "get --all"}},
+		Run:      emptyRun,
+	}
+	buf := new(bytes.Buffer)
+	if err := GenYaml(cmd, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, "examples:\n    - description: Get everything\n      command: get --all\n")
+}
+
 func BenchmarkGenYamlToFile(b *testing.B) {
 	file, err := ioutil.TempFile("", "")
 	if err != nil {
@@ -99,3 +114,32 @@ func BenchmarkGenYamlToFile(b *testing.B) {
 		}
 	}
 }
+
+func TestGenYamlTreeSchema(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root", Short: "The root command", Run: emptyRun}
+	rootCmd.Flags().IntP("replicas", "r", 3, "Number of replicas")
+	rootCmd.AddCommand(
+		&cobra.Command{Use: "get", Short: "Get a resource", Run: emptyRun},
+		&cobra.Command{Use: "create", Short: "Create a resource", Run: emptyRun},
+		&cobra.Command{Use: "secret", Hidden: true, Run: emptyRun},
+	)
+	tmpdir := t.TempDir()
+
+	if err := GenYamlTree(rootCmd, tmpdir); err != nil {
+		t.Fatalf("GenYamlTree failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpdir, "root.yaml"))
+	if err != nil {
+		t.Fatalf("Expected file 'root.yaml' to exist")
+	}
+	output := string(content)
+
+	checkStringContains(t, output, "usage: root [flags]\n")
+	checkStringContains(t, output, "- name: replicas\n      shorthand: r\n      type: int\n      default_value: \"3\"\n")
+	checkStringContains(t, output, "commands:\n    - create\n    - get\n")
+	checkStringOmits(t, output, "- secret")
+	if _, err := os.Stat(filepath.Join(tmpdir, "root_get.yaml")); err != nil {
+		t.Error("Expected file 'root_get.yaml' to exist")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+// AddEphemeral adds commands to root which are only part of the tree when one of
+// them, or one of their sub-commands, is called: they are then added to root for
+// the time of the execution.  This allows providing hidden utility commands, such
+// as the one used for shell completion, without any side effect on the tree, e.g.
+// on the arguments accepted by a root command without sub-commands.
+func AddEphemeral(root *Command, cmds ...*Command) {
+	root.ephemeralCommands = append(root.ephemeralCommands, cmds...)
+}
+
+// addEphemeralCommands adds the ephemeral commands to c, the root being executed,
+// if args call one of them, and returns the commands added so that they can be
+// removed after the execution.
+func (c *Command) addEphemeralCommands(args []string) []*Command {
+	cmds := append([]*Command{newCompleteCmd(), newSchemaCmd()}, c.ephemeralCommands...)
+	c.AddCommand(cmds...)
+	if cmd, _, err := c.Find(args); err != nil || !cmd.isEphemeral(cmds) {
+		c.removeCommands(cmds...)
+		return nil
+	}
+	return cmds
+}
+
+// isEphemeral returns true if the command is one of cmds or one of their sub-commands.
+func (c *Command) isEphemeral(cmds []*Command) bool {
+	for p := c; p != nil; p = p.parent {
+		for _, cmd := range cmds {
+			if p == cmd {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isEphemeralChild returns true if sub is one of the ephemeral commands of c,
+// which are only part of the tree while one of them is executed.
+func (c *Command) isEphemeralChild(sub *Command) bool {
+	if sub.Name() == ShellCompRequestCmd || sub.Name() == SchemaRequestCmd {
+		return true
+	}
+	for _, eph := range c.ephemeralCommands {
+		if sub == eph {
+			return true
+		}
+	}
+	return false
+}
+
+// ephemeralChildren returns the ephemeral commands currently added to c.
+func (c *Command) ephemeralChildren() []*Command {
+	var cmds []*Command
+	for _, sub := range c.commands {
+		if c.isEphemeralChild(sub) {
+			cmds = append(cmds, sub)
+		}
+	}
+	return cmds
+}
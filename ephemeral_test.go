@@ -0,0 +1,59 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddEphemeral(t *testing.T) {
+	var rootArgs, debugArgs []string
+	rootCmd := &Command{Use: "root", Run: func(_ *Command, args []string) { rootArgs = args }}
+	debugCmd := &Command{Use: "__debug", Hidden: true, Run: func(_ *Command, args []string) { debugArgs = args }}
+	AddEphemeral(rootCmd, debugCmd)
+
+	if _, err := executeCommand(rootCmd, "one", "two"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(rootArgs, []string{"one", "two"}) || debugArgs != nil {
+		t.Errorf("Expected the root command to receive its args, got %v", rootArgs)
+	}
+
+	if _, err := executeCommand(rootCmd, "__debug", "three"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(debugArgs, []string{"three"}) {
+		t.Errorf("Expected the ephemeral command to receive its args, got %v", debugArgs)
+	}
+	if rootCmd.HasSubCommands() || debugCmd.HasParent() {
+		t.Error("Expected the ephemeral command not to remain in the tree")
+	}
+}
+
+func TestCompleteCmdIsEphemeral(t *testing.T) {
+	rootCmd := &Command{Use: "root", ValidArgs: []string{"one", "two"}, Run: emptyRun}
+
+	for i := 0; i < 2; i++ {
+		output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		checkStringContains(t, output, "one\ntwo\n")
+		if rootCmd.HasSubCommands() {
+			t.Fatalf("Expected the %s command not to remain in the tree", ShellCompRequestCmd)
+		}
+	}
+}
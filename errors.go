@@ -0,0 +1,210 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// UnknownCommandError is returned when a command receives an argument which is
+// not one of its sub-commands.
+type UnknownCommandError struct {
+	// Command is the path of the command which received the argument.
+	Command string
+	// Name is the unknown sub-command.
+	Name string
+	// Suggestions are the sub-commands the user may have meant.
+	Suggestions []string
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command %q for %q%s", e.Name, e.Command, formatSuggestions(e.Suggestions))
+}
+
+// MissingGroupError is returned when executing a program in which a command has a
+// group ID which is not defined by its parent, see ExecOptions.StrictCommandGroups.
+type MissingGroupError struct {
+	// Command is the path of the command.
+	Command string
+	// GroupID is the group ID of the command.
+	GroupID string
+}
+
+func (e *MissingGroupError) Error() string {
+	return fmt.Sprintf("group id '%s' is not defined for subcommand '%s'", e.GroupID, e.Command)
+}
+
+// TimeoutError is returned when a command fails after its Timeout elapsed.
+type TimeoutError struct {
+	// Command is the path of the command.
+	Command string
+	// Timeout is the Timeout of the command.
+	Timeout time.Duration
+	// Err is the error returned by the command.
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("command timed out after %s", e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// RequiredFlagError is returned when required flags of a command are not set.
+type RequiredFlagError struct {
+	// Command is the path of the command.
+	Command string
+	// Flags are the names of the required flags which are not set.
+	Flags []string
+	// EnvVars maps the names of the flags which can also be set from an
+	// environment variable to the name of the variable.
+	EnvVars map[string]string
+}
+
+func (e *RequiredFlagError) Error() string {
+	msg := fmt.Sprintf(`required flag(s) "%s" not set`, strings.Join(e.Flags, `", "`))
+	var alternatives []string
+	for _, name := range e.Flags {
+		if envVar, ok := e.EnvVars[name]; ok {
+			alternatives = append(alternatives, fmt.Sprintf("%s for %q", envVar, name))
+		}
+	}
+	if len(alternatives) > 0 {
+		msg += fmt.Sprintf(" (or set the environment variable(s) %s)", strings.Join(alternatives, ", "))
+	}
+	return msg
+}
+
+// InvalidArgsError is returned by the PositionalArgs provided by Cobra when the
+// positional arguments of a command are not valid.
+type InvalidArgsError struct {
+	// Command is the path of the command.
+	Command string
+	// Args are the positional arguments received by the command.
+	Args []string
+	// Arg is the invalid argument, or an empty string if the error is about
+	// the number of arguments.
+	Arg string
+	// Suggestions are the valid arguments the user may have meant.
+	Suggestions []string
+	// Reason describes why the arguments are not valid.
+	Reason string
+}
+
+func (e *InvalidArgsError) Error() string {
+	return e.Reason + formatSuggestions(e.Suggestions)
+}
+
+// usageError marks an error due to an incorrect usage of a command which has no
+// specific type, such as an unknown flag.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+
+func (e *usageError) Unwrap() error { return e.err }
+
+// newUsageError marks err as a usage error.
+func newUsageError(err error) error {
+	if err == nil || IsUsageError(err) {
+		return err
+	}
+	return &usageError{err: err}
+}
+
+// IsUsageError returns true if err is due to an incorrect usage of a command, such as
+// an unknown command or flag, an invalid flag value, missing required flags or invalid
+// positional arguments, as opposed to an error returned by the command itself.
+func IsUsageError(err error) bool {
+	var usageErr *usageError
+	var unknownCommandErr *UnknownCommandError
+	var requiredFlagErr *RequiredFlagError
+	var invalidArgsErr *InvalidArgsError
+	return errors.As(err, &usageErr) || errors.As(err, &unknownCommandErr) ||
+		errors.As(err, &requiredFlagErr) || errors.As(err, &invalidArgsErr)
+}
+
+// ExitError is an error with the exit code the program should exit with, see ExitCode.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// ExitCode returns the conventional exit code of a program whose execution returned err:
+// 0 on success, including when help was requested, 130 when interrupted by a signal,
+// see ExecuteWithSignals, the code of an ExitError, 2 for usage errors and 1 otherwise.
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrInterrupted):
+		return 130
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	case IsUsageError(err):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// osExit is used by ExecuteExit; it is a variable for testing.
+var osExit = os.Exit
+
+// ExecuteExit executes the root command of cmd, cancelling its context on interrupt
+// like ExecuteWithSignals, and exits the program with the code returned by ExitCode.
+// It is meant to be the only statement of the main function.
+func ExecuteExit(cmd *Command) {
+	osExit(ExitCode(ExecuteWithSignals(cmd)))
+}
+
+// suggestionsForError returns the suggestions to include in an error about arg,
+// unless suggestions are disabled.
+func (c *Command) suggestionsForError(arg string) []string {
+	if c.suggestionsDisabled() {
+		return nil
+	}
+	return c.SuggestionsFor(arg)
+}
+
+// formatSuggestions formats suggestions to be appended to an error message.
+func formatSuggestions(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nDid you mean this?\n")
+	for _, s := range suggestions {
+		_, _ = fmt.Fprintf(&sb, "\t%v\n", s)
+	}
+	return sb.String()
+}
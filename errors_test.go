@@ -0,0 +1,186 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestUnknownCommandError(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "times", Run: emptyRun})
+
+	_, err := executeCommand(rootCmd, "time")
+	var unknownErr *UnknownCommandError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("Expected an UnknownCommandError, got %#v", err)
+	}
+	expected := &UnknownCommandError{Command: "root", Name: "time", Suggestions: []string{"times"}}
+	if !reflect.DeepEqual(unknownErr, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, unknownErr)
+	}
+	if err.Error() != "unknown command \"time\" for \"root\"\n\nDid you mean this?\n\ttimes\n" {
+		t.Errorf("Unexpected error message: %q", err.Error())
+	}
+
+	rootCmd.DisableSuggestions = true
+	_, err = executeCommand(rootCmd, "time")
+	if !errors.As(err, &unknownErr) || unknownErr.Suggestions != nil {
+		t.Errorf("Expected an UnknownCommandError without suggestions, got %#v", err)
+	}
+}
+
+func TestRequiredFlagError(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().String("foo", "", "")
+	rootCmd.Flags().String("bar", "", "")
+	assertNoErr(t, rootCmd.MarkFlagRequired("foo"))
+	assertNoErr(t, rootCmd.MarkFlagRequired("bar"))
+
+	_, err := executeCommand(rootCmd)
+	var requiredErr *RequiredFlagError
+	if !errors.As(err, &requiredErr) {
+		t.Fatalf("Expected a RequiredFlagError, got %#v", err)
+	}
+	if requiredErr.Command != "root" || !reflect.DeepEqual(requiredErr.Flags, []string{"bar", "foo"}) {
+		t.Errorf("Unexpected error: %#v", requiredErr)
+	}
+	if err.Error() != `required flag(s) "bar", "foo" not set` {
+		t.Errorf("Unexpected error message: %q", err.Error())
+	}
+}
+
+func TestInvalidArgsError(t *testing.T) {
+	rootCmd := &Command{Use: "root", Args: OnlyValidArgs, ValidArgs: []string{"one", "two"}, Run: emptyRun}
+
+	_, err := executeCommand(rootCmd, "one", "tow")
+	var invalidErr *InvalidArgsError
+	if !errors.As(err, &invalidErr) {
+		t.Fatalf("Expected an InvalidArgsError, got %#v", err)
+	}
+	if invalidErr.Arg != "tow" || !reflect.DeepEqual(invalidErr.Args, []string{"one", "tow"}) || invalidErr.Command != "root" {
+		t.Errorf("Unexpected error: %#v", invalidErr)
+	}
+
+	if err.Error() != `invalid argument "tow" for "root"` {
+		t.Errorf("Unexpected error message: %q", err.Error())
+	}
+
+	rootCmd.Args = ExactArgs(1)
+	_, err = executeCommand(rootCmd, "one", "two")
+	if !errors.As(err, &invalidErr) {
+		t.Fatalf("Expected an InvalidArgsError, got %#v", err)
+	}
+	if invalidErr.Arg != "" || invalidErr.Command != "root" || err.Error() != "accepts 1 arg(s), received 2" {
+		t.Errorf("Unexpected error: %#v", invalidErr)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	newRootCmd := func() *Command {
+		rootCmd := &Command{Use: "root"}
+		remoteCmd := &Command{Use: "remote"}
+		remoteCmd.AddCommand(&Command{Use: "add", Args: ExactArgs(1), Run: emptyRun})
+		failCmd := &Command{Use: "fail", RunE: func(*Command, []string) error { return errors.New("failure") }}
+		conflictCmd := &Command{Use: "conflict", RunE: func(*Command, []string) error {
+			return &ExitError{Code: 3, Err: errors.New("already exists")}
+		}}
+		interruptedCmd := &Command{Use: "interrupted", RunE: func(*Command, []string) error {
+			return &InterruptedError{Signal: os.Interrupt, Err: context.Canceled}
+		}}
+		checkCmd := &Command{
+			Use:  "check",
+			Args: func(*Command, []string) error { return errors.New("invalid") },
+			Run:  emptyRun,
+		}
+		requiredCmd := &Command{Use: "required", Run: emptyRun}
+		requiredCmd.Flags().String("name", "", "")
+		_ = requiredCmd.MarkFlagRequired("name")
+		rootCmd.AddCommand(remoteCmd, failCmd, conflictCmd, interruptedCmd, checkCmd, requiredCmd)
+		return rootCmd
+	}
+
+	testcases := []struct {
+		desc       string
+		args       []string
+		usageError bool
+		exitCode   int
+		output     string
+	}{
+		{"help flag", []string{"--help"}, false, 0, "Usage:"},
+		{"help command", []string{"help", "remote"}, false, 0, "Usage:"},
+		{"non-runnable command", []string{"remote"}, false, 0, "Available Commands:"},
+		{"non-runnable command with args", []string{"remote", "ad"}, false, 0, "Available Commands:"},
+		{"unknown command", []string{"unknown"}, true, 2, `unknown command "unknown" for "root"`},
+		{"unknown flag", []string{"remote", "add", "--unknown", "x"}, true, 2, "unknown flag: --unknown"},
+		{"invalid args", []string{"remote", "add"}, true, 2, "accepts 1 arg(s), received 0"},
+		{"custom args validation", []string{"check"}, true, 2, "invalid"},
+		{"required flag", []string{"required"}, true, 2, `required flag(s) "name" not set`},
+		{"command error", []string{"fail"}, false, 1, "failure"},
+		{"exit error", []string{"conflict"}, false, 3, "Error: already exists"},
+		{"interrupted", []string{"interrupted"}, false, 130, "interrupted by signal"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.desc, func(t *testing.T) {
+			output, err := executeCommand(newRootCmd(), tc.args...)
+			if IsUsageError(err) != tc.usageError {
+				t.Errorf("Expected IsUsageError to be %v, got %v for %v", tc.usageError, !tc.usageError, err)
+			}
+			if code := ExitCode(err); code != tc.exitCode {
+				t.Errorf("Expected exit code %d, got %d for %v", tc.exitCode, code, err)
+			}
+			checkStringContains(t, output, tc.output)
+		})
+	}
+}
+
+func TestStrictSubCommands(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	remoteCmd := &Command{Use: "remote"}
+	remoteCmd.AddCommand(&Command{Use: "add", Run: emptyRun})
+	rootCmd.AddCommand(remoteCmd)
+
+	rootCmd.SetExecOptions(ExecOptions{StrictSubCommands: true})
+	output, err := executeCommand(rootCmd, "remote", "ad")
+	var unknownErr *UnknownCommandError
+	if !errors.As(err, &unknownErr) || ExitCode(err) != 2 {
+		t.Errorf("Expected an UnknownCommandError, got %v", err)
+	}
+	checkStringContains(t, output, `unknown command "ad" for "root remote"`)
+	checkStringContains(t, output, "Did you mean this?\n\tadd")
+}
+
+func TestExecuteExit(t *testing.T) {
+	defer func(exit func(int)) { osExit = exit }(osExit)
+	var code int
+	osExit = func(c int) { code = c }
+
+	rootCmd := &Command{Use: "root", RunE: func(*Command, []string) error {
+		return &ExitError{Code: 4}
+	}}
+	rootCmd.SetArgs(nil)
+	rootCmd.SetErr(new(bytes.Buffer))
+	rootCmd.SetOut(new(bytes.Buffer))
+
+	ExecuteExit(rootCmd)
+	if code != 4 {
+		t.Errorf("Expected exit code 4, got %d", code)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "strings"
+
+// Example is an example of how to use a command, see Command.Examples.
+type Example struct {
+	// Description explains what the example does.  It is optional.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Command is the command line of the example, e.g. "app get --all".
+	Command string `json:"command" yaml:"command"`
+}
+
+// String returns the example as shown in the help output: the description as a
+// comment, followed by the command line, both indented.
+func (e Example) String() string {
+	var b strings.Builder
+	for _, line := range strings.Split(e.Description, "\n") {
+		if line != "" {
+			b.WriteString("  # " + line + "\n")
+		}
+	}
+	b.WriteString("  " + e.Command)
+	return b.String()
+}
+
+// ExamplesString returns the free-form Example of the command followed by its
+// structured Examples, separated by blank lines, as shown in the help output and
+// in the generated documentation.
+func (c *Command) ExamplesString() string {
+	var parts []string
+	if c.Example != "" {
+		parts = append(parts, strings.TrimRight(c.Example, "\n"))
+	}
+	for _, e := range c.Examples {
+		parts = append(parts, e.String())
+	}
+	return strings.Join(parts, "\n\n")
+}
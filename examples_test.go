@@ -0,0 +1,75 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExamplesInHelp(t *testing.T) {
+	rootCmd := &Command{
+		Use:     "app",
+		Example: "  app --all\n",
+		Examples: []Example{
+			{Description: "Get a single item", Command: "app get item"},
+			{Command: "app get --watch"},
+		},
+		Run: emptyRun,
+	}
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "Examples:\n  app --all\n\n  # Get a single item\n  app get item\n\n  app get --watch\n\nFlags:")
+}
+
+func TestExamplesWithoutExample(t *testing.T) {
+	rootCmd := &Command{
+		Use:      "app",
+		Examples: []Example{{Description: "Run it", Command: "app"}},
+		Run:      emptyRun,
+	}
+
+	if !rootCmd.HasExample() {
+		t.Error("expected HasExample to be true with structured examples only")
+	}
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "Examples:\n  # Run it\n  app\n")
+}
+
+func TestExamplesInSchema(t *testing.T) {
+	rootCmd := &Command{
+		Use:      "app",
+		Examples: []Example{{Description: "Run it", Command: "app"}},
+		Run:      emptyRun,
+	}
+
+	b, err := Schema(rootCmd)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var s CommandSchema
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(s.Examples) != 1 || s.Examples[0] != rootCmd.Examples[0] {
+		t.Errorf("expected examples %v, got %v", rootCmd.Examples, s.Examples)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "strings"
+
+// ExecOptions are the settings of the parsing and execution of a command tree.
+// They are set with SetExecOptions, usually on the root command, so that several
+// trees, e.g. in parallel tests, can use different settings.  The commands which
+// have none use DefaultExecOptions.
+type ExecOptions struct {
+	// PrefixMatching allows unambiguous prefixes of the command names and aliases.
+	PrefixMatching bool
+	// CommandSorting sorts the sub-commands by name.
+	CommandSorting bool
+	// CaseInsensitiveCommands allows case-insensitive command names.
+	CaseInsensitiveCommands bool
+	// CaseInsensitiveAliases allows case-insensitive command aliases.
+	CaseInsensitiveAliases bool
+	// CaseInsensitiveFlags allows case-insensitive long flag names.
+	CaseInsensitiveFlags bool
+	// TraverseRunHooks executes the persistent pre-run and post-run hooks of all
+	// the parents instead of only the first one found.
+	TraverseRunHooks bool
+	// POSIXStrict ends the flags at the first argument which is not a flag, see
+	// SetFlagsInterspersed.
+	POSIXStrict bool
+	// StrictCommandGroups makes executing a command panic, as it used to, when one
+	// of the commands of the tree has a group ID which is not defined by its parent.
+	// By default, the execution fails with a MissingGroupError instead.
+	StrictCommandGroups bool
+	// StrictSubCommands reports an UnknownCommandError, rather than printing the
+	// help, when a command which is not runnable receives arguments which are not
+	// sub-commands.  The root command always reports them unless its Args are set.
+	StrictSubCommands bool
+	// DebugFlag adds a hidden persistent '--boot-debug' flag to the root command,
+	// see InitDefaultDebugFlag.
+	DebugFlag bool
+}
+
+// DefaultExecOptions returns the settings of the commands which have no
+// ExecOptions, i.e. the current values of the package-level Enable* variables
+// for the settings which have one, and false for the others.
+func DefaultExecOptions() ExecOptions {
+	return ExecOptions{
+		PrefixMatching:          EnablePrefixMatching,
+		CommandSorting:          EnableCommandSorting,
+		CaseInsensitiveCommands: EnableCaseInsensitive,
+		CaseInsensitiveAliases:  EnableCaseInsensitive,
+		TraverseRunHooks:        EnableTraverseRunHooks,
+	}
+}
+
+// SetExecOptions sets the settings of the command and its children which do not
+// set their own, replacing the package-level Enable* variables for them.  Start
+// from DefaultExecOptions to only change some of them.
+func (c *Command) SetExecOptions(opts ExecOptions) {
+	c.execOptions = &opts
+}
+
+// ExecOptions returns the settings of the command: the ones set by SetExecOptions
+// on the command or its closest parent, or else DefaultExecOptions.
+func (c *Command) ExecOptions() ExecOptions {
+	for p := c; p != nil; p = p.parent {
+		if p.execOptions != nil {
+			return *p.execOptions
+		}
+	}
+	return DefaultExecOptions()
+}
+
+// commandNameMatches checks if two command names are equal taking into account
+// the case sensitivity of the options.
+func (o ExecOptions) commandNameMatches(s string, t string) bool {
+	if o.CaseInsensitiveCommands {
+		return strings.EqualFold(s, t)
+	}
+
+	return s == t
+}
+
+// commandAliasMatches checks if two command aliases are equal taking into account
+// the case sensitivity of the options.
+func (o ExecOptions) commandAliasMatches(s string, t string) bool {
+	if o.CaseInsensitiveAliases {
+		return strings.EqualFold(s, t)
+	}
+
+	return s == t
+}
+
+// commandNameHasPrefix checks if a command name starts with prefix
+// taking into account the same case sensitivity as commandNameMatches.
+func (o ExecOptions) commandNameHasPrefix(name string, prefix string) bool {
+	if o.CaseInsensitiveCommands {
+		return strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix))
+	}
+
+	return strings.HasPrefix(name, prefix)
+}
+
+// commandAliasHasPrefix checks if a command alias starts with prefix
+// taking into account the same case sensitivity as commandAliasMatches.
+func (o ExecOptions) commandAliasHasPrefix(alias string, prefix string) bool {
+	if o.CaseInsensitiveAliases {
+		return strings.HasPrefix(strings.ToLower(alias), strings.ToLower(prefix))
+	}
+
+	return strings.HasPrefix(alias, prefix)
+}
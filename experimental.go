@@ -0,0 +1,86 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// These values should not be changed: users will be using them explicitly.
+const (
+	enableExperimentalFlagName           = "enable-experimental"
+	configEnvVarSuffixEnableExperimental = "ENABLE_EXPERIMENTAL"
+)
+
+// ExperimentalCommandError is returned when executing an experimental command
+// without enabling experimental commands.
+type ExperimentalCommandError struct {
+	// Command is the path of the experimental command.
+	Command string
+	// EnvVar is the environment variable which enables experimental commands.
+	EnvVar string
+}
+
+func (e *ExperimentalCommandError) Error() string {
+	return fmt.Sprintf("command %q is experimental, use --%s or set %s=true to run it",
+		e.Command, enableExperimentalFlagName, e.EnvVar)
+}
+
+// IsExperimental returns true if the command or one of its parents is experimental.
+func (c *Command) IsExperimental() bool {
+	for p := c; p != nil; p = p.Parent() {
+		if p.Experimental {
+			return true
+		}
+	}
+	return false
+}
+
+// initExperimentalFlag adds the hidden '--enable-experimental' persistent flag to c
+// if its tree contains experimental commands.
+func (c *Command) initExperimentalFlag() {
+	if c.PersistentFlags().Lookup(enableExperimentalFlagName) != nil {
+		return
+	}
+	hasExperimental := false
+	c.visitTree(func(cmd *Command) {
+		hasExperimental = hasExperimental || cmd.Experimental
+	})
+	if !hasExperimental {
+		return
+	}
+	c.PersistentFlags().Bool(enableExperimentalFlagName, false, "allow running experimental commands")
+	_ = c.PersistentFlags().MarkHidden(enableExperimentalFlagName)
+	_ = c.PersistentFlags().SetAnnotation(enableExperimentalFlagName, FlagSetByCobraAnnotation, []string{"true"})
+}
+
+// checkExperimental returns an ExperimentalCommandError if the command is experimental
+// and experimental commands were enabled neither by flag nor by environment variable.
+func (c *Command) checkExperimental() error {
+	if !c.IsExperimental() {
+		return nil
+	}
+	if enabled, err := c.Flags().GetBool(enableExperimentalFlagName); err == nil && enabled {
+		return nil
+	}
+	if enabled, err := strconv.ParseBool(getEnvConfig(c, configEnvVarSuffixEnableExperimental)); err == nil && enabled {
+		return nil
+	}
+	return &ExperimentalCommandError{
+		Command: c.CommandPath(),
+		EnvVar:  configEnvVar(c.Root().Name(), configEnvVarSuffixEnableExperimental),
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"testing"
+)
+
+func getExperimentalCmd(ran *bool) *Command {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	betaCmd := &Command{Use: "beta", Short: "Beta features", Experimental: true, Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: func(*Command, []string) { *ran = true }}
+	betaCmd.AddCommand(childCmd)
+	rootCmd.AddCommand(betaCmd, &Command{Use: "stable", Short: "Stable", Run: emptyRun})
+	return rootCmd
+}
+
+func TestExperimentalCommandGated(t *testing.T) {
+	t.Setenv("ROOT_ENABLE_EXPERIMENTAL", "")
+	t.Setenv("COBRA_ENABLE_EXPERIMENTAL", "")
+
+	ran := false
+	_, err := executeCommand(getExperimentalCmd(&ran), "beta", "child")
+	var expErr *ExperimentalCommandError
+	if !errors.As(err, &expErr) {
+		t.Fatalf("Expected an ExperimentalCommandError, got %v", err)
+	}
+	expected := `command "root beta child" is experimental, use --enable-experimental or set ROOT_ENABLE_EXPERIMENTAL=true to run it`
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+	if ran {
+		t.Error("The experimental command should not have run")
+	}
+
+	_, err = executeCommand(getExperimentalCmd(&ran), "beta", "child", "--enable-experimental")
+	if err != nil || !ran {
+		t.Errorf("Expected the command to run with the flag, got %v", err)
+	}
+
+	ran = false
+	t.Setenv("ROOT_ENABLE_EXPERIMENTAL", "true")
+	_, err = executeCommand(getExperimentalCmd(&ran), "beta", "child")
+	if err != nil || !ran {
+		t.Errorf("Expected the command to run with the environment variable, got %v", err)
+	}
+}
+
+func TestExperimentalCommandHelp(t *testing.T) {
+	ran := false
+	rootCmd := getExperimentalCmd(&ran)
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "Beta features (experimental)\n")
+	checkStringOmits(t, output, "Stable (experimental)")
+	checkStringOmits(t, output, "enable-experimental")
+
+	// The help of an experimental command is always available.
+	if _, err = executeCommand(rootCmd, "beta", "child", "--help"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestNoExperimentalFlagWithoutExperimentalCommands(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if rootCmd.PersistentFlags().Lookup("enable-experimental") != nil {
+		t.Error("Expected no --enable-experimental flag")
+	}
+}
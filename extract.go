@@ -0,0 +1,472 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// Extract returns a copy of the sub-command of root at path, such as "remote add",
+// which is detached from root and can be executed as the root of its own program.
+// This allows shipping a smaller program made of a part of a larger one.
+//
+// The copy includes the sub-commands, flags, groups and help of the command, as well
+// as the persistent flags, the persistent run hooks and the settings, such as templates
+// or ExecOptions, it inherits from its parents.  The flags of the copy are bound to the same variables as the original ones.
+// The references of SeeAlso to commands outside of the copy are dropped.
+// Extract is meant to be called before root is executed.
+func Extract(root *Command, path string) (*Command, error) {
+	cmd := findCommandByNames(root, strings.Fields(path))
+	if cmd == nil {
+		return nil, fmt.Errorf("cannot extract %q: no such command in %q", path, root.Name())
+	}
+
+	e := &extractor{
+		flags:   map[*flag.Flag]*flag.Flag{},
+		oldPath: cmd.CommandPath(),
+		newPath: cmd.Name(),
+	}
+	// The persistent flags inherited from the parents become persistent flags of the copy,
+	// and must be known before copying the flag sets which may already include them.
+	inherited := cmd.InheritedFlags()
+	cp := e.clone(cmd, func(cp *Command) {
+		inherited.VisitAll(func(f *flag.Flag) {
+			if cp.PersistentFlags().Lookup(f.Name) == nil {
+				cp.PersistentFlags().AddFlag(e.cloneFlag(f))
+			}
+		})
+	})
+	cp.GroupID = ""
+
+	traverseRunHooks := cmd.ExecOptions().TraverseRunHooks
+	for p := cmd.parent; p != nil; p = p.parent {
+		e.copyFlagCompletions(p, cp)
+		e.copyFlagValidators(p, cp)
+		cp.inheritSettings(p)
+		cp.inheritRunHooks(p, traverseRunHooks)
+	}
+	return cp, nil
+}
+
+// extractor holds the state of a call to Extract.
+type extractor struct {
+	// flags maps the original flags to their copy.
+	flags map[*flag.Flag]*flag.Flag
+	// oldPath and newPath are the paths of the extracted command and of its copy.
+	oldPath, newPath string
+}
+
+// clone returns a detached copy of c and its sub-commands; initFlags, if not nil,
+// is called before the flags of c are copied.
+// The copy does not share any slice or map with c, and does not include the state
+// of a previous execution of c.  TestExtractCopiesAllFields makes sure every field
+// of Command is either copied or deliberately left out here.
+func (e *extractor) clone(c *Command, initFlags func(*Command)) *Command {
+	cp := &Command{
+		Use:                        c.Use,
+		DisplayName:                c.DisplayName,
+		Aliases:                    copyStrings(c.Aliases),
+		SuggestFor:                 copyStrings(c.SuggestFor),
+		Short:                      c.Short,
+		GroupID:                    c.GroupID,
+		Long:                       c.Long,
+		Example:                    c.Example,
+		Examples:                   append([]Example(nil), c.Examples...),
+		DocsURL:                    c.DocsURL,
+		Params:                     append([]Parameter(nil), c.Params...),
+		ArgNames:                   copyStrings(c.ArgNames),
+		ValidArgs:                  copyStrings(c.ValidArgs),
+		ValidArgsFunction:          c.ValidArgsFunction,
+		Args:                       c.Args,
+		ArgAliases:                 copyStrings(c.ArgAliases),
+		BashCompletionFunction:     c.BashCompletionFunction,
+		Deprecated:                 c.Deprecated,
+		DeprecationPolicy:          c.DeprecationPolicy,
+		Annotations:                copyStringMap(c.Annotations),
+		Experimental:               c.Experimental,
+		Timeout:                    c.Timeout,
+		Version:                    c.Version,
+		OwnVersionFlag:             c.OwnVersionFlag,
+		PersistentPreRun:           c.PersistentPreRun,
+		PersistentPreRunE:          c.PersistentPreRunE,
+		PreRun:                     c.PreRun,
+		PreRunE:                    c.PreRunE,
+		Run:                        c.Run,
+		RunE:                       c.RunE,
+		PostRun:                    c.PostRun,
+		PostRunE:                   c.PostRunE,
+		PersistentPostRun:          c.PersistentPostRun,
+		PersistentPostRunE:         c.PersistentPostRunE,
+		FParseErrWhitelist:         c.FParseErrWhitelist,
+		CompletionOptions:          c.CompletionOptions,
+		TraverseChildren:           c.TraverseChildren,
+		DropEmptyArgs:              c.DropEmptyArgs,
+		BufferOutput:               c.BufferOutput,
+		Hidden:                     c.Hidden,
+		SilenceErrors:              c.SilenceErrors,
+		SilenceUsage:               c.SilenceUsage,
+		DisableFlagParsing:         c.DisableFlagParsing,
+		DisableAutoGenTag:          c.DisableAutoGenTag,
+		DisableFlagsInUseLine:      c.DisableFlagsInUseLine,
+		DisableSuggestions:         c.DisableSuggestions,
+		SuggestionsMinimumDistance: c.SuggestionsMinimumDistance,
+
+		envPrefix:                c.envPrefix,
+		configuration:            c.configuration,
+		globNormFunc:             c.globNormFunc,
+		usageFunc:                c.usageFunc,
+		suggester:                c.suggester,
+		unknownCommandFunc:       c.unknownCommandFunc,
+		defaultCommand:           c.defaultCommand,
+		completionFilter:         c.completionFilter,
+		metrics:                  c.metrics,
+		telemetry:                c.telemetry,
+		usageTemplate:            c.usageTemplate,
+		flagErrorFunc:            c.flagErrorFunc,
+		helpTemplate:             c.helpTemplate,
+		usageFooter:              c.usageFooter,
+		helpBanner:               c.helpBanner,
+		helpFunc:                 c.helpFunc,
+		helpCommandGroupID:       c.helpCommandGroupID,
+		completionCommandGroupID: c.completionCommandGroupID,
+		versionTemplate:          c.versionTemplate,
+		versionInfo:              copyStringMap(c.versionInfo),
+		interactive:              c.interactive,
+		lazyLoad:                 c.lazyLoad,
+		execOptions:              c.execOptions,
+		helpWidth:                c.helpWidth,
+		flagsInterspersed:        c.flagsInterspersed,
+		middlewares:              append([]Middleware(nil), c.middlewares...),
+		recoverFunc:              c.recoverFunc,
+		onInitialize:             append(([]func(*Command))(nil), c.onInitialize...),
+		onFinalize:               append(([]func(*Command))(nil), c.onFinalize...),
+		commandProviders:         append([]CommandProvider(nil), c.commandProviders...),
+		pluginOptions:            c.pluginOptions,
+		errPrefix:                c.errPrefix,
+		inReader:                 c.inReader,
+		outWriter:                c.outWriter,
+		errWriter:                c.errWriter,
+	}
+	for alias, expansion := range c.aliasExpansions {
+		cp.AddAliasExpansion(alias, expansion)
+	}
+	for t, handlers := range c.eventHandlers {
+		cp.OnEvent(t, handlers...)
+	}
+	for i, f := range c.argCompletions {
+		_ = cp.RegisterArgCompletionFunc(i, f)
+	}
+	for oldName, newWords := range c.renamedCommands {
+		// The new paths are relative to the root, and are only kept if they are in the copy.
+		path := strings.Join(append([]string{c.Root().CommandPath()}, newWords...), " ")
+		if strings.HasPrefix(path, e.oldPath+" ") {
+			if cp.renamedCommands == nil {
+				cp.renamedCommands = map[string][]string{}
+			}
+			cp.renamedCommands[oldName] = strings.Fields(path[len(e.oldPath):])
+		}
+	}
+	for _, path := range c.SeeAlso {
+		if path == e.oldPath || strings.HasPrefix(path, e.oldPath+" ") {
+			cp.SeeAlso = append(cp.SeeAlso, e.newPath+path[len(e.oldPath):])
+		}
+	}
+	for _, g := range c.commandgroups {
+		g := *g
+		cp.commandgroups = append(cp.commandgroups, &g)
+	}
+
+	if initFlags != nil {
+		initFlags(cp)
+	}
+	if c.pflags != nil {
+		e.cloneFlagSet(c.pflags, cp.PersistentFlags())
+	}
+	if c.flags != nil {
+		e.cloneFlagSet(c.flags, cp.Flags())
+	}
+	e.copyFlagCompletions(c, cp)
+	e.copyFlagValidators(c, cp)
+
+	if c.helpCommand != nil {
+		cp.helpCommand = e.clone(c.helpCommand, nil)
+	}
+	for _, sub := range c.commands {
+		if sub != c.helpCommand {
+			cp.AddCommand(e.clone(sub, nil))
+		}
+	}
+	for _, eph := range c.ephemeralCommands {
+		cp.ephemeralCommands = append(cp.ephemeralCommands, e.clone(eph, nil))
+	}
+	return cp
+}
+
+// copyStrings returns a copy of s, or nil if s is empty.
+func copyStrings(s []string) []string {
+	return append([]string(nil), s...)
+}
+
+// copyStringMap returns a copy of m, or nil if m is nil.
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	cp := make(map[string]string, len(m))
+	for k, v := range m {
+		cp[k] = v
+	}
+	return cp
+}
+
+// cloneFlagSet adds to dst the copies of the flags of src which it does not have yet.
+func (e *extractor) cloneFlagSet(src, dst *flag.FlagSet) {
+	dst.SortFlags = src.SortFlags
+	dst.SetNormalizeFunc(src.GetNormalizeFunc())
+	src.VisitAll(func(f *flag.Flag) {
+		if dst.Lookup(f.Name) == nil {
+			dst.AddFlag(e.cloneFlag(f))
+		}
+	})
+}
+
+// cloneFlag returns the copy of f, which shares its value.
+func (e *extractor) cloneFlag(f *flag.Flag) *flag.Flag {
+	if cp, ok := e.flags[f]; ok {
+		return cp
+	}
+	cp := *f
+	cp.Changed = false
+	if f.Annotations != nil {
+		cp.Annotations = make(map[string][]string, len(f.Annotations))
+		for k, v := range f.Annotations {
+			cp.Annotations[k] = append([]string(nil), v...)
+		}
+	}
+	e.flags[f] = &cp
+	return &cp
+}
+
+// copyFlagCompletions registers on dst the completion functions registered on src
+// for the flags which were copied.
+func (e *extractor) copyFlagCompletions(src, dst *Command) {
+	for f, fn := range src.flagCompletions.snapshot() {
+		if cp, ok := e.flags[f]; ok {
+			dst.flagCompletions.register(cp, fn)
+		}
+	}
+}
+
+// copyFlagValidators registers on dst the validators registered on src for the
+// flags which were copied.
+func (e *extractor) copyFlagValidators(src, dst *Command) {
+	for f, validators := range src.flagValidators {
+		if cp, ok := e.flags[f]; ok {
+			if dst.flagValidators == nil {
+				dst.flagValidators = map[*flag.Flag][]func() error{}
+			}
+			dst.flagValidators[cp] = append(dst.flagValidators[cp], validators...)
+		}
+	}
+}
+
+// inheritSettings sets the settings of c which are not set from those of p,
+// for c to behave as if p was its parent.
+func (c *Command) inheritSettings(p *Command) {
+	if c.usageTemplate == "" {
+		c.usageTemplate = p.usageTemplate
+	}
+	if c.helpTemplate == "" {
+		c.helpTemplate = p.helpTemplate
+	}
+	if c.versionTemplate == "" {
+		c.versionTemplate = p.versionTemplate
+	}
+	if c.versionInfo == nil {
+		c.versionInfo = p.versionInfo
+	}
+	if len(p.onInitialize) > 0 {
+		c.onInitialize = append(append(([]func(*Command))(nil), p.onInitialize...), c.onInitialize...)
+	}
+	if len(p.onFinalize) > 0 {
+		c.onFinalize = append(append(([]func(*Command))(nil), p.onFinalize...), c.onFinalize...)
+	}
+	for t, handlers := range p.eventHandlers {
+		if c.eventHandlers == nil {
+			c.eventHandlers = map[EventType][]func(e Event){}
+		}
+		c.eventHandlers[t] = append(append(([]func(e Event))(nil), handlers...), c.eventHandlers[t]...)
+	}
+	if len(p.middlewares) > 0 {
+		c.middlewares = append(append([]Middleware(nil), p.middlewares...), c.middlewares...)
+	}
+	if c.errPrefix == "" {
+		c.errPrefix = p.errPrefix
+	}
+	if c.usageFooter == "" {
+		c.usageFooter = p.usageFooter
+	}
+	if c.helpBanner == "" {
+		c.helpBanner = p.helpBanner
+	}
+	if c.envPrefix == "" {
+		c.envPrefix = p.envPrefix
+	}
+	if c.usageFunc == nil {
+		c.usageFunc = p.usageFunc
+	}
+	if c.helpFunc == nil {
+		c.helpFunc = p.helpFunc
+	}
+	if c.flagErrorFunc == nil {
+		c.flagErrorFunc = p.flagErrorFunc
+	}
+	if c.globNormFunc == nil {
+		c.globNormFunc = p.globNormFunc
+	}
+	if c.recoverFunc == nil {
+		c.recoverFunc = p.recoverFunc
+	}
+	if c.suggester == nil {
+		c.suggester = p.suggester
+	}
+	if c.completionFilter == nil {
+		c.completionFilter = p.completionFilter
+	}
+	if c.metrics == nil {
+		c.metrics = p.metrics
+	}
+	if c.configuration == nil {
+		c.configuration = p.configuration
+	}
+	if c.inReader == nil {
+		c.inReader = p.inReader
+	}
+	if c.outWriter == nil {
+		c.outWriter = p.outWriter
+	}
+	if c.errWriter == nil {
+		c.errWriter = p.errWriter
+	}
+	c.Experimental = c.Experimental || p.Experimental
+	if c.DeprecationPolicy.IsZero() {
+		c.DeprecationPolicy = p.DeprecationPolicy
+	}
+	if c.Version == "" {
+		c.Version = p.Version
+	}
+	for k, v := range p.Annotations {
+		// The display name and help topic annotations only apply to the command setting them.
+		if _, ok := c.Annotations[k]; ok || k == CommandDisplayNameAnnotation || k == helpTopicAnnotation {
+			continue
+		}
+		c.SetAnnotation(k, v)
+	}
+	c.CompletionOptions.DisableCompletion = c.CompletionOptions.DisableCompletion || p.CompletionOptions.DisableCompletion
+	c.CompletionOptions.NoFileCompletion = c.CompletionOptions.NoFileCompletion || p.CompletionOptions.NoFileCompletion
+	if c.CompletionOptions.Timeout <= 0 {
+		c.CompletionOptions.Timeout = p.CompletionOptions.Timeout
+	}
+	if !p.HasParent() {
+		// The options of the default 'completion' command are read on the root only.
+		c.CompletionOptions.DisableDefaultCmd = p.CompletionOptions.DisableDefaultCmd
+		c.CompletionOptions.DisableNoDescFlag = p.CompletionOptions.DisableNoDescFlag
+		c.CompletionOptions.DisableDescriptions = p.CompletionOptions.DisableDescriptions
+		c.CompletionOptions.HiddenDefaultCmd = p.CompletionOptions.HiddenDefaultCmd
+	}
+	if c.execOptions == nil {
+		c.execOptions = p.execOptions
+	}
+	c.DisableSuggestions = c.DisableSuggestions || p.DisableSuggestions
+	if c.SuggestionsMinimumDistance <= 0 {
+		c.SuggestionsMinimumDistance = p.SuggestionsMinimumDistance
+	}
+	if c.helpWidth == 0 {
+		c.helpWidth = p.helpWidth
+	}
+	if c.flagsInterspersed == nil {
+		c.flagsInterspersed = p.flagsInterspersed
+	}
+	if c.interactive == nil {
+		c.interactive = p.interactive
+	}
+	if c.telemetry == nil {
+		c.telemetry = p.telemetry
+	}
+	if c.unknownCommandFunc == nil {
+		c.unknownCommandFunc = p.unknownCommandFunc
+	}
+	if c.pluginOptions == nil {
+		c.pluginOptions = p.pluginOptions
+	}
+}
+
+// inheritRunHooks sets the persistent run hooks of c from those of p, as they run
+// when p is a parent of c: only those of the closest command defining some, or,
+// if traverseRunHooks is set, all of them from the root down.
+func (c *Command) inheritRunHooks(p *Command, traverseRunHooks bool) {
+	pre := runHookE(p.PersistentPreRunE, p.PersistentPreRun)
+	post := runHookE(p.PersistentPostRunE, p.PersistentPostRun)
+	if !traverseRunHooks {
+		if c.PersistentPreRunE == nil && c.PersistentPreRun == nil {
+			c.PersistentPreRunE, c.PersistentPreRun = p.PersistentPreRunE, p.PersistentPreRun
+		}
+		if c.PersistentPostRunE == nil && c.PersistentPostRun == nil {
+			c.PersistentPostRunE, c.PersistentPostRun = p.PersistentPostRunE, p.PersistentPostRun
+		}
+		return
+	}
+	if own := runHookE(c.PersistentPreRunE, c.PersistentPreRun); pre != nil {
+		c.PersistentPreRunE, c.PersistentPreRun = chainRunHooks(pre, own), nil
+	}
+	if own := runHookE(c.PersistentPostRunE, c.PersistentPostRun); post != nil {
+		c.PersistentPostRunE, c.PersistentPostRun = chainRunHooks(own, post), nil
+	}
+}
+
+// runHookE returns the hook run for a pair of hooks, the one returning an error
+// taking precedence, or nil if neither is set.
+func runHookE(hookE func(*Command, []string) error, hook func(*Command, []string)) func(*Command, []string) error {
+	if hookE != nil {
+		return hookE
+	}
+	if hook != nil {
+		return func(cmd *Command, args []string) error {
+			hook(cmd, args)
+			return nil
+		}
+	}
+	return nil
+}
+
+// chainRunHooks returns a hook running first and then, if it succeeds, next,
+// either of which may be nil.
+func chainRunHooks(first, next func(*Command, []string) error) func(*Command, []string) error {
+	if first == nil {
+		return next
+	}
+	if next == nil {
+		return first
+	}
+	return func(cmd *Command, args []string) error {
+		if err := first(cmd, args); err != nil {
+			return err
+		}
+		return next(cmd, args)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtract(t *testing.T) {
+	var verbose bool
+	var name string
+	var gotArgs []string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().String("profile", "", "profile to use")
+	rootCmd.SetUsageFooter("Learn more at https://example.com")
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", func(*Command, []string, string) ([]string, ShellCompDirective) {
+		return []string{"dev", "prod"}, ShellCompDirectiveNoFileComp
+	})
+
+	remoteCmd := &Command{Use: "remote", Short: "Manage remotes"}
+	remoteCmd.AddGroup(&Group{ID: "edit", Title: "Edit Commands:"})
+	addCmd := &Command{
+		Use:     "add",
+		GroupID: "edit",
+		SeeAlso: []string{"root remote list", "root other"},
+		Run:     func(_ *Command, args []string) { gotArgs = args },
+	}
+	addCmd.Flags().StringVar(&name, "name", "", "name of the remote")
+	listCmd := &Command{Use: "list", GroupID: "edit", Run: emptyRun}
+	remoteCmd.AddCommand(addCmd, listCmd)
+	rootCmd.AddCommand(remoteCmd, &Command{Use: "other", Run: emptyRun})
+
+	extracted, err := Extract(rootCmd, "remote")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if extracted.HasParent() || extracted.Name() != "remote" || len(extracted.Commands()) != 2 {
+		t.Fatalf("Expected a detached copy of 'remote' with two sub-commands, got %q with parent %v", extracted.CommandPath(), extracted.HasParent())
+	}
+	extractedAdd := extracted.Commands()[0]
+	if extractedAdd == addCmd || !reflect.DeepEqual(extractedAdd.SeeAlso, []string{"remote list"}) {
+		t.Errorf("Expected a copy of 'add' referring to 'remote list' only, got %v", extractedAdd.SeeAlso)
+	}
+
+	if _, err := executeCommand(extracted, "add", "--name", "origin", "-v", "url"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "origin" || !verbose || !reflect.DeepEqual(gotArgs, []string{"url"}) {
+		t.Errorf("Expected the flags to be bound to the original variables, got name %q, verbose %v, args %v", name, verbose, gotArgs)
+	}
+	if addCmd.Flags().Lookup("name").Changed || rootCmd.PersistentFlags().Lookup("verbose").Changed {
+		t.Error("Expected the flags of the original tree not to be changed")
+	}
+	if len(rootCmd.Commands()) != 2 || remoteCmd.Parent() != rootCmd {
+		t.Error("Expected the original tree not to be modified")
+	}
+
+	output, err := executeCommand(extracted, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "remote [command] --help")
+	checkStringContains(t, output, "Edit Commands:")
+	checkStringContains(t, output, "Learn more at https://example.com")
+	checkStringOmits(t, output, "root")
+
+	output, err = executeCommand(extracted, ShellCompRequestCmd, "list", "--profile", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "dev\nprod\n")
+}
+
+func TestExtractRoot(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "child", Run: emptyRun})
+
+	extracted, err := Extract(rootCmd, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if extracted == rootCmd || extracted.Name() != "root" || len(extracted.Commands()) != 1 || extracted.Commands()[0] == rootCmd.Commands()[0] {
+		t.Error("Expected a copy of the whole tree")
+	}
+}
+
+func TestExtractUnknownCommand(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	_, err := Extract(rootCmd, "missing sub")
+	if err == nil || !strings.Contains(err.Error(), `cannot extract "missing sub": no such command in "root"`) {
+		t.Errorf("Expected an error for an unknown command, got %v", err)
+	}
+}
+
+func TestExtractInheritedSettings(t *testing.T) {
+	var ran []string
+	record := func(name string) func(*Command, []string) {
+		return func(*Command, []string) { ran = append(ran, name) }
+	}
+	testCases := []struct {
+		name  string
+		set   func(root, parent *Command)
+		check func(t *testing.T, cp *Command)
+	}{
+		{"Experimental", func(_, parent *Command) { parent.Experimental = true }, func(t *testing.T, cp *Command) {
+			var experimentalErr *ExperimentalCommandError
+			if _, err := executeCommand(cp, "sub"); !errors.As(err, &experimentalErr) {
+				t.Errorf("Expected an ExperimentalCommandError, got %v", err)
+			}
+		}},
+		{"DeprecationPolicy", func(_, parent *Command) {
+			parent.DeprecationPolicy = DeprecationPolicy{HardAfter: "v2.0.0"}
+		}, func(t *testing.T, cp *Command) {
+			if cp.deprecationPolicy().HardAfter != "v2.0.0" {
+				t.Errorf("Expected the deprecation policy of the parent, got %+v", cp.deprecationPolicy())
+			}
+		}},
+		{"Version", func(root, _ *Command) { root.Version = "1.0" }, func(t *testing.T, cp *Command) {
+			if cp.version() != "1.0" {
+				t.Errorf("Expected the version of the root, got %q", cp.version())
+			}
+		}},
+		{"Annotations", func(_, parent *Command) { parent.SetAnnotation("team", "infra") }, func(t *testing.T, cp *Command) {
+			if value, _ := cp.Annotation("team"); value != "infra" {
+				t.Errorf("Expected the annotation of the parent, got %q", value)
+			}
+		}},
+		{"CompletionOptions.DisableCompletion", func(_, parent *Command) {
+			parent.CompletionOptions.DisableCompletion = true
+		}, func(t *testing.T, cp *Command) {
+			if !cp.Commands()[0].completionDisabled() {
+				t.Error("Expected completion to be disabled")
+			}
+		}},
+		{"CompletionOptions.NoFileCompletion", func(_, parent *Command) {
+			parent.CompletionOptions.NoFileCompletion = true
+		}, func(t *testing.T, cp *Command) {
+			if !cp.Commands()[0].noFileCompletion() {
+				t.Error("Expected file completion to be turned off")
+			}
+		}},
+		{"CompletionOptions.Timeout", func(_, parent *Command) {
+			parent.CompletionOptions.Timeout = time.Second
+		}, func(t *testing.T, cp *Command) {
+			if cp.completionTimeout() != time.Second {
+				t.Errorf("Expected the completion timeout of the parent, got %v", cp.completionTimeout())
+			}
+		}},
+		{"CompletionOptions.HiddenDefaultCmd", func(root, _ *Command) {
+			root.CompletionOptions.HiddenDefaultCmd = true
+		}, func(t *testing.T, cp *Command) {
+			if !cp.CompletionOptions.HiddenDefaultCmd {
+				t.Error("Expected the completion options of the root")
+			}
+		}},
+		{"ExecOptions", func(_, parent *Command) {
+			opts := DefaultExecOptions()
+			opts.PrefixMatching = true
+			parent.SetExecOptions(opts)
+		}, func(t *testing.T, cp *Command) {
+			if _, err := executeCommand(cp, "su"); err != nil || !reflect.DeepEqual(ran, []string{"sub"}) {
+				t.Errorf("Expected the prefix to run 'sub', got %v and error %v", ran, err)
+			}
+		}},
+		{"DisableSuggestions", func(_, parent *Command) { parent.DisableSuggestions = true }, func(t *testing.T, cp *Command) {
+			if !cp.suggestionsDisabled() {
+				t.Error("Expected suggestions to be disabled")
+			}
+		}},
+		{"SuggestionsMinimumDistance", func(_, parent *Command) { parent.SuggestionsMinimumDistance = 4 }, func(t *testing.T, cp *Command) {
+			if cp.suggestionsMinimumDistance() != 4 {
+				t.Errorf("Expected the distance of the parent, got %d", cp.suggestionsMinimumDistance())
+			}
+		}},
+		{"HelpWidth", func(_, parent *Command) { parent.SetHelpWidth(50) }, func(t *testing.T, cp *Command) {
+			if cp.HelpWidth() != 50 {
+				t.Errorf("Expected the help width of the parent, got %d", cp.HelpWidth())
+			}
+		}},
+		{"FlagsInterspersed", func(_, parent *Command) { parent.SetFlagsInterspersed(false) }, func(t *testing.T, cp *Command) {
+			if interspersed, ok := cp.flagsInterspersedMode(); interspersed || !ok {
+				t.Error("Expected flags not to be interspersed")
+			}
+		}},
+		{"Interactive", func(_, parent *Command) { parent.SetInteractive(true) }, func(t *testing.T, cp *Command) {
+			if !cp.isInteractive() {
+				t.Error("Expected the command to be interactive")
+			}
+		}},
+		{"Telemetry", func(_, parent *Command) {
+			parent.SetTelemetry(TelemetryFunc(func(e TelemetryEvent) { ran = append(ran, e.CommandPath) }))
+		}, func(t *testing.T, cp *Command) {
+			if _, err := executeCommand(cp, "sub"); err != nil || !reflect.DeepEqual(ran, []string{"sub", "leaf sub"}) {
+				t.Errorf("Expected the execution to be sent to the telemetry of the parent, got %v and error %v", ran, err)
+			}
+		}},
+		{"UnknownCommandFunc", func(_, parent *Command) {
+			parent.SetUnknownCommandFunc(func(cmd *Command, args []string) (*Command, []string, error) {
+				return cmd.Find([]string{"sub"})
+			})
+		}, func(t *testing.T, cp *Command) {
+			if _, err := executeCommand(cp, "missing"); err != nil || !reflect.DeepEqual(ran, []string{"sub"}) {
+				t.Errorf("Expected the unknown command function of the parent to run 'sub', got %v and error %v", ran, err)
+			}
+		}},
+		{"PluginOptions", func(root, _ *Command) { root.EnablePlugins(PluginOptions{Prefix: "app-"}) }, func(t *testing.T, cp *Command) {
+			if cp.pluginPrefix() != "app-" {
+				t.Errorf("Expected the plugin options of the root, got prefix %q", cp.pluginPrefix())
+			}
+		}},
+		{"RenamedCommands", func(root, _ *Command) {
+			if err := RenameCommand(root, "group leaf old", "group leaf sub"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}, func(t *testing.T, cp *Command) {
+			if _, err := executeCommand(cp, "old"); err != nil || !reflect.DeepEqual(ran, []string{"sub"}) {
+				t.Errorf("Expected the old name to run 'sub', got %v and error %v", ran, err)
+			}
+		}},
+		{"PersistentRunHooks", func(root, parent *Command) {
+			root.PersistentPreRun = record("root pre")
+			parent.PersistentPreRun = record("parent pre")
+			parent.PersistentPostRun = record("parent post")
+		}, func(t *testing.T, cp *Command) {
+			if _, err := executeCommand(cp, "sub"); err != nil || !reflect.DeepEqual(ran, []string{"parent pre", "sub", "parent post"}) {
+				t.Errorf("Expected the closest hooks to run, got %v and error %v", ran, err)
+			}
+		}},
+		{"TraverseRunHooks", func(root, parent *Command) {
+			opts := DefaultExecOptions()
+			opts.TraverseRunHooks = true
+			root.SetExecOptions(opts)
+			root.PersistentPreRun = record("root pre")
+			parent.PersistentPreRun = record("parent pre")
+			parent.PersistentPostRun = record("parent post")
+			parent.Commands()[0].PersistentPostRun = record("leaf post")
+		}, func(t *testing.T, cp *Command) {
+			if _, err := executeCommand(cp, "sub"); err != nil ||
+				!reflect.DeepEqual(ran, []string{"root pre", "parent pre", "sub", "leaf post", "parent post"}) {
+				t.Errorf("Expected all the hooks to run, got %v and error %v", ran, err)
+			}
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ran = nil
+			rootCmd := &Command{Use: "root", Run: emptyRun}
+			groupCmd := &Command{Use: "group"}
+			leafCmd := &Command{Use: "leaf"}
+			leafCmd.AddCommand(&Command{Use: "sub", Run: record("sub")})
+			groupCmd.AddCommand(leafCmd)
+			rootCmd.AddCommand(groupCmd)
+			tc.set(rootCmd, groupCmd)
+
+			extracted, err := Extract(rootCmd, "group leaf")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tc.check(t, extracted)
+		})
+	}
+}
+
+func TestExtractIsIndependent(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	leafCmd := &Command{
+		Use:         "leaf",
+		Aliases:     make([]string, 1, 10),
+		Annotations: map[string]string{"key": "value"},
+		Run:         emptyRun,
+	}
+	leafCmd.Aliases[0] = "l"
+	leafCmd.Flags().String("name", "", "")
+	leafCmd.AddGroup(&Group{ID: "group", Title: "Group:"})
+	leafCmd.AddCommand(&Command{Use: "sub", Run: emptyRun})
+	leafCmd.middlewares = make([]Middleware, 0, 10)
+	Use(leafCmd, func(next RunFunc) RunFunc { return next })
+	rootCmd.AddCommand(leafCmd)
+
+	extracted, err := Extract(rootCmd, "leaf")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	extracted.Aliases = append(extracted.Aliases, "other")
+	extracted.Annotations["key"] = "changed"
+	extracted.Flags().String("other", "", "")
+	extracted.Groups()[0].Title = "Changed:"
+	extracted.Commands()[0].Use = "changed"
+	extracted.AddCommand(&Command{Use: "added", Run: emptyRun})
+	Use(extracted, func(next RunFunc) RunFunc { return next })
+
+	if got := leafCmd.Aliases[:cap(leafCmd.Aliases)][1]; got != "" {
+		t.Errorf("Expected the aliases of the original to be unchanged, got %q", got)
+	}
+	if leafCmd.Annotations["key"] != "value" {
+		t.Errorf("Expected the annotations of the original to be unchanged, got %v", leafCmd.Annotations)
+	}
+	if leafCmd.Flags().Lookup("other") != nil {
+		t.Error("Expected the flags of the original to be unchanged")
+	}
+	if leafCmd.Groups()[0].Title != "Group:" {
+		t.Errorf("Expected the groups of the original to be unchanged, got %q", leafCmd.Groups()[0].Title)
+	}
+	if len(leafCmd.Commands()) != 1 || leafCmd.Commands()[0].Use != "sub" {
+		t.Errorf("Expected the sub-commands of the original to be unchanged, got %v", leafCmd.Commands())
+	}
+	if len(leafCmd.middlewares) != 1 || leafCmd.middlewares[:2][1] != nil {
+		t.Error("Expected the middlewares of the original to be unchanged")
+	}
+}
+
+// TestExtractCopiesAllFields fails when a field is added to Command, so that it is
+// also handled by Extract: copied by clone, inherited from the parents by
+// inheritSettings if it is a setting, or deliberately left out.
+func TestExtractCopiesAllFields(t *testing.T) {
+	known := map[string]bool{
+		// Copied by clone.
+		"Use": true, "DisplayName": true, "Aliases": true, "SuggestFor": true, "Short": true,
+		"GroupID": true, "Long": true, "Example": true, "Examples": true, "DocsURL": true,
+		"SeeAlso": true, "Params": true, "ArgNames": true, "ValidArgs": true,
+		"ValidArgsFunction": true, "Args": true, "ArgAliases": true, "BashCompletionFunction": true,
+		"Deprecated": true, "DeprecationPolicy": true, "Experimental": true, "Timeout": true,
+		"Annotations": true, "Version": true, "OwnVersionFlag": true,
+		"PersistentPreRun": true, "PersistentPreRunE": true, "PreRun": true, "PreRunE": true,
+		"Run": true, "RunE": true, "PostRun": true, "PostRunE": true,
+		"PersistentPostRun": true, "PersistentPostRunE": true,
+		"FParseErrWhitelist": true, "CompletionOptions": true, "TraverseChildren": true,
+		"DropEmptyArgs": true, "BufferOutput": true, "Hidden": true, "SilenceErrors": true,
+		"SilenceUsage": true, "DisableFlagParsing": true, "DisableAutoGenTag": true,
+		"DisableFlagsInUseLine": true, "DisableSuggestions": true, "SuggestionsMinimumDistance": true,
+		"commandgroups": true, "envPrefix": true, "configuration": true, "ephemeralCommands": true,
+		"flags": true, "pflags": true, "globNormFunc": true, "usageFunc": true, "suggester": true,
+		"unknownCommandFunc": true, "defaultCommand": true, "renamedCommands": true,
+		"completionFilter": true, "metrics": true, "telemetry": true, "usageTemplate": true,
+		"flagErrorFunc": true, "helpTemplate": true, "usageFooter": true, "helpBanner": true,
+		"helpFunc": true, "helpCommand": true, "helpCommandGroupID": true,
+		"completionCommandGroupID": true, "versionTemplate": true, "versionInfo": true,
+		"interactive": true, "lazyLoad": true, "execOptions": true, "helpWidth": true,
+		"flagsInterspersed": true, "middlewares": true, "recoverFunc": true, "onInitialize": true,
+		"onFinalize": true, "commandProviders": true, "pluginOptions": true, "aliasExpansions": true,
+		"eventHandlers": true, "errPrefix": true, "inReader": true, "outWriter": true,
+		"errWriter": true, "flagCompletions": true, "flagValidators": true, "argCompletions": true,
+		"commands": true,
+		// The state of an execution, or derived from the copied fields.
+		"args": true, "rawArgs": true, "params": true, "namedArgs": true, "flagSources": true,
+		"configFileUsed": true, "flagErrorBuf": true, "lflags": true, "iflags": true,
+		"flagSetSizes": true, "parentsPflags": true, "flagsCaseFolded": true, "renamedCommandsUsed": true,
+		"commandIndex": true, "commandsAreSorted": true, "commandCalledAs": true,
+		"resolvedBy": true, "ctx": true, "parent": true, "commandsMaxUseLen": true,
+		"commandsMaxCommandPathLen": true, "commandsMaxNameLen": true,
+	}
+	typ := reflect.TypeOf(Command{})
+	for i := 0; i < typ.NumField(); i++ {
+		if name := typ.Field(i).Name; !known[name] {
+			t.Errorf("Command.%s is not handled by Extract: update clone and inheritSettings in extract.go, and this test", name)
+		}
+	}
+}
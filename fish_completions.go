@@ -74,9 +74,18 @@ function __%[1]s_perform_completion
     set -l comps $results[1..-2]
     set -l directiveLine $results[-1]
 
+    set -l directive (string sub --start 2 $directiveLine)
+    set -l shellCompDirectiveShellCommand %[11]d
+    if test -n "$directive"; and test (math (math --scale 0 $directive / $shellCompDirectiveShellCommand) %% 2) -eq 1
+        # The completions are the lines printed by the shell command
+        __%[1]s_debug "Executing shell command: $comps[1]"
+        set comps (eval $comps[1] 2> /dev/null)
+    end
+
     # For Fish, when completing a flag with an = (e.g., <program> -n=<TAB>)
-    # completions must be prefixed with the flag
-    set -l flagPrefix (string match -r -- '-.*=' "$lastArg")
+    # completions must be prefixed with the flag.  Only the part up to the
+    # first = is the flag, the value itself may contain = (e.g., --selector=app=foo)
+    set -l flagPrefix (string match -r -- '^-[^=]*=' "$lastArg")
 
     __%[1]s_debug "Comps: $comps"
     __%[1]s_debug "DirectiveLine: $directiveLine"
@@ -269,7 +278,8 @@ complete -c %[2]s -n 'not __%[1]s_requires_order_preservation && __%[1]s_prepare
 complete -k -c %[2]s -n '__%[1]s_requires_order_preservation && __%[1]s_prepare_completions' -f -a '$__%[1]s_comp_results'
 `, nameForVar, name, compCmd,
 		ShellCompDirectiveError, ShellCompDirectiveNoSpace, ShellCompDirectiveNoFileComp,
-		ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs, ShellCompDirectiveKeepOrder, activeHelpEnvVar(name)))
+		ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs, ShellCompDirectiveKeepOrder, activeHelpEnvVar(name),
+		ShellCompDirectiveShellCommand))
 }
 
 // GenFishCompletion generates fish completion file and writes to the passed writer.
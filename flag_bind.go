@@ -0,0 +1,89 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+
+	flag "github.com/spf13/pflag"
+)
+
+// addFlagValidator registers on c a validator of the flag f, which is defined
+// by one of the flag sets of c.
+func (c *Command) addFlagValidator(f *flag.Flag, validate func() error) {
+	if c.flagValidators == nil {
+		c.flagValidators = map[*flag.Flag][]func() error{}
+	}
+	c.flagValidators[f] = append(c.flagValidators[f], validate)
+}
+
+// ValidateFlagValues runs the validators registered through BindFlag for all the
+// flags of the command and returns the first error encountered.
+func (c *Command) ValidateFlagValues() error {
+	if c.DisableFlagParsing {
+		return nil
+	}
+
+	var err error
+	c.Flags().VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		// The validators of persistent flags are registered on the parent defining them.
+		var validators []func() error
+		for p := c; p != nil && validators == nil; p = p.parent {
+			validators = p.flagValidators[f]
+		}
+		for _, validate := range validators {
+			if verr := validate(); verr != nil {
+				err = fmt.Errorf("invalid value %q for flag %q: %w", f.Value.String(), f.Name, verr)
+				return
+			}
+		}
+	})
+	return err
+}
+
+// FlagSource describes where the value of a flag comes from.
+type FlagSource string
+
+const (
+	// FlagSourceDefault indicates the flag holds its default value.
+	FlagSourceDefault FlagSource = "default"
+	// FlagSourceCommandLine indicates the flag was set on the command-line.
+	FlagSourceCommandLine FlagSource = "command-line"
+	// FlagSourceEnv indicates the flag was set from its environment variable.
+	FlagSourceEnv FlagSource = "env"
+	// FlagSourceConfig indicates the flag was set from the configuration file.
+	FlagSourceConfig FlagSource = "config"
+	// FlagSourcePrompt indicates the flag was set from the answer to a prompt.
+	FlagSourcePrompt FlagSource = "prompt"
+)
+
+// FlagSource returns where the value of the named flag comes from,
+// or an empty string if the command has no such flag.
+func (c *Command) FlagSource(name string) FlagSource {
+	f := c.Flag(name)
+	if f == nil {
+		return ""
+	}
+	if source, ok := c.flagSources[f]; ok {
+		return source
+	}
+	if f.Changed {
+		return FlagSourceCommandLine
+	}
+	return FlagSourceDefault
+}
@@ -0,0 +1,134 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package cobra
+
+import (
+	"fmt"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+// FlagType is the set of the types of variables which can be bound to a flag with BindFlag.
+type FlagType interface {
+	string | bool | int | int32 | int64 | uint | uint32 | uint64 | float32 | float64 |
+		time.Duration | []string | []int | []bool
+}
+
+// FlagValidator validates the value of a flag bound using BindFlag.  It receives
+// the value of the variable the flag is bound to.
+type FlagValidator[T FlagType] func(value T) error
+
+// BindFlag defines a flag with the specified name, shorthand, default value and usage
+// on the local flags of cmd, and binds it to the variable p points to.
+// Contrary to the type-specific pflag functions, the type of the flag is inferred from p,
+// and the default value and the validators are checked against it at compile time.
+// The validators are run, in order, against the parsed value of the flag when the
+// command is executed; the first error returned aborts the execution.
+// The shorthand may be empty.
+func BindFlag[T FlagType](cmd *Command, p *T, name, shorthand string, value T, usage string, validators ...FlagValidator[T]) *flag.Flag {
+	return bindFlag(cmd, cmd.Flags(), p, name, shorthand, value, usage, validators)
+}
+
+// BindPersistentFlag is like BindFlag but defines the flag on the persistent
+// flags of cmd so that it is also available to its children.
+func BindPersistentFlag[T FlagType](cmd *Command, p *T, name, shorthand string, value T, usage string, validators ...FlagValidator[T]) *flag.Flag {
+	return bindFlag(cmd, cmd.PersistentFlags(), p, name, shorthand, value, usage, validators)
+}
+
+// bindFlag defines the flag on flags, one of the flag sets of c, and registers its
+// validators on c.
+func bindFlag[T FlagType](c *Command, flags *flag.FlagSet, p *T, name, shorthand string, value T, usage string, validators []FlagValidator[T]) *flag.Flag {
+	switch ptr := any(p).(type) {
+	case *string:
+		flags.StringVarP(ptr, name, shorthand, any(value).(string), usage)
+	case *bool:
+		flags.BoolVarP(ptr, name, shorthand, any(value).(bool), usage)
+	case *int:
+		flags.IntVarP(ptr, name, shorthand, any(value).(int), usage)
+	case *int32:
+		flags.Int32VarP(ptr, name, shorthand, any(value).(int32), usage)
+	case *int64:
+		flags.Int64VarP(ptr, name, shorthand, any(value).(int64), usage)
+	case *uint:
+		flags.UintVarP(ptr, name, shorthand, any(value).(uint), usage)
+	case *uint32:
+		flags.Uint32VarP(ptr, name, shorthand, any(value).(uint32), usage)
+	case *uint64:
+		flags.Uint64VarP(ptr, name, shorthand, any(value).(uint64), usage)
+	case *float32:
+		flags.Float32VarP(ptr, name, shorthand, any(value).(float32), usage)
+	case *float64:
+		flags.Float64VarP(ptr, name, shorthand, any(value).(float64), usage)
+	case *time.Duration:
+		flags.DurationVarP(ptr, name, shorthand, any(value).(time.Duration), usage)
+	case *[]string:
+		flags.StringSliceVarP(ptr, name, shorthand, any(value).([]string), usage)
+	case *[]int:
+		flags.IntSliceVarP(ptr, name, shorthand, any(value).([]int), usage)
+	case *[]bool:
+		flags.BoolSliceVarP(ptr, name, shorthand, any(value).([]bool), usage)
+	}
+
+	f := flags.Lookup(name)
+	for _, validator := range validators {
+		validator := validator
+		c.addFlagValidator(f, func() error { return validator(*p) })
+	}
+	return f
+}
+
+// orderedFlagType is the set of the flag types whose values are ordered.
+type orderedFlagType interface {
+	string | int | int32 | int64 | uint | uint32 | uint64 | float32 | float64 | time.Duration
+}
+
+// FlagInRange returns a FlagValidator which fails if the value is not within [min, max].
+func FlagInRange[T orderedFlagType](min, max T) FlagValidator[T] {
+	return func(value T) error {
+		if value < min || value > max {
+			return fmt.Errorf("must be between %v and %v", min, max)
+		}
+		return nil
+	}
+}
+
+// comparableFlagType is the set of the flag types whose values can be compared.
+type comparableFlagType interface {
+	orderedFlagType | bool
+}
+
+// FlagOneOf returns a FlagValidator which fails if the value is not one of the allowed values.
+func FlagOneOf[T comparableFlagType](allowed ...T) FlagValidator[T] {
+	return func(value T) error {
+		for _, a := range allowed {
+			if value == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %v", allowed)
+	}
+}
+
+// FlagNotEmpty is a FlagValidator which fails if a string or slice flag is empty.
+func FlagNotEmpty[T string | []string | []int | []bool](value T) error {
+	if len(value) == 0 {
+		return fmt.Errorf("must not be empty")
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package cobra
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBindFlag(t *testing.T) {
+	var cfg struct {
+		Port    int
+		Host    string
+		Verbose bool
+		Timeout time.Duration
+		Tags    []string
+	}
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	BindFlag(rootCmd, &cfg.Port, "port", "p", 8080, "port to listen on")
+	BindFlag(rootCmd, &cfg.Host, "host", "", "localhost", "host to bind")
+	BindFlag(rootCmd, &cfg.Verbose, "verbose", "v", false, "verbose output")
+	BindPersistentFlag(rootCmd, &cfg.Timeout, "timeout", "", time.Second, "timeout")
+	BindFlag(rootCmd, &cfg.Tags, "tag", "t", nil, "tags", FlagNotEmpty[[]string])
+
+	if cfg.Port != 8080 || cfg.Host != "localhost" || cfg.Timeout != time.Second {
+		t.Errorf("defaults were not applied: %+v", cfg)
+	}
+	if rootCmd.PersistentFlags().Lookup("timeout") == nil {
+		t.Errorf("expected timeout to be a persistent flag")
+	}
+
+	_, err := executeCommand(rootCmd, "-p", "9090", "--verbose", "--timeout", "5s", "-t", "a,b")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if cfg.Port != 9090 || !cfg.Verbose || cfg.Timeout != 5*time.Second || !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected bound values: %+v", cfg)
+	}
+	if src := rootCmd.FlagSource("port"); src != FlagSourceCommandLine {
+		t.Errorf("expected port source %q, got %q", FlagSourceCommandLine, src)
+	}
+	if src := rootCmd.FlagSource("host"); src != FlagSourceDefault {
+		t.Errorf("expected host source %q, got %q", FlagSourceDefault, src)
+	}
+	if src := rootCmd.FlagSource("unknown"); src != "" {
+		t.Errorf("expected no source for unknown flag, got %q", src)
+	}
+}
+
+func TestBindFlagValidators(t *testing.T) {
+	getCmd := func() *Command {
+		var port uint
+		var mode string
+		var level int32
+		c := &Command{Use: "root", Run: emptyRun}
+		BindFlag(c, &port, "port", "p", 8080, "port", FlagInRange[uint](1, 65535))
+		BindFlag(c, &mode, "mode", "", "fast", "mode", FlagNotEmpty[string], FlagOneOf("fast", "slow"))
+		BindFlag(c, &level, "level", "", 1, "level", FlagOneOf[int32](1, 2, 3))
+		return c
+	}
+
+	testcases := []struct {
+		desc        string
+		args        []string
+		expectedErr string
+	}{
+		{desc: "defaults are valid", args: []string{}},
+		{desc: "valid values", args: []string{"--port", "443", "--mode", "slow"}},
+		{desc: "out of range", args: []string{"--port", "0"}, expectedErr: `invalid value "0" for flag "port": must be between 1 and 65535`},
+		{desc: "first validator fails", args: []string{"--mode", ""}, expectedErr: `invalid value "" for flag "mode": must not be empty`},
+		{desc: "second validator fails", args: []string{"--mode", "medium"}, expectedErr: `invalid value "medium" for flag "mode": must be one of [fast slow]`},
+		{desc: "typed allowed values", args: []string{"--level", "4"}, expectedErr: `invalid value "4" for flag "level": must be one of [1 2 3]`},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.desc, func(t *testing.T) {
+			c := getCmd()
+			c.SilenceUsage = true
+			_, err := executeCommand(c, tc.args...)
+			switch {
+			case err == nil && tc.expectedErr != "":
+				t.Errorf("expected error %q but got nil", tc.expectedErr)
+			case err != nil && err.Error() != tc.expectedErr:
+				t.Errorf("expected error %q but got %q", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestBindFlagValidatorsPerCommand(t *testing.T) {
+	var port int
+	rootCmd := &Command{Use: "root"}
+	BindPersistentFlag(rootCmd, &port, "port", "", 8080, "port", FlagInRange(1, 65535))
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	rootCmd.AddCommand(childCmd)
+
+	if _, err := executeCommand(rootCmd, "child", "--port", "0"); err == nil {
+		t.Error("Expected the validator of the persistent flag to run for the child")
+	}
+	if len(childCmd.flagValidators) != 0 || len(rootCmd.flagValidators) != 1 {
+		t.Errorf("Expected the validator to be registered on the command defining the flag")
+	}
+
+	extracted, err := Extract(rootCmd, "child")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := executeCommand(extracted, "--port", "0"); err == nil {
+		t.Error("Expected the validator to run for the extracted command")
+	}
+	if _, err := executeCommand(extracted, "--port", "80"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(rootCmd.flagValidators[rootCmd.PersistentFlags().Lookup("port")]) != 1 {
+		t.Error("Expected the validators of the original tree to be unchanged")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	flag "github.com/spf13/pflag"
+)
+
+// flagSetSize is a flag set and its number of flags.  Flags cannot be removed from
+// a flag set, so a set with the same size has the same flags.
+type flagSetSize struct {
+	set  *flag.FlagSet
+	size int
+}
+
+// flagSetsChanged reports whether flags were added to the flag sets LocalFlags and
+// InheritedFlags are built from, i.e. the flags of c and the persistent flags of its
+// parents, or whether these sets were replaced, e.g. by ResetFlags or by moving c to
+// another parent, since the previous call.
+func (c *Command) flagSetsChanged() bool {
+	changed := false
+	i := 0
+	record := func(set *flag.FlagSet) {
+		size := 0
+		set.VisitAll(func(*flag.Flag) { size++ })
+		current := flagSetSize{set: set, size: size}
+		if i >= len(c.flagSetSizes) || c.flagSetSizes[i] != current {
+			changed = true
+			c.flagSetSizes = append(c.flagSetSizes[:i], current)
+		}
+		i++
+	}
+
+	record(c.Flags())
+	record(c.PersistentFlags())
+	for p := c.parent; p != nil; p = p.parent {
+		record(p.PersistentFlags())
+	}
+	if i != len(c.flagSetSizes) {
+		changed = true
+		c.flagSetSizes = c.flagSetSizes[:i]
+	}
+	return changed
+}
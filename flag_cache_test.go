@@ -0,0 +1,68 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "testing"
+
+func TestLocalFlagsMemoized(t *testing.T) {
+	root := &Command{Use: "root", Run: emptyRun}
+	root.PersistentFlags().Bool("verbose", false, "")
+	child := &Command{Use: "child", Run: emptyRun}
+	child.Flags().String("name", "", "")
+	root.AddCommand(child)
+
+	local, inherited := child.LocalFlags(), child.InheritedFlags()
+	if child.LocalFlags() != local || child.InheritedFlags() != inherited {
+		t.Errorf("Expected the flag sets to be reused while no flag is added")
+	}
+	if local.Lookup("name") == nil || inherited.Lookup("verbose") == nil {
+		t.Errorf("Unexpected flags: local %v, inherited %v", local.FlagUsages(), inherited.FlagUsages())
+	}
+}
+
+func TestLocalFlagsAfterLateRegistration(t *testing.T) {
+	root := &Command{Use: "root", Run: emptyRun}
+	root.PersistentFlags().Bool("verbose", false, "")
+	child := &Command{Use: "child", Run: emptyRun}
+	root.AddCommand(child)
+
+	if child.InheritedFlags().Lookup("verbose") == nil {
+		t.Fatalf("Expected verbose to be inherited")
+	}
+
+	root.PersistentFlags().String("config", "", "")
+	child.Flags().Bool("dry-run", false, "")
+
+	if child.InheritedFlags().Lookup("config") == nil {
+		t.Errorf("Expected the flag added to the parent to be inherited")
+	}
+	if child.LocalFlags().Lookup("dry-run") == nil || child.InheritedFlags().Lookup("dry-run") != nil {
+		t.Errorf("Expected the flag added to the child to be local")
+	}
+}
+
+func TestLocalFlagsAfterResetFlags(t *testing.T) {
+	c := &Command{Use: "c", Run: emptyRun}
+	c.Flags().Bool("old", false, "")
+	if c.LocalFlags().Lookup("old") == nil {
+		t.Fatalf("Expected the old flag")
+	}
+
+	c.ResetFlags()
+	c.Flags().Bool("new", false, "")
+	if c.LocalFlags().Lookup("old") != nil || c.LocalFlags().Lookup("new") == nil {
+		t.Errorf("Unexpected local flags after reset: %v", c.LocalFlags().FlagUsages())
+	}
+}
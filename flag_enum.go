@@ -0,0 +1,61 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// EnumVar defines a flag with the specified name, shorthand and usage on the local
+// flags of cmd, which only accepts one of choices, and binds it to the variable p
+// points to.  Its default value is the current value of the variable.
+// The choices are shown in the help, as in "--mode {fast|safe}", and are the
+// completions of the flag.  The shorthand may be empty.
+func EnumVar(cmd *Command, p *string, name, shorthand string, choices []string, usage string) *flag.Flag {
+	return enumVar(cmd, cmd.Flags(), p, name, shorthand, choices, usage)
+}
+
+// PersistentEnumVar is like EnumVar but defines the flag on the persistent flags
+// of cmd so that it is also available to its children.
+func PersistentEnumVar(cmd *Command, p *string, name, shorthand string, choices []string, usage string) *flag.Flag {
+	return enumVar(cmd, cmd.PersistentFlags(), p, name, shorthand, choices, usage)
+}
+
+func enumVar(cmd *Command, flags *flag.FlagSet, p *string, name, shorthand string, choices []string, usage string) *flag.Flag {
+	flags.VarP(&enumValue{value: p, choices: choices}, name, shorthand, usage)
+	_ = cmd.RegisterFlagCompletionFunc(name, FixedCompletions(choices, ShellCompDirectiveNoFileComp))
+	return flags.Lookup(name)
+}
+
+// enumValue is the value of a flag defined by EnumVar.
+type enumValue struct {
+	value   *string
+	choices []string
+}
+
+func (v *enumValue) String() string { return *v.value }
+
+func (v *enumValue) Type() string { return "{" + strings.Join(v.choices, "|") + "}" }
+
+func (v *enumValue) Set(s string) error {
+	if !stringInSlice(s, v.choices) {
+		return fmt.Errorf("must be one of %s", strings.Join(v.choices, ", "))
+	}
+	*v.value = s
+	return nil
+}
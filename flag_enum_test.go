@@ -0,0 +1,75 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+	"testing"
+)
+
+func newEnumTree(mode *string) *Command {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "child", Run: emptyRun})
+	*mode = "fast"
+	PersistentEnumVar(rootCmd, mode, "mode", "m", []string{"fast", "safe", "dry-run"}, "execution mode")
+	return rootCmd
+}
+
+func TestEnumVar(t *testing.T) {
+	var mode string
+
+	if _, err := executeCommand(newEnumTree(&mode), "child", "--mode", "safe"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if mode != "safe" {
+		t.Errorf("Expected mode to be %q, got %q", "safe", mode)
+	}
+
+	_, err := executeCommand(newEnumTree(&mode), "-m", "slow")
+	if err == nil || !strings.Contains(err.Error(), "must be one of fast, safe, dry-run") {
+		t.Errorf("Expected an invalid choice error, got %v", err)
+	}
+}
+
+func TestEnumVarHelp(t *testing.T) {
+	var mode string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	mode = "fast"
+	EnumVar(rootCmd, &mode, "mode", "", []string{"fast", "safe"}, "execution mode")
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, `--mode {fast|safe}   execution mode (default fast)`)
+}
+
+func TestEnumVarCompletion(t *testing.T) {
+	var mode string
+
+	output, err := executeCommand(newEnumTree(&mode), ShellCompNoDescRequestCmd, "child", "--mode", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"fast",
+		"safe",
+		"dry-run",
+		":4",
+		"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// FlagEnvVarAnnotation is the annotation holding the name of the environment
+// variable a flag falls back to.
+const FlagEnvVarAnnotation = "cobra_annotation_flag_env_var"
+
+// BindEnv makes the named flag of cmd fall back to the environment variable envVar
+// when it is not set on the command-line.  The precedence is: command-line,
+// environment variable, default value.  Empty environment variables are ignored.
+// The name of the variable is shown in the usage of the flag.
+func BindEnv(cmd *Command, flagName, envVar string) error {
+	f := cmd.Flag(flagName)
+	if f == nil {
+		return fmt.Errorf("BindEnv: flag '%s' does not exist", flagName)
+	}
+	bindFlagEnv(f, envVar)
+	return nil
+}
+
+// RequiredUnlessEnv makes the named flag of cmd required unless the environment
+// variable envVar is set, in which case the flag takes its value from it: the flag is
+// mandatory when the program is used interactively, but can be provided by the
+// environment in automation.  Like with BindEnv, the name of the variable is shown
+// in the usage of the flag and FlagSource tells where its value comes from.
+func RequiredUnlessEnv(cmd *Command, flagName, envVar string) error {
+	f := cmd.Flag(flagName)
+	if f == nil {
+		return fmt.Errorf("RequiredUnlessEnv: flag '%s' does not exist", flagName)
+	}
+	bindFlagEnv(f, envVar)
+	f.Annotations[BashCompOneRequiredFlag] = []string{"true"}
+	return nil
+}
+
+// AutomaticEnv makes all the flags of c and its sub-commands which are not bound
+// with BindEnv fall back to the environment variable PREFIX_FLAG_NAME, where the
+// name of the flag is in upper case and its dashes are replaced by underscores.
+// The flags defined by Cobra itself, such as --help, are excluded.
+func (c *Command) AutomaticEnv(prefix string) {
+	c.envPrefix = prefix
+}
+
+// bindFlagEnv records envVar as the environment variable of f.
+func bindFlagEnv(f *flag.Flag, envVar string) {
+	if f.Annotations == nil {
+		f.Annotations = map[string][]string{}
+	}
+	f.Annotations[FlagEnvVarAnnotation] = []string{envVar}
+}
+
+// envVarFlags returns flags, or a copy of it in which the usage of the flags bound
+// to an environment variable ends with the name of the variable, e.g. "[$APP_TOKEN]",
+// for the help.
+func envVarFlags(flags *flag.FlagSet) *flag.FlagSet {
+	bound := false
+	flags.VisitAll(func(f *flag.Flag) {
+		_, ok := f.Annotations[FlagEnvVarAnnotation]
+		bound = bound || ok
+	})
+	if !bound {
+		return flags
+	}
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.SortFlags = flags.SortFlags
+	flags.VisitAll(func(f *flag.Flag) {
+		if envVar, ok := f.Annotations[FlagEnvVarAnnotation]; ok {
+			cp := *f
+			cp.Usage += fmt.Sprintf(" [$%s]", envVar[0])
+			f = &cp
+		}
+		fs.AddFlag(f)
+	})
+	return fs
+}
+
+// automaticEnvPrefix returns the prefix set by AutomaticEnv on the command
+// or its closest parent.
+func (c *Command) automaticEnvPrefix() string {
+	for p := c; p != nil; p = p.Parent() {
+		if p.envPrefix != "" {
+			return p.envPrefix
+		}
+	}
+	return ""
+}
+
+// initAutomaticEnv binds the flags of the tree of c to their environment variable
+// according to AutomaticEnv.
+func (c *Command) initAutomaticEnv() {
+	c.visitTree(func(cmd *Command) {
+		prefix := cmd.automaticEnvPrefix()
+		if prefix == "" {
+			return
+		}
+		bind := func(f *flag.Flag) {
+			if _, ok := f.Annotations[FlagEnvVarAnnotation]; ok {
+				return
+			}
+			if _, ok := f.Annotations[FlagSetByCobraAnnotation]; ok {
+				return
+			}
+			bindFlagEnv(f, strings.ToUpper(strings.ReplaceAll(prefix+"_"+f.Name, "-", "_")))
+		}
+		cmd.LocalFlags().VisitAll(bind)
+	})
+}
+
+// applyEnvFlags sets the flags which were not set on the command-line
+// from their environment variable.
+func (c *Command) applyEnvFlags() error {
+	var err error
+	c.Flags().VisitAll(func(f *flag.Flag) {
+		envVar, ok := f.Annotations[FlagEnvVarAnnotation]
+		if err != nil || !ok || f.Changed {
+			return
+		}
+		value := os.Getenv(envVar[0])
+		if value == "" {
+			return
+		}
+		if setErr := setFlagValue(f, value); setErr != nil {
+			err = fmt.Errorf("invalid value %q for flag %q from environment variable %s: %w", value, f.Name, envVar[0], setErr)
+			return
+		}
+		c.setFlagSource(f, FlagSourceEnv)
+	})
+	return err
+}
+
+// setFlagValue sets the value of f, which is marked as changed, replacing any
+// previous value: slices and arrays are set from comma-separated values.
+func setFlagValue(f *flag.Flag, value string) error {
+	if sv, ok := f.Value.(flag.SliceValue); ok {
+		values, err := csv.NewReader(strings.NewReader(value)).Read()
+		if err != nil {
+			return err
+		}
+		if err := sv.Replace(values); err != nil {
+			return err
+		}
+	} else if err := f.Value.Set(value); err != nil {
+		return err
+	}
+	f.Changed = true
+	return nil
+}
+
+// setFlagSource records that the value of f does not come from the command-line.
+func (c *Command) setFlagSource(f *flag.Flag, source FlagSource) {
+	if c.flagSources == nil {
+		c.flagSources = map[*flag.Flag]FlagSource{}
+	}
+	c.flagSources[f] = source
+}
+
+// resetFlagSources restores the default value of the flags set from other sources
+// than the command-line by a previous execution, so that they can be set again.
+func (c *Command) resetFlagSources() {
+	for f := range c.flagSources {
+		resetFlagValue(f)
+	}
+	c.flagSources = nil
+}
+
+// resetFlagValue sets f back to its default value and marks it as not changed.
+func resetFlagValue(f *flag.Flag) {
+	if sv, ok := f.Value.(flag.SliceValue); ok {
+		// The default value of slices and arrays is formatted as "[a,b]".
+		values, _ := csv.NewReader(strings.NewReader(strings.TrimSuffix(strings.TrimPrefix(f.DefValue, "["), "]"))).Read()
+		_ = sv.Replace(values)
+	} else {
+		_ = f.Value.Set(f.DefValue)
+	}
+	f.Changed = false
+}
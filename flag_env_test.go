@@ -0,0 +1,220 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	flag "github.com/spf13/pflag"
+)
+
+func TestBindEnv(t *testing.T) {
+	var config string
+	var source FlagSource
+	rootCmd := &Command{Use: "root", Run: func(cmd *Command, _ []string) { source = cmd.FlagSource("config") }}
+	rootCmd.Flags().StringVar(&config, "config", "default.yaml", "config file")
+	if err := BindEnv(rootCmd, "config", "MYAPP_CONFIG"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := BindEnv(rootCmd, "unknown", "MYAPP_UNKNOWN"); err == nil {
+		t.Error("Expected an error for an unknown flag")
+	}
+
+	tests := []struct {
+		env, args      string
+		expected       string
+		expectedSource FlagSource
+	}{
+		{"", "", "default.yaml", FlagSourceDefault},
+		{"env.yaml", "", "env.yaml", FlagSourceEnv},
+		{"env.yaml", "cli.yaml", "cli.yaml", FlagSourceCommandLine},
+	}
+	for _, tc := range tests {
+		t.Setenv("MYAPP_CONFIG", tc.env)
+		config = "default.yaml"
+		rootCmd.Flags().Lookup("config").Changed = false
+		args := []string{}
+		if tc.args != "" {
+			args = append(args, "--config", tc.args)
+		}
+		if _, err := executeCommand(rootCmd, args...); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config != tc.expected || source != tc.expectedSource {
+			t.Errorf("env %q, args %q: expected %q from %s, got %q from %s", tc.env, tc.args, tc.expected, tc.expectedSource, config, source)
+		}
+	}
+
+	output, _ := executeCommand(rootCmd, "--help")
+	checkStringContains(t, output, "config file [$MYAPP_CONFIG]")
+}
+
+func TestBindEnvSatisfiesRequiredFlag(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().Int("port", 0, "port")
+	_ = rootCmd.MarkFlagRequired("port")
+	_ = BindEnv(rootCmd, "port", "MYAPP_PORT")
+
+	t.Setenv("MYAPP_PORT", "8080")
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	t.Setenv("MYAPP_PORT", "http")
+	_, err := executeCommand(rootCmd)
+	expected := `invalid value "http" for flag "port" from environment variable MYAPP_PORT: strconv.ParseInt: parsing "http": invalid syntax`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestRequiredUnlessEnv(t *testing.T) {
+	var source FlagSource
+	rootCmd := &Command{Use: "root", Run: func(cmd *Command, _ []string) { source = cmd.FlagSource("token") }}
+	rootCmd.Flags().String("token", "", "API token")
+	if err := RequiredUnlessEnv(rootCmd, "token", "CI_TOKEN"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := RequiredUnlessEnv(rootCmd, "unknown", "CI_UNKNOWN"); err == nil {
+		t.Error("Expected an error for an unknown flag")
+	}
+
+	t.Setenv("CI_TOKEN", "")
+	_, err := executeCommand(rootCmd)
+	expected := `required flag(s) "token" not set (or set the environment variable(s) CI_TOKEN for "token")`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+
+	t.Setenv("CI_TOKEN", "secret")
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if source != FlagSourceEnv {
+		t.Errorf("Expected the token to come from %s, got %s", FlagSourceEnv, source)
+	}
+
+	output, _ := executeCommand(rootCmd, "--help")
+	checkStringContains(t, output, "API token [$CI_TOKEN]")
+}
+
+func TestAutomaticEnv(t *testing.T) {
+	var region string
+	var tags []string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.PersistentFlags().StringVar(&region, "region", "", "region")
+	childCmd.Flags().StringSliceVar(&tags, "tag-name", nil, "tags")
+	childCmd.Flags().String("explicit", "", "explicit")
+	_ = BindEnv(childCmd, "explicit", "OTHER")
+	rootCmd.AutomaticEnv("myapp")
+
+	t.Setenv("MYAPP_REGION", "eu")
+	t.Setenv("MYAPP_TAG_NAME", "a,b")
+	output, err := executeCommand(rootCmd, "child", "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "tags [$MYAPP_TAG_NAME]")
+	checkStringContains(t, output, "explicit [$OTHER]")
+	checkStringContains(t, output, "region [$MYAPP_REGION]")
+	checkStringOmits(t, output, "MYAPP_HELP")
+
+	if _, err := executeCommand(rootCmd, "child"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if region != "eu" || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("Expected the flags to be set from the environment, got %q and %q", region, tags)
+	}
+}
+
+func TestBindEnvNormalizedName(t *testing.T) {
+	var logLevel string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "")
+	rootCmd.SetGlobalNormalizationFunc(func(f *flag.FlagSet, name string) flag.NormalizedName {
+		return flag.NormalizedName(strings.ReplaceAll(name, "_", "-"))
+	})
+	if err := BindEnv(childCmd, "log_level", "APP_LOG_LEVEL"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name := NormalizeName(childCmd, "log_level"); name != "log-level" {
+		t.Errorf("Expected the name to be normalized to %q, got %q", "log-level", name)
+	}
+
+	t.Setenv("APP_LOG_LEVEL", "debug")
+	if _, err := executeCommand(rootCmd, "child"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if logLevel != "debug" {
+		t.Errorf("Expected the flag to be set from the environment, got %q", logLevel)
+	}
+}
+
+func TestBindEnvUsage(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "child", Run: emptyRun})
+	rootCmd.PersistentFlags().String("config", "", "config file")
+	_ = BindEnv(rootCmd, "config", "OLD_CONFIG")
+	_ = BindEnv(rootCmd, "config", "MYAPP_CONFIG")
+	_ = BindEnv(rootCmd, "config", "MYAPP_CONFIG")
+	if usage := rootCmd.PersistentFlags().Lookup("config").Usage; usage != "config file" {
+		t.Errorf("Expected the usage of the flag to be left alone, got %q", usage)
+	}
+
+	extracted, err := Extract(rootCmd, "child")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output, _ := executeCommand(extracted, "--help")
+	checkStringContains(t, output, "config file [$MYAPP_CONFIG]\n")
+	checkStringOmits(t, output, "OLD_CONFIG")
+}
+
+func TestEnvFlagsResetBetweenExecutions(t *testing.T) {
+	var region string
+	var tags []string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().StringVar(&region, "region", "us", "region")
+	rootCmd.Flags().StringSliceVar(&tags, "tag", []string{"a", "b"}, "tags")
+	_ = BindEnv(rootCmd, "region", "MYAPP_REGION")
+	_ = BindEnv(rootCmd, "tag", "MYAPP_TAG")
+
+	t.Setenv("MYAPP_REGION", "eu")
+	t.Setenv("MYAPP_TAG", "c")
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if region != "eu" || !reflect.DeepEqual(tags, []string{"c"}) {
+		t.Errorf("Expected the flags to be set from the environment, got %q and %q", region, tags)
+	}
+
+	t.Setenv("MYAPP_REGION", "")
+	t.Setenv("MYAPP_TAG", "")
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if region != "us" || !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Errorf("Expected the default values to be restored, got %q and %q", region, tags)
+	}
+	if rootCmd.FlagSource("region") != FlagSourceDefault {
+		t.Errorf("Expected the region to come from %s, got %s", FlagSourceDefault, rootCmd.FlagSource("region"))
+	}
+}
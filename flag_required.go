@@ -0,0 +1,84 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// isRequiredFlag returns true if f was marked as required, see MarkFlagRequired.
+func isRequiredFlag(f *flag.Flag) bool {
+	required := f.Annotations[BashCompOneRequiredFlag]
+	return len(required) > 0 && required[0] == "true"
+}
+
+// RequiredFlags returns the local flags of the command which are required.
+// They are shown in the "Required Flags" section of the help and in the usage line.
+func (c *Command) RequiredFlags() *flag.FlagSet {
+	return c.filterLocalFlags(isRequiredFlag)
+}
+
+// OptionalLocalFlags returns the local flags of the command which are not required.
+func (c *Command) OptionalLocalFlags() *flag.FlagSet {
+	return c.filterLocalFlags(func(f *flag.Flag) bool { return !isRequiredFlag(f) })
+}
+
+// HasAvailableRequiredFlags checks if the command has required local flags which are
+// not hidden or deprecated.
+func (c *Command) HasAvailableRequiredFlags() bool {
+	return c.RequiredFlags().HasAvailableFlags()
+}
+
+// HasAvailableOptionalLocalFlags checks if the command has local flags which are not
+// required, hidden or deprecated.
+func (c *Command) HasAvailableOptionalLocalFlags() bool {
+	return c.OptionalLocalFlags().HasAvailableFlags()
+}
+
+// filterLocalFlags returns a flag set of the local flags of the command for which keep returns true.
+func (c *Command) filterLocalFlags(keep func(*flag.Flag) bool) *flag.FlagSet {
+	local := c.LocalFlags()
+	fs := flag.NewFlagSet(c.displayName(), flag.ContinueOnError)
+	fs.SortFlags = local.SortFlags
+	local.VisitAll(func(f *flag.Flag) {
+		if keep(f) {
+			fs.AddFlag(f)
+		}
+	})
+	return fs
+}
+
+// requiredFlagsUseLine returns the available required flags of the command which are
+// not already part of use, formatted for the usage line, e.g. " --image IMAGE".
+func (c *Command) requiredFlagsUseLine(use string) string {
+	var b strings.Builder
+	c.RequiredFlags().VisitAll(func(f *flag.Flag) {
+		if f.Hidden || len(f.Deprecated) > 0 || strings.Contains(use, "--"+f.Name) {
+			return
+		}
+		b.WriteString(" --" + f.Name)
+		if f.NoOptDefVal != "" {
+			return
+		}
+		name, _ := flag.UnquoteUsage(f)
+		if !strings.Contains(f.Usage, "`") {
+			name = strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		}
+		b.WriteString(" " + name)
+	})
+	return b.String()
+}
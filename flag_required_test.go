@@ -0,0 +1,67 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "testing"
+
+func newRequiredFlagsCmd() *Command {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	deployCmd := &Command{Use: "deploy", Run: emptyRun}
+	deployCmd.Flags().String("image", "", "image to deploy")
+	deployCmd.Flags().String("replicas", "1", "number of `N` replicas")
+	deployCmd.Flags().Bool("force", false, "force the deployment")
+	deployCmd.Flags().Bool("wait", false, "wait for the deployment")
+	_ = deployCmd.MarkFlagRequired("image")
+	_ = deployCmd.MarkFlagRequired("replicas")
+	_ = deployCmd.MarkFlagRequired("force")
+	rootCmd.AddCommand(deployCmd)
+	return rootCmd
+}
+
+func TestRequiredFlagsUseLine(t *testing.T) {
+	deployCmd, _, _ := newRequiredFlagsCmd().Find([]string{"deploy"})
+
+	expected := "app deploy --force --image IMAGE --replicas N [flags]"
+	if useLine := deployCmd.UseLine(); useLine != expected {
+		t.Errorf("expected %q, got %q", expected, useLine)
+	}
+
+	deployCmd.Use = "deploy --image IMAGE"
+	expected = "app deploy --image IMAGE --force --replicas N [flags]"
+	if useLine := deployCmd.UseLine(); useLine != expected {
+		t.Errorf("expected %q, got %q", expected, useLine)
+	}
+
+	deployCmd.DisableFlagsInUseLine = true
+	if useLine := deployCmd.UseLine(); useLine != "app deploy --image IMAGE" {
+		t.Errorf("expected no flags in the use line, got %q", useLine)
+	}
+}
+
+func TestRequiredFlagsHelp(t *testing.T) {
+	output, err := executeCommand(newRequiredFlagsCmd(), "deploy", "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, `Required Flags:
+      --force          force the deployment
+      --image string   image to deploy
+      --replicas N     number of N replicas (default "1")
+
+Flags:
+  -h, --help   help for deploy
+      --wait   wait for the deployment
+`)
+}
@@ -0,0 +1,104 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// FlagShadow describes a flag of a command which has the name of a persistent flag
+// of one of its parents, but a different type or default value.  The flag of the
+// command silently replaces the one of the parent, which is often a mistake.
+type FlagShadow struct {
+	// Flag is the name of the flag.
+	Flag string
+	// Command is the path of the command defining the shadowing flag.
+	Command string
+	// Parent is the path of the parent defining the persistent flag.
+	Parent string
+	// Reason describes how the flags differ.
+	Reason string
+}
+
+func (s FlagShadow) String() string {
+	return fmt.Sprintf("flag --%s of %q shadows the persistent flag of %q (%s): remove it to inherit the flag of %q, or rename it",
+		s.Flag, s.Command, s.Parent, s.Reason, s.Parent)
+}
+
+// FlagShadowError is returned by ValidateFlagDefinitions when flags shadow
+// persistent flags of their parents.
+type FlagShadowError struct {
+	Shadows []FlagShadow
+}
+
+func (e *FlagShadowError) Error() string {
+	lines := make([]string, 0, len(e.Shadows))
+	for _, s := range e.Shadows {
+		lines = append(lines, s.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateFlagDefinitions checks the flags of c and all its sub-commands and returns
+// a FlagShadowError if some of them have the name of a persistent flag of a parent
+// with a different type or default value.  It is meant to be called from a test of
+// the program.
+func (c *Command) ValidateFlagDefinitions() error {
+	var shadows []FlagShadow
+	c.visitTree(func(cmd *Command) {
+		shadows = append(shadows, cmd.flagShadows()...)
+	})
+	if len(shadows) > 0 {
+		return &FlagShadowError{Shadows: shadows}
+	}
+	return nil
+}
+
+// flagShadows returns the flags defined by c which shadow a persistent flag of one of
+// its parents with a different type or default value.
+func (c *Command) flagShadows() []FlagShadow {
+	var shadows []FlagShadow
+	seen := map[string]bool{}
+	for p := c.parent; p != nil; p = p.parent {
+		p.PersistentFlags().VisitAll(func(pf *flag.Flag) {
+			own := c.PersistentFlags().Lookup(pf.Name)
+			if own == nil && c.flags != nil {
+				own = c.flags.Lookup(pf.Name)
+			}
+			if own == nil || seen[pf.Name] {
+				return
+			}
+			// Only the closest parent defining the flag is shadowed.
+			seen[pf.Name] = true
+			if own == pf {
+				return
+			}
+			var reason string
+			switch {
+			case own.Value.Type() != pf.Value.Type():
+				reason = fmt.Sprintf("type %s instead of %s", own.Value.Type(), pf.Value.Type())
+			case own.DefValue != pf.DefValue:
+				reason = fmt.Sprintf("default %q instead of %q", own.DefValue, pf.DefValue)
+			default:
+				return
+			}
+			shadows = append(shadows, FlagShadow{Flag: pf.Name, Command: c.CommandPath(), Parent: p.CommandPath(), Reason: reason})
+		})
+	}
+	return shadows
+}
@@ -0,0 +1,56 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateFlagDefinitions(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().Int("intf", -1, "")
+	rootCmd.PersistentFlags().String("strf", "a", "")
+	rootCmd.PersistentFlags().Bool("boolf", false, "")
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	childCmd.Flags().String("intf", "", "")
+	childCmd.PersistentFlags().String("strf", "b", "")
+	childCmd.Flags().Bool("boolf", false, "")
+	grandchildCmd := &Command{Use: "grandchild", Run: emptyRun}
+	grandchildCmd.Flags().String("strf", "b", "")
+	rootCmd.AddCommand(childCmd)
+	childCmd.AddCommand(grandchildCmd)
+
+	err := rootCmd.ValidateFlagDefinitions()
+	var shadowErr *FlagShadowError
+	if !errors.As(err, &shadowErr) {
+		t.Fatalf("Expected a FlagShadowError, got %v", err)
+	}
+	// The flags which only differ in their usage and the ones shadowing a flag of the
+	// same type and default of their closest parent are not reported.
+	expected := []FlagShadow{
+		{Flag: "intf", Command: "root child", Parent: "root", Reason: "type string instead of int"},
+		{Flag: "strf", Command: "root child", Parent: "root", Reason: `default "b" instead of "a"`},
+	}
+	if !reflect.DeepEqual(shadowErr.Shadows, expected) {
+		t.Errorf("Expected %v, got %v", expected, shadowErr.Shadows)
+	}
+	checkStringContains(t, err.Error(), `flag --intf of "root child" shadows the persistent flag of "root" (type string instead of int): remove it to inherit the flag of "root", or rename it`)
+
+	if err := grandchildCmd.ValidateFlagDefinitions(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"strconv"
+
+	flag "github.com/spf13/pflag"
+)
+
+// FlagValues returns the values of all the flags available to c, whether they are
+// local, persistent or inherited from its parents, keyed by the name of the flags.
+// When flags have the same name, the value of the one closest to c is returned.
+func FlagValues(c *Command) map[string]string {
+	values := map[string]string{}
+	visit := func(f *flag.Flag) {
+		if _, ok := values[f.Name]; !ok {
+			values[f.Name] = f.Value.String()
+		}
+	}
+	c.Flags().VisitAll(visit)
+	c.PersistentFlags().VisitAll(visit)
+	c.InheritedFlags().VisitAll(visit)
+	return values
+}
+
+// GetString returns the value of the string flag with the given name, which may be
+// a local, persistent or inherited flag of c.
+func (c *Command) GetString(name string) (string, error) {
+	f, err := c.typedFlag(name, "string")
+	if err != nil {
+		return "", err
+	}
+	return f.Value.String(), nil
+}
+
+// GetInt returns the value of the int flag with the given name, which may be
+// a local, persistent or inherited flag of c.
+func (c *Command) GetInt(name string) (int, error) {
+	f, err := c.typedFlag(name, "int")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(f.Value.String())
+}
+
+// GetBool returns the value of the bool flag with the given name, which may be
+// a local, persistent or inherited flag of c.
+func (c *Command) GetBool(name string) (bool, error) {
+	f, err := c.typedFlag(name, "bool")
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(f.Value.String())
+}
+
+// typedFlag returns the flag with the given name available to c, and an error if
+// it does not exist or is not of type typ.
+func (c *Command) typedFlag(name, typ string) (*flag.Flag, error) {
+	f := c.Flag(name)
+	if f == nil {
+		return nil, fmt.Errorf("flag accessed but not defined: %s", name)
+	}
+	if f.Value.Type() != typ {
+		return nil, fmt.Errorf("trying to get %s value of flag of type %s", typ, f.Value.Type())
+	}
+	return f, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlagValues(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.PersistentFlags().String("region", "eu", "")
+	rootCmd.PersistentFlags().Bool("verbose", false, "")
+	rootCmd.PersistentFlags().Int("retries", 1, "")
+	rootCmd.Flags().String("root-only", "", "")
+	childCmd.PersistentFlags().Int("retries", 3, "")
+	childCmd.Flags().Bool("force", false, "")
+
+	if _, err := executeCommand(rootCmd, "child", "--region", "us", "--verbose", "--retries", "5"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"region":  "us",
+		"verbose": "true",
+		"retries": "5",
+		"force":   "false",
+		"help":    "false",
+	}
+	if got := FlagValues(childCmd); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if region, err := childCmd.GetString("region"); err != nil || region != "us" {
+		t.Errorf("Expected region 'us', got %q (%v)", region, err)
+	}
+	if verbose, err := childCmd.GetBool("verbose"); err != nil || !verbose {
+		t.Errorf("Expected verbose, got %v (%v)", verbose, err)
+	}
+	if retries, err := childCmd.GetInt("retries"); err != nil || retries != 5 {
+		t.Errorf("Expected 5 retries, got %d (%v)", retries, err)
+	}
+	if _, err := childCmd.GetInt("region"); err == nil {
+		t.Error("Expected an error for a flag of another type")
+	}
+	if _, err := childCmd.GetString("root-only"); err == nil {
+		t.Error("Expected an error for a local flag of the parent")
+	}
+}
+
+func TestFlagValuesBeforeExecution(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.PersistentFlags().String("region", "eu", "")
+
+	if got := FlagValues(childCmd); got["region"] != "eu" {
+		t.Errorf("Expected the inherited flag with its default value, got %v", got)
+	}
+	if region, err := childCmd.GetString("region"); err != nil || region != "eu" {
+		t.Errorf("Expected region 'eu', got %q (%v)", region, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// helpTopicAnnotation marks the commands added by AddHelpTopic.
+const helpTopicAnnotation = "cobra_annotation_help_topic"
+
+// AddHelpTopic adds to root a help topic: a page of documentation, such as a guide
+// to the configuration or the environment variables of the program, which is
+// listed under "Additional help topics" and shown by 'app help <name>'.
+//
+// The content is a template executed with the topic command, e.g. {{.Root.Name}}
+// is the name of the program, and is rendered as Markdown: headings, **bold** text,
+// `code` and fenced code blocks are styled when the output is a terminal, see
+// ColorTheme, and their markers are removed otherwise, except for inline code.
+func AddHelpTopic(root *Command, name, title, content string) *Command {
+	topic := &Command{
+		Use:         name,
+		Short:       title,
+		Long:        content,
+		Annotations: map[string]string{helpTopicAnnotation: "true"},
+	}
+	topic.SetHelpFunc(func(cmd *Command, args []string) {
+		text, err := cmd.helpTopicText()
+		if err != nil {
+			cmd.PrintErrln(cmd.styledErrPrefix(cmd), err.Error())
+			return
+		}
+		cmd.Print(renderMarkdown(colorSupported(cmd.OutOrStdout()), text))
+	})
+	root.AddCommand(topic)
+	return topic
+}
+
+// isHelpTopic returns true if c was added by AddHelpTopic.
+func (c *Command) isHelpTopic() bool {
+	return c.Annotations[helpTopicAnnotation] == "true" && !c.Hidden
+}
+
+// helpTopicText returns the content of the help topic c, with its template executed.
+func (c *Command) helpTopicText() (string, error) {
+	content := c.Long
+	if content == "" {
+		content = c.Short
+	}
+	t, err := template.New("topic").Funcs(templateFuncs).Parse(content)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, c); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n", nil
+}
+
+// markdownCodeStyle is the style of the code in help topics.
+const markdownCodeStyle = "cyan"
+
+var (
+	markdownHeading = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	markdownBold    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownCode    = regexp.MustCompile("`([^`]+)`")
+)
+
+// renderMarkdown renders the Markdown text for a terminal, styled if color is true.
+func renderMarkdown(color bool, text string) string {
+	var b strings.Builder
+	inCode := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		content := strings.TrimSuffix(line, "\n")
+		newline := line[len(content):]
+		switch {
+		case strings.HasPrefix(content, "```"):
+			inCode = !inCode
+			continue
+		case inCode:
+			content = "    " + style(color, markdownCodeStyle, content)
+		case markdownHeading.MatchString(content):
+			content = style(color, "bold", markdownHeading.ReplaceAllString(content, "$1"))
+		default:
+			content = markdownBold.ReplaceAllStringFunc(content, func(s string) string {
+				return style(color, "bold", s[2:len(s)-2])
+			})
+			if color {
+				content = markdownCode.ReplaceAllStringFunc(content, func(s string) string {
+					return style(color, markdownCodeStyle, s[1:len(s)-1])
+				})
+			}
+		}
+		b.WriteString(content + newline)
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+	"testing"
+)
+
+const topicContent = "# Environment\n\n{{.Root.Name}} reads **two** variables:\n\n```\nexport `APP_HOME`=/opt\n```\n\nSee `APP_HOME`.\n"
+
+func newHelpTopicTree() *Command {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "child", Short: "A child", Run: emptyRun})
+	AddHelpTopic(rootCmd, "environment", "Environment variables", topicContent)
+	return rootCmd
+}
+
+func TestHelpTopic(t *testing.T) {
+	t.Setenv("CLICOLOR_FORCE", "")
+
+	output, err := executeCommand(newHelpTopicTree(), "help", "environment")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "Environment\n\napp reads two variables:\n\n    export `APP_HOME`=/opt\n\nSee `APP_HOME`.\n"
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+
+	output, err = executeCommand(newHelpTopicTree(), "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "Additional help topics:\n  app environment Environment variables\n")
+}
+
+func TestHelpTopicStyled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "1")
+
+	output, err := executeCommand(newHelpTopicTree(), "help", "environment")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "\x1b[1mEnvironment\x1b[0m\n\napp reads \x1b[1mtwo\x1b[0m variables:\n\n    \x1b[36mexport `APP_HOME`=/opt\x1b[0m\n\nSee \x1b[36mAPP_HOME\x1b[0m.\n"
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
+
+func TestHelpTopicCompletion(t *testing.T) {
+	output, err := executeCommand(newHelpTopicTree(), ShellCompRequestCmd, "help", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"child\tA child",
+		"completion\tGenerate the autocompletion script for the specified shell",
+		"environment\tEnvironment variables",
+		"help\tHelp about any command",
+		":4",
+		"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
+
+func TestHelpTopicInvalidTemplate(t *testing.T) {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	AddHelpTopic(rootCmd, "broken", "A broken topic", "{{.Unknown")
+
+	output, err := executeCommand(rootCmd, "help", "broken")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "Error: template: topic:1: unclosed action")
+}
@@ -0,0 +1,134 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	flag "github.com/spf13/pflag"
+)
+
+// minWrapWidth is the narrowest text, after the indentation, worth wrapping.
+const minWrapWidth = 24
+
+// SetHelpWidth sets the width the help of the command and its children which do
+// not set their own is wrapped to.  A negative width disables the wrapping, and 0
+// restores the default: the width given by the COLUMNS environment variable, or
+// else the width of the terminal the help is written to, if any.
+func (c *Command) SetHelpWidth(width int) {
+	c.helpWidth = width
+}
+
+// HelpWidth returns the width the help of the command is wrapped to, see
+// SetHelpWidth, or 0 if it is not wrapped.
+func (c *Command) HelpWidth() int {
+	for p := c; p != nil; p = p.parent {
+		if p.helpWidth > 0 {
+			return p.helpWidth
+		}
+		if p.helpWidth < 0 {
+			return 0
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return outputWidth(c.OutOrStdout())
+}
+
+// outputWidth returns the width of the terminal w writes to, or 0 if it is not a
+// terminal.  A colorWriter buffering text for an output reports the width of that output.
+func outputWidth(w io.Writer) int {
+	if cw, ok := w.(*colorWriter); ok {
+		return cw.width
+	}
+	if f, ok := w.(*os.File); ok {
+		return fileTerminalWidth(f)
+	}
+	return 0
+}
+
+// fileTerminalWidth returns the width of the terminal f is, or 0 if it is not a
+// terminal; it is a variable for testing.
+var fileTerminalWidth = func(f *os.File) int {
+	if !isTerminal(f) {
+		return 0
+	}
+	return terminalWidth(f)
+}
+
+// WrapText wraps the lines of text to the help width of the command, indenting the
+// lines it adds by indent spaces so that they align with a first line starting at
+// that column.  Lines starting with a space, such as examples, are left as they are.
+func (c *Command) WrapText(indent int, text string) string {
+	return wrapText(text, c.HelpWidth(), indent)
+}
+
+// WrappedShort returns the short description of the command wrapped to its help
+// width, aligned with the first line in the lists of sub-commands of the help.
+func (c *Command) WrappedShort() string {
+	return c.WrapText(len("  ")+c.NamePadding()+len(" "), c.Short)
+}
+
+// WrappedFlagUsages returns the usages of flags wrapped to the help width of the
+// command, with hanging indentation.
+func (c *Command) WrappedFlagUsages(flags *flag.FlagSet) string {
+	return envVarFlags(flags).FlagUsagesWrapped(c.HelpWidth())
+}
+
+// wrapText wraps each line of text to width, see WrapText.
+func wrapText(text string, width, indent int) string {
+	if width <= 0 || width-indent < minWrapWidth {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		start := indent
+		if i > 0 {
+			start = 0
+		}
+		lines[i] = wrapLine(line, width, start, indent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapLine wraps line, whose first word starts at column start, to width,
+// starting the added lines at column indent.
+func wrapLine(line string, width, start, indent int) string {
+	var b strings.Builder
+	column := start
+	for i, word := range strings.Fields(line) {
+		n := utf8.RuneCountInString(word)
+		switch {
+		case i == 0:
+		case column+1+n > width:
+			b.WriteString("\n" + strings.Repeat(" ", indent))
+			column = indent
+		default:
+			b.WriteString(" ")
+			column++
+		}
+		b.WriteString(word)
+		column += n
+	}
+	return b.String()
+}
@@ -0,0 +1,133 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"os"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		width    int
+		indent   int
+		expected string
+	}{
+		{"no width", "a long line which is not wrapped", 0, 0, "a long line which is not wrapped"},
+		{"short", "fits", 40, 0, "fits"},
+		{
+			"wrapped",
+			"the quick brown fox jumps over the lazy dog and keeps running",
+			30, 0,
+			"the quick brown fox jumps over\nthe lazy dog and keeps running",
+		},
+		{
+			"hanging indent",
+			"the quick brown fox jumps over the lazy dog",
+			40, 10,
+			"the quick brown fox jumps over\n          the lazy dog",
+		},
+		{
+			"paragraphs and examples",
+			"the quick brown fox jumps over the lazy dog\n\n  app run --a-very-long-flag-name value --other",
+			30, 0,
+			"the quick brown fox jumps over\nthe lazy dog\n\n  app run --a-very-long-flag-name value --other",
+		},
+		{"too narrow", "the quick brown fox jumps over the lazy dog", 30, 10, "the quick brown fox jumps over the lazy dog"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := wrapText(tc.text, tc.width, tc.indent); got != tc.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestHelpWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	root := &Command{Use: "root"}
+	child := &Command{Use: "child"}
+	root.AddCommand(child)
+
+	if w := child.HelpWidth(); w != 0 {
+		t.Errorf("Expected no wrapping when the output is not a terminal, got %d", w)
+	}
+
+	t.Setenv("COLUMNS", "100")
+	if w := child.HelpWidth(); w != 100 {
+		t.Errorf("Expected the width from COLUMNS, got %d", w)
+	}
+
+	root.SetHelpWidth(60)
+	if w := child.HelpWidth(); w != 60 {
+		t.Errorf("Expected the width of the parent, got %d", w)
+	}
+
+	child.SetHelpWidth(-1)
+	if w := child.HelpWidth(); w != 0 {
+		t.Errorf("Expected no wrapping, got %d", w)
+	}
+}
+
+func TestHelpWrapped(t *testing.T) {
+	root := &Command{
+		Use:  "root",
+		Long: "The root command does many things, which take more than one line to describe.",
+		Run:  emptyRun,
+	}
+	root.Flags().String("name", "", "the name of the thing to create, which must be unique in the namespace")
+	root.AddCommand(&Command{Use: "child", Short: "A child command with a rather long description", Run: emptyRun})
+	root.SetHelpWidth(50)
+
+	output, err := executeCommand(root, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "The root command does many things, which take more\nthan one line to describe.\n")
+	checkStringContains(t, output, "  child       A child command with a rather long\n              description\n")
+	checkStringContains(t, output, "      --name string   the name of the thing\n                      to create, which must\n")
+}
+
+func TestHelpWrappedToTerminal(t *testing.T) {
+	t.Setenv("COLUMNS", "")
+	out, err := os.CreateTemp("", "cobra-test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	defer func(f func(*os.File) int) { fileTerminalWidth = f }(fileTerminalWidth)
+	fileTerminalWidth = func(f *os.File) int {
+		if f == out {
+			return 50
+		}
+		return 0
+	}
+
+	root := &Command{Use: "root", Run: emptyRun}
+	root.Flags().String("name", "", "the name of the thing to create, which must be unique in the namespace")
+	root.SetOut(out)
+	if err := root.Help(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, string(output), "      --name string   the name of the thing\n                      to create, which must\n")
+}
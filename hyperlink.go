@@ -0,0 +1,85 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// These values should not be changed: users will be using them explicitly.
+const configEnvVarSuffixHyperlinks = "HYPERLINKS"
+
+// hyperlink formats text as an OSC 8 terminal hyperlink pointing to url.
+func hyperlink(url, text string) string {
+	return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, text)
+}
+
+// DocsLink returns the DocsURL of the command formatted as a clickable link if
+// the output of the command is a terminal which supports OSC 8 hyperlinks,
+// or the plain DocsURL otherwise.
+// Detection can be overridden by setting the environment variable
+// <PROGRAM>_HYPERLINKS, or COBRA_HYPERLINKS, to a boolean value.
+func (c *Command) DocsLink() string {
+	if c.DocsURL == "" || !c.hyperlinksSupported() {
+		return c.DocsURL
+	}
+	return hyperlink(c.DocsURL, c.DocsURL)
+}
+
+// hyperlinksSupported returns true if the output of the command is known to
+// render OSC 8 hyperlinks.
+func (c *Command) hyperlinksSupported() bool {
+	if enabled, err := strconv.ParseBool(getEnvConfig(c, configEnvVarSuffixHyperlinks)); err == nil {
+		return enabled
+	}
+	return isTerminal(c.OutOrStdout()) && terminalSupportsHyperlinks()
+}
+
+// isTerminal returns true if w is a character device such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalSupportsHyperlinks uses the environment to detect terminal
+// emulators known to support OSC 8 hyperlinks.
+func terminalSupportsHyperlinks() bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty":
+		return true
+	}
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	for _, v := range []string{"WT_SESSION", "KITTY_WINDOW_ID", "KONSOLE_VERSION", "DOMTERM"} {
+		if os.Getenv(v) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"testing"
+)
+
+const docsURL = "https://example.com/docs/root"
+
+func TestDocsLinkInHelp(t *testing.T) {
+	rootCmd := &Command{Use: "root", Short: "Root", Run: emptyRun, DocsURL: docsURL}
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	// The output is not a terminal so the plain URL is expected
+	checkStringContains(t, output, "\nDocs: "+docsURL+"\n")
+	checkStringOmits(t, output, "\x1b]8;;")
+}
+
+func TestNoDocsLinkInHelp(t *testing.T) {
+	rootCmd := &Command{Use: "root", Short: "Root", Run: emptyRun}
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringOmits(t, output, "Docs:")
+}
+
+func TestDocsLinkHyperlinkOverride(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun, DocsURL: docsURL}
+	rootCmd.SetOut(new(bytes.Buffer))
+
+	t.Setenv("ROOT_HYPERLINKS", "1")
+	expected := "\x1b]8;;" + docsURL + "\x1b\\" + docsURL + "\x1b]8;;\x1b\\"
+	if got := rootCmd.DocsLink(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	t.Setenv("ROOT_HYPERLINKS", "")
+	t.Setenv("COBRA_HYPERLINKS", "true")
+	if got := rootCmd.DocsLink(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	t.Setenv("ROOT_HYPERLINKS", "0")
+	if got := rootCmd.DocsLink(); got != docsURL {
+		t.Errorf("expected %q, got %q", docsURL, got)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// Invocation describes how a command was invoked: which command, with which
+// flags and which positional arguments.  It can be serialized (e.g. to JSON) and
+// later re-executed with Replay, for instance to implement a "re-run last command"
+// feature or to reproduce an issue from a support bundle.
+type Invocation struct {
+	// Path is the list of command names from the root (excluded) to the invoked command.
+	Path []string `json:"path"`
+	// Flags are the flags which did not hold their default value.
+	Flags []InvocationFlag `json:"flags,omitempty"`
+	// Args are the positional arguments passed to the command.
+	Args []string `json:"args,omitempty"`
+}
+
+// InvocationFlag is a flag recorded as part of an Invocation.
+type InvocationFlag struct {
+	Name string `json:"name"`
+	// Values holds the value of the flag; flags which can be repeated
+	// have one entry per element for slices and arrays, and one "key=value"
+	// entry per key for maps.
+	Values []string   `json:"values"`
+	Source FlagSource `json:"source"`
+	// Type is the type of the flag, as returned by flag.Value.Type.
+	Type string `json:"type,omitempty"`
+}
+
+// RecordInvocation returns the Invocation of cmd.  It is meant to be called once
+// the flags have been parsed, for instance from one of the *Run functions.
+// For commands which set DisableFlagParsing, neither flags nor arguments are recorded
+// since they are only known to the command itself.
+func RecordInvocation(cmd *Command) Invocation {
+	inv := Invocation{}
+	for p := cmd; p.HasParent(); p = p.Parent() {
+		inv.Path = append([]string{p.Name()}, inv.Path...)
+	}
+
+	cmd.Flags().VisitAll(func(f *flag.Flag) {
+		source := cmd.FlagSource(f.Name)
+		if source == FlagSourceDefault {
+			return
+		}
+		values := []string{f.Value.String()}
+		if sv, ok := f.Value.(flag.SliceValue); ok {
+			values = sv.GetSlice()
+		} else if entries, ok := mapFlagEntries(cmd.Flags(), f); ok {
+			values = entries
+		}
+		inv.Flags = append(inv.Flags, InvocationFlag{Name: f.Name, Values: values, Source: source, Type: f.Value.Type()})
+	})
+	inv.Args = append(inv.Args, cmd.Flags().Args()...)
+	return inv
+}
+
+// CommandLine returns the arguments, relative to the root command, which
+// reproduce the invocation.
+func (inv Invocation) CommandLine() []string {
+	args := append([]string{}, inv.Path...)
+	for _, f := range inv.Flags {
+		for _, v := range f.Values {
+			if strings.HasSuffix(f.Type, "Slice") || f.Type == "stringToString" {
+				v = quoteSliceElement(v)
+			}
+			args = append(args, fmt.Sprintf("--%s=%s", f.Name, v))
+		}
+	}
+	for _, a := range inv.Args {
+		if strings.HasPrefix(a, "-") {
+			// Make sure positional arguments are not interpreted as flags.
+			args = append(args, "--")
+			break
+		}
+	}
+	return append(args, inv.Args...)
+}
+
+// mapFlagEntries returns the "key=value" entries, sorted by key, of f if it is
+// one of the map flags of pflag.
+func mapFlagEntries(flags *flag.FlagSet, f *flag.Flag) ([]string, bool) {
+	var entries []string
+	switch f.Value.Type() {
+	case "stringToString":
+		m, _ := flags.GetStringToString(f.Name)
+		for k, v := range m {
+			entries = append(entries, k+"="+v)
+		}
+	case "stringToInt":
+		m, _ := flags.GetStringToInt(f.Name)
+		for k, v := range m {
+			entries = append(entries, fmt.Sprintf("%s=%d", k, v))
+		}
+	case "stringToInt64":
+		m, _ := flags.GetStringToInt64(f.Name)
+		for k, v := range m {
+			entries = append(entries, fmt.Sprintf("%s=%d", k, v))
+		}
+	default:
+		return nil, false
+	}
+	sort.Strings(entries)
+	return entries, true
+}
+
+// quoteSliceElement quotes v so that it is parsed as a single element by the
+// slice and map flags, which split their values on commas.
+func quoteSliceElement(v string) string {
+	if !strings.ContainsAny(v, ",\"\r\n") {
+		return v
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{v})
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// Replay executes the invocation again on the command tree of root.
+// The arguments of root are replaced by the ones of the invocation.
+func Replay(root *Command, inv Invocation) error {
+	root.SetArgs(inv.CommandLine())
+	return root.Execute()
+}
+
+// ResolutionMode is how the executed command was found from the arguments.
+type ResolutionMode string
+
+const (
+	// ResolvedByFind means the command was found with Find: only the flags of
+	// the command itself were parsed.
+	ResolvedByFind ResolutionMode = "find"
+	// ResolvedByTraverse means the command was found with Traverse because
+	// TraverseChildren is set: the flags of its parents were parsed too.
+	ResolvedByTraverse ResolutionMode = "traverse"
+)
+
+// InvocationInfo describes how the command being executed was resolved.
+type InvocationInfo struct {
+	// CalledAs is the name or alias used to invoke the command.
+	CalledAs string
+	// Path is the full path of the command, as returned by CommandPath.
+	Path string
+	// RawArgs are the arguments received by the root command.
+	RawArgs []string
+	// ArgsAfterDash are the positional arguments which followed "--".
+	ArgsAfterDash []string
+	// ResolvedBy is how the command was found from the arguments.
+	ResolvedBy ResolutionMode
+}
+
+// InvocationInfo returns how the command was resolved, or nil if the command was
+// not executed.  It is meant to be called from the *Run functions of the command.
+func (c *Command) InvocationInfo() *InvocationInfo {
+	if !c.commandCalledAs.called {
+		return nil
+	}
+	info := &InvocationInfo{
+		CalledAs:   c.CalledAs(),
+		Path:       c.CommandPath(),
+		RawArgs:    c.RawArgs(),
+		ResolvedBy: c.resolvedBy,
+	}
+	// The position of the dash is not reset when the flags are parsed again
+	// without one, so make sure it is consistent with the current arguments.
+	if args, dash := c.Flags().Args(), c.ArgsLenAtDash(); dash >= 0 && dash < len(args) {
+		info.ArgsAfterDash = append([]string{}, args[dash:]...)
+	}
+	return info
+}
@@ -0,0 +1,181 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRecordAndReplayInvocation(t *testing.T) {
+	var recorded Invocation
+	var runs [][]string
+	var names []string
+	var verbose bool
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose")
+	childCmd := &Command{
+		Use: "child",
+		Run: func(cmd *Command, args []string) {
+			recorded = RecordInvocation(cmd)
+			runs = append(runs, args)
+			names, _ = cmd.Flags().GetStringSlice("name")
+		},
+	}
+	childCmd.Flags().StringSlice("name", nil, "names")
+	childCmd.Flags().String("unused", "default", "unused")
+	rootCmd.AddCommand(childCmd)
+
+	_, err := executeCommand(rootCmd, "child", "-v", "--name", "a,b", "--name", `"c,d"`, "one", "--", "-two")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := Invocation{
+		Path: []string{"child"},
+		Flags: []InvocationFlag{
+			{Name: "name", Values: []string{"a", "b", "c,d"}, Source: FlagSourceCommandLine, Type: "stringSlice"},
+			{Name: "verbose", Values: []string{"true"}, Source: FlagSourceCommandLine, Type: "bool"},
+		},
+		Args: []string{"one", "-two"},
+	}
+	if !reflect.DeepEqual(recorded, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, recorded)
+	}
+
+	data, err := json.Marshal(recorded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded Invocation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Replay on a fresh tree
+	verbose = false
+	names = nil
+	rootCmd.ResetFlags()
+	childCmd.ResetFlags()
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose")
+	childCmd.Flags().StringSlice("name", nil, "names")
+
+	if err := Replay(rootCmd, decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(runs) != 2 || !reflect.DeepEqual(runs[0], runs[1]) {
+		t.Errorf("expected replay to receive the same args, got %q", runs)
+	}
+	if !verbose || !reflect.DeepEqual(names, []string{"a", "b", "c,d"}) {
+		t.Errorf("expected replay to set the same flags, got verbose=%v names=%q", verbose, names)
+	}
+}
+
+func TestReplayMapFlags(t *testing.T) {
+	var labels map[string]string
+	var limits map[string]int
+	var recorded Invocation
+	rootCmd := &Command{
+		Use: "root",
+		Run: func(cmd *Command, _ []string) { recorded = RecordInvocation(cmd) },
+	}
+	rootCmd.Flags().StringToStringVar(&labels, "label", nil, "labels")
+	rootCmd.Flags().StringToIntVar(&limits, "limit", nil, "limits")
+
+	_, err := executeCommand(rootCmd, "--label", "app=web", "--label", `"tier=a,b"`, "--limit", "cpu=2,mem=512")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedLabels := map[string]string{"app": "web", "tier": "a,b"}
+	expectedLimits := map[string]int{"cpu": 2, "mem": 512}
+	if !reflect.DeepEqual(labels, expectedLabels) || !reflect.DeepEqual(limits, expectedLimits) {
+		t.Fatalf("Unexpected values %v and %v", labels, limits)
+	}
+	expected := []string{"--label=app=web", `--label="tier=a,b"`, "--limit=cpu=2", "--limit=mem=512"}
+	if got := recorded.CommandLine(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	labels, limits = nil, nil
+	rootCmd.ResetFlags()
+	rootCmd.Flags().StringToStringVar(&labels, "label", nil, "labels")
+	rootCmd.Flags().StringToIntVar(&limits, "limit", nil, "limits")
+	if err := Replay(rootCmd, recorded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(labels, expectedLabels) || !reflect.DeepEqual(limits, expectedLimits) {
+		t.Errorf("expected the replay to set %v and %v, got %v and %v", expectedLabels, expectedLimits, labels, limits)
+	}
+}
+
+func TestInvocationCommandLine(t *testing.T) {
+	inv := Invocation{
+		Path:  []string{"a", "b"},
+		Flags: []InvocationFlag{{Name: "sel", Values: []string{"app=x"}}},
+		Args:  []string{"arg"},
+	}
+	expected := []string{"a", "b", "--sel=app=x", "arg"}
+	if got := inv.CommandLine(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	inv.Flags = []InvocationFlag{
+		{Name: "slice", Values: []string{"x", "y,z"}, Type: "stringSlice"},
+		{Name: "array", Values: []string{"y,z"}, Type: "stringArray"},
+	}
+	expected = []string{"a", "b", "--slice=x", `--slice="y,z"`, "--array=y,z", "arg"}
+	if got := inv.CommandLine(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestInvocationInfo(t *testing.T) {
+	var info *InvocationInfo
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{
+		Use:     "child",
+		Aliases: []string{"kid"},
+		Run:     func(cmd *Command, _ []string) { info = cmd.InvocationInfo() },
+	}
+	rootCmd.AddCommand(childCmd)
+
+	if childCmd.InvocationInfo() != nil {
+		t.Error("expected no invocation info before execution")
+	}
+
+	if _, err := executeCommand(rootCmd, "kid", "a", "--", "b", "-c"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := &InvocationInfo{
+		CalledAs:      "kid",
+		Path:          "root child",
+		RawArgs:       []string{"kid", "a", "--", "b", "-c"},
+		ArgsAfterDash: []string{"b", "-c"},
+		ResolvedBy:    ResolvedByFind,
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+
+	rootCmd.TraverseChildren = true
+	if _, err := executeCommand(rootCmd, "child"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.ResolvedBy != ResolvedByTraverse || info.ArgsAfterDash != nil {
+		t.Errorf("expected a traversal without dash, got %+v", info)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+// AddLazyCommand adds a sub-command to c which is only built by load when it is
+// selected on the command line, e.g. because it imports large packages which are
+// slow to initialize.  Until then, stub stands for it: its Use, Short, Aliases,
+// GroupID, Hidden and Deprecated fields are used to list it in the help and to
+// complete its name.  load must return a command with the same name; the aliases
+// of stub are added to it.
+func (c *Command) AddLazyCommand(stub *Command, load func() *Command) {
+	stub.lazyLoad = load
+	c.AddCommand(stub)
+}
+
+// isLazy returns true if c is the stub of a command added by AddLazyCommand
+// which is not loaded yet.
+func (c *Command) isLazy() bool {
+	return c.lazyLoad != nil
+}
+
+// loadLazy replaces c in its parent by the command it stands for, if c is a stub
+// added by AddLazyCommand, and returns that command; otherwise it returns c.
+func (c *Command) loadLazy() *Command {
+	if !c.isLazy() || c.parent == nil {
+		return c
+	}
+	parent := c.parent
+	loaded := c.lazyLoad()
+	for i, cmd := range parent.commands {
+		if cmd == c {
+			parent.commands[i] = loaded
+			break
+		}
+	}
+	loaded.parent = parent
+	for _, alias := range c.Aliases {
+		if !stringInSlice(alias, loaded.Aliases) {
+			loaded.Aliases = append(loaded.Aliases, alias)
+		}
+	}
+	loaded.commandCalledAs.name = c.commandCalledAs.name
+	if parent.globNormFunc != nil {
+		loaded.SetGlobalNormalizationFunc(parent.globNormFunc)
+	}
+	parent.commandsAreSorted = false
+	parent.commandIndex = nil
+	c.parent = nil
+	return loaded
+}
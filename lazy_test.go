@@ -0,0 +1,103 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+	"testing"
+)
+
+func newLazyTree(loads *int, gotArgs *[]string) *Command {
+	root := &Command{Use: "root", Run: emptyRun}
+	root.AddLazyCommand(&Command{Use: "heavy", Short: "A heavy command", Aliases: []string{"hv"}}, func() *Command {
+		*loads++
+		heavy := &Command{
+			Use:   "heavy [file]",
+			Short: "A heavy command",
+			Run:   func(_ *Command, args []string) { *gotArgs = args },
+		}
+		heavy.Flags().String("mode", "", "the mode")
+		heavy.AddCommand(&Command{Use: "sub", Run: emptyRun})
+		return heavy
+	})
+	root.AddCommand(&Command{Use: "light", Run: emptyRun})
+	return root
+}
+
+func TestLazyCommandNotLoaded(t *testing.T) {
+	var loads int
+	var gotArgs []string
+	root := newLazyTree(&loads, &gotArgs)
+
+	output, err := executeCommand(root, ShellCompNoDescRequestCmd, "h")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "heavy\nhelp\n")
+
+	output, err = executeCommand(root, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "heavy       A heavy command")
+
+	if _, err := executeCommand(root, "light"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if loads != 0 {
+		t.Errorf("Expected the lazy command not to be loaded, loaded %d times", loads)
+	}
+}
+
+func TestLazyCommandLoadedWhenSelected(t *testing.T) {
+	var loads int
+	var gotArgs []string
+	root := newLazyTree(&loads, &gotArgs)
+
+	if _, err := executeCommand(root, "hv", "--mode", "fast", "file.txt"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(gotArgs, " ") != "file.txt" {
+		t.Errorf("Expected args [file.txt], got %v", gotArgs)
+	}
+
+	heavy, _, err := root.Find([]string{"heavy", "sub"})
+	if err != nil || heavy.Name() != "sub" {
+		t.Fatalf("Expected to find the sub-command of the loaded command, got %v (%v)", heavy, err)
+	}
+	if heavy.Parent().isLazy() || heavy.Parent().Parent() != root {
+		t.Errorf("Expected the stub to be replaced by the loaded command")
+	}
+
+	if loads != 1 {
+		t.Errorf("Expected the lazy command to be loaded once, loaded %d times", loads)
+	}
+}
+
+func TestLazyCommandLoadedForCompletion(t *testing.T) {
+	var loads int
+	var gotArgs []string
+	root := newLazyTree(&loads, &gotArgs)
+
+	output, err := executeCommand(root, ShellCompNoDescRequestCmd, "heavy", "--m")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "--mode\n")
+	if loads != 1 {
+		t.Errorf("Expected the lazy command to be loaded once, loaded %d times", loads)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "strconv"
+
+// EventType identifies a step of the execution of a command.
+type EventType int
+
+const (
+	// CommandResolved is sent once the command to execute has been found from the arguments.
+	CommandResolved EventType = iota
+	// FlagsParsed is sent once the flags of the command have been parsed and set
+	// from the environment and the configuration file.
+	FlagsParsed
+	// BeforeRun is sent right before the Run function of the command is called,
+	// after the pre-run hooks and the validation of the arguments and flags.
+	BeforeRun
+	// AfterRun is sent right after the Run function of the command returns,
+	// with the error it returned.
+	AfterRun
+)
+
+func (t EventType) String() string {
+	switch t {
+	case CommandResolved:
+		return "CommandResolved"
+	case FlagsParsed:
+		return "FlagsParsed"
+	case BeforeRun:
+		return "BeforeRun"
+	case AfterRun:
+		return "AfterRun"
+	}
+	return "EventType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Event describes a step of the execution of a command, see OnEvent.
+type Event struct {
+	Type EventType
+	// Command is the command being executed.
+	Command *Command
+	// Args are the arguments of the command: the arguments following its name for
+	// CommandResolved, the arguments left after the flags for the other events.
+	Args []string
+	// Err is the error returned by the Run function, for AfterRun.
+	Err error
+}
+
+// OnEvent registers handlers called with the events of type t sent during the
+// execution of c and all its sub-commands.  It lets libraries attach behavior to a
+// subtree of the program.  The handlers of the parents are called before the ones
+// of their children, in the order they were registered.
+func (c *Command) OnEvent(t EventType, handlers ...func(e Event)) {
+	if c.eventHandlers == nil {
+		c.eventHandlers = map[EventType][]func(e Event){}
+	}
+	c.eventHandlers[t] = append(c.eventHandlers[t], handlers...)
+}
+
+// OnInitialize sets the functions to be called before the execution of c or of
+// one of its sub-commands, after the functions set with the OnInitialize function.
+// They are called with the command being executed, those of the parents first.
+func (c *Command) OnInitialize(y ...func(cmd *Command)) {
+	c.onInitialize = append(c.onInitialize, y...)
+}
+
+// OnFinalize sets the functions to be called after the execution of c or of one
+// of its sub-commands, before the functions set with the OnFinalize function.
+// They are called with the command being executed, those of the parents first.
+func (c *Command) OnFinalize(y ...func(cmd *Command)) {
+	c.onFinalize = append(c.onFinalize, y...)
+}
+
+// sendEvent calls the handlers registered for the events of type t by c and its parents.
+func (c *Command) sendEvent(t EventType, args []string, err error) {
+	e := Event{Type: t, Command: c, Args: args, Err: err}
+	for _, p := range c.lineage() {
+		for _, h := range p.eventHandlers[t] {
+			h(e)
+		}
+	}
+}
+
+// lineage returns the root command down to c.
+func (c *Command) lineage() []*Command {
+	var cmds []*Command
+	for p := c; p != nil; p = p.parent {
+		cmds = append([]*Command{p}, cmds...)
+	}
+	return cmds
+}
@@ -0,0 +1,89 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestCommandLifecycleEvents(t *testing.T) {
+	var calls []string
+	record := func(name string) func(e Event) {
+		return func(e Event) {
+			calls = append(calls, fmt.Sprintf("%s %s %s %v", name, e.Type, e.Command.Name(), e.Args))
+		}
+	}
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: func(*Command, []string) { calls = append(calls, "run") }}
+	otherCmd := &Command{Use: "other", Run: emptyRun}
+	rootCmd.AddCommand(childCmd, otherCmd)
+	childCmd.Flags().Bool("force", false, "")
+	for _, typ := range []EventType{CommandResolved, FlagsParsed, BeforeRun, AfterRun} {
+		rootCmd.OnEvent(typ, record("root"))
+		childCmd.OnEvent(typ, record("child"))
+	}
+	rootCmd.OnInitialize(func(cmd *Command) { calls = append(calls, "root init "+cmd.Name()) })
+	childCmd.OnInitialize(func(cmd *Command) { calls = append(calls, "child init "+cmd.Name()) })
+	childCmd.OnFinalize(func(cmd *Command) { calls = append(calls, "child finalize "+cmd.Name()) })
+
+	if _, err := executeCommand(rootCmd, "child", "--force", "a"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{
+		"root CommandResolved child [--force a]", "child CommandResolved child [--force a]",
+		"root FlagsParsed child [a]", "child FlagsParsed child [a]",
+		"root init child", "child init child",
+		"root BeforeRun child [a]", "child BeforeRun child [a]",
+		"run",
+		"root AfterRun child [a]", "child AfterRun child [a]",
+		"child finalize child",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %q, got %q", expected, calls)
+	}
+
+	// The handlers of a command are not called for the other subtrees.
+	calls = nil
+	if _, err := executeCommand(rootCmd, "other"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = []string{
+		"root CommandResolved other []",
+		"root FlagsParsed other []",
+		"root init other",
+		"root BeforeRun other []",
+		"root AfterRun other []",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %q, got %q", expected, calls)
+	}
+}
+
+func TestAfterRunEventError(t *testing.T) {
+	errFailed := errors.New("failed")
+	rootCmd := &Command{Use: "root", RunE: func(*Command, []string) error { return errFailed }}
+	var got error
+	rootCmd.OnEvent(AfterRun, func(e Event) { got = e.Err })
+
+	if _, err := executeCommand(rootCmd); !errors.Is(err, errFailed) {
+		t.Fatalf("Expected %v, got %v", errFailed, err)
+	}
+	if !errors.Is(got, errFailed) {
+		t.Errorf("Expected the AfterRun event to hold %v, got %v", errFailed, got)
+	}
+}
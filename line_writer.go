@@ -0,0 +1,105 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+)
+
+// PrefixOutputFlagName is the name of the flag added by AddPrefixOutputFlag.
+const PrefixOutputFlagName = "prefix-output"
+
+// AddPrefixOutputFlag adds to c the persistent --prefix-output flag, whose value is
+// written before each line written with a LineWriter by c and its sub-commands.
+// The parts of the prefix between braces are Go time layouts replaced by the time
+// at which the line is written, e.g. "{15:04:05} " prefixes lines with the time.
+func AddPrefixOutputFlag(c *Command) {
+	if c.PersistentFlags().Lookup(PrefixOutputFlagName) == nil {
+		c.PersistentFlags().String(PrefixOutputFlagName, "", "prefix each line of output, {layout} is replaced by the time in the Go layout")
+	}
+}
+
+// LineWriter returns a writer to the output of cmd for long-running commands, such
+// as log tailers, which write their output line by line.  Each complete line is
+// prefixed according to the --prefix-output flag, see AddPrefixOutputFlag, and
+// written at once.  Writing fails with the error of the context of cmd once it is
+// cancelled, e.g. on interrupt with ExecuteWithSignals.
+// Close writes the last line if it does not end with a newline.
+func LineWriter(cmd *Command) io.WriteCloser {
+	lw := &lineWriter{ctx: cmd.Context(), w: cmd.OutOrStdout()}
+	if f := cmd.Flags().Lookup(PrefixOutputFlagName); f != nil {
+		lw.prefix = f.Value.String()
+	}
+	if lw.ctx == nil {
+		lw.ctx = context.Background()
+	}
+	return lw
+}
+
+// timeLayout matches the time layouts of the prefix of a LineWriter.
+var timeLayout = regexp.MustCompile(`\{[^}]*\}`)
+
+type lineWriter struct {
+	ctx    context.Context
+	w      io.Writer
+	prefix string
+	// pending is the incomplete line written last.
+	pending []byte
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	if err := lw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	lw.pending = append(lw.pending, p...)
+	for {
+		i := bytes.IndexByte(lw.pending, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := lw.writeLine(lw.pending[:i+1]); err != nil {
+			return 0, err
+		}
+		lw.pending = lw.pending[i+1:]
+	}
+}
+
+func (lw *lineWriter) Close() error {
+	if len(lw.pending) == 0 {
+		return nil
+	}
+	line := append(lw.pending, '\n')
+	lw.pending = nil
+	return lw.writeLine(line)
+}
+
+// writeLine writes line, which ends with a newline, after the prefix.
+func (lw *lineWriter) writeLine(line []byte) error {
+	if err := lw.ctx.Err(); err != nil {
+		return err
+	}
+	if lw.prefix != "" {
+		now := timeNow()
+		prefix := timeLayout.ReplaceAllStringFunc(lw.prefix, func(layout string) string {
+			return now.Format(layout[1 : len(layout)-1])
+		})
+		line = append([]byte(prefix), line...)
+	}
+	_, err := lw.w.Write(line)
+	return err
+}
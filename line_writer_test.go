@@ -0,0 +1,80 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLineWriter(t *testing.T) {
+	defer func(now func() time.Time) { timeNow = now }(timeNow)
+	timeNow = func() time.Time { return time.Date(2023, 5, 1, 10, 30, 0, 0, time.UTC) }
+
+	rootCmd := &Command{Use: "root"}
+	AddPrefixOutputFlag(rootCmd)
+	logsCmd := &Command{
+		Use: "logs",
+		RunE: func(cmd *Command, args []string) error {
+			w := LineWriter(cmd)
+			fmt.Fprint(w, "first line\nsecond ")
+			fmt.Fprint(w, "line\nlast line")
+			return w.Close()
+		},
+	}
+	rootCmd.AddCommand(logsCmd)
+
+	output, err := executeCommand(rootCmd, "logs")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "first line\nsecond line\nlast line\n"; output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+
+	output, err = executeCommand(rootCmd, "logs", "--prefix-output", "[{15:04:05}] logs: ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "[10:30:00] logs: first line\n[10:30:00] logs: second line\n[10:30:00] logs: last line\n"
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
+
+func TestLineWriterCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rootCmd := &Command{
+		Use: "root",
+		RunE: func(cmd *Command, args []string) error {
+			w := LineWriter(cmd)
+			fmt.Fprint(w, "before\n")
+			cancel()
+			_, err := fmt.Fprint(w, "after\n")
+			return err
+		},
+	}
+	rootCmd.SetContext(ctx)
+
+	output, err := executeCommand(rootCmd)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the error of the context, got %v", err)
+	}
+	checkStringContains(t, output, "before\n")
+	checkStringOmits(t, output, "after")
+}
@@ -0,0 +1,184 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestCommand describes a command in a manifest read by LoadManifest.
+type ManifestCommand struct {
+	Use        string          `json:"use" yaml:"use"`
+	Aliases    []string        `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Short      string          `json:"short,omitempty" yaml:"short,omitempty"`
+	Long       string          `json:"long,omitempty" yaml:"long,omitempty"`
+	Examples   []Example       `json:"examples,omitempty" yaml:"examples,omitempty"`
+	GroupID    string          `json:"group,omitempty" yaml:"group,omitempty"`
+	Groups     []ManifestGroup `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Deprecated string          `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Hidden     bool            `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	// Run is the ID of the handler, passed to LoadManifest, executing the command.
+	Run      string            `json:"run,omitempty" yaml:"run,omitempty"`
+	Flags    []ManifestFlag    `json:"flags,omitempty" yaml:"flags,omitempty"`
+	Commands []ManifestCommand `json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// ManifestGroup describes a group of commands in a manifest.
+type ManifestGroup struct {
+	ID          string `json:"id" yaml:"id"`
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// ManifestFlag describes a flag in a manifest.
+type ManifestFlag struct {
+	Name      string `json:"name" yaml:"name"`
+	Shorthand string `json:"shorthand,omitempty" yaml:"shorthand,omitempty"`
+	// Type is one of the types listed in ManifestFlagTypes; it defaults to "string".
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Default is the default value, in the syntax of the command line.
+	Default    string `json:"default,omitempty" yaml:"default,omitempty"`
+	Usage      string `json:"usage,omitempty" yaml:"usage,omitempty"`
+	Persistent bool   `json:"persistent,omitempty" yaml:"persistent,omitempty"`
+	Required   bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	Hidden     bool   `json:"hidden,omitempty" yaml:"hidden,omitempty"`
+	EnvVar     string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// ManifestFlagTypes are the types of the flags a manifest can define.
+var ManifestFlagTypes = []string{
+	"string", "bool", "int", "int64", "uint", "float64", "duration",
+	"stringSlice", "stringArray", "intSlice",
+}
+
+// LoadManifest builds a tree of commands from a manifest in YAML or JSON whose
+// top-level object describes the root command, as a ManifestCommand, e.g.:
+//
+//	use: app
+//	short: An application
+//	commands:
+//	  - use: serve
+//	    short: Start the server
+//	    run: serve
+//	    flags:
+//	      - {name: port, type: int, default: "8080", usage: port to listen on}
+//
+// The run fields are the IDs of the handlers executing the commands.  Unknown
+// fields, flag types and handler IDs are errors.
+func LoadManifest(r io.Reader, handlers map[string]RunFunc) (*Command, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var m ManifestCommand
+	if err := dec.Decode(&m); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("manifest: empty manifest")
+		}
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	return m.build(handlers)
+}
+
+func (m *ManifestCommand) build(handlers map[string]RunFunc) (*Command, error) {
+	if m.Use == "" {
+		return nil, errors.New("manifest: command without use")
+	}
+	cmd := &Command{
+		Use:        m.Use,
+		Aliases:    m.Aliases,
+		Short:      m.Short,
+		Long:       m.Long,
+		Examples:   m.Examples,
+		GroupID:    m.GroupID,
+		Deprecated: m.Deprecated,
+		Hidden:     m.Hidden,
+	}
+	if m.Run != "" {
+		run, ok := handlers[m.Run]
+		if !ok {
+			return nil, fmt.Errorf("manifest: command %q: unknown handler %q", cmd.Name(), m.Run)
+		}
+		cmd.RunE = run
+	}
+	for _, g := range m.Groups {
+		cmd.AddGroup(&Group{ID: g.ID, Title: g.Title, Description: g.Description})
+	}
+	for _, f := range m.Flags {
+		if err := f.define(cmd); err != nil {
+			return nil, fmt.Errorf("manifest: command %q: %w", cmd.Name(), err)
+		}
+	}
+	for i := range m.Commands {
+		sub, err := m.Commands[i].build(handlers)
+		if err != nil {
+			return nil, err
+		}
+		cmd.AddCommand(sub)
+	}
+	return cmd, nil
+}
+
+func (mf *ManifestFlag) define(cmd *Command) error {
+	fs := cmd.Flags()
+	if mf.Persistent {
+		fs = cmd.PersistentFlags()
+	}
+	if fs.Lookup(mf.Name) != nil {
+		return fmt.Errorf("flag %q defined twice", mf.Name)
+	}
+	switch mf.Type {
+	case "", "string":
+		fs.StringP(mf.Name, mf.Shorthand, "", mf.Usage)
+	case "bool":
+		fs.BoolP(mf.Name, mf.Shorthand, false, mf.Usage)
+	case "int":
+		fs.IntP(mf.Name, mf.Shorthand, 0, mf.Usage)
+	case "int64":
+		fs.Int64P(mf.Name, mf.Shorthand, 0, mf.Usage)
+	case "uint":
+		fs.UintP(mf.Name, mf.Shorthand, 0, mf.Usage)
+	case "float64":
+		fs.Float64P(mf.Name, mf.Shorthand, 0, mf.Usage)
+	case "duration":
+		fs.DurationP(mf.Name, mf.Shorthand, 0, mf.Usage)
+	case "stringSlice":
+		fs.StringSliceP(mf.Name, mf.Shorthand, nil, mf.Usage)
+	case "stringArray":
+		fs.StringArrayP(mf.Name, mf.Shorthand, nil, mf.Usage)
+	case "intSlice":
+		fs.IntSliceP(mf.Name, mf.Shorthand, nil, mf.Usage)
+	default:
+		return fmt.Errorf("flag %q has unknown type %q", mf.Name, mf.Type)
+	}
+
+	f := fs.Lookup(mf.Name)
+	f.Hidden = mf.Hidden
+	if mf.Default != "" {
+		if err := f.Value.Set(mf.Default); err != nil {
+			return fmt.Errorf("flag %q has invalid default %q: %w", mf.Name, mf.Default, err)
+		}
+		f.DefValue = f.Value.String()
+	}
+	if mf.Required {
+		_ = MarkFlagRequired(fs, mf.Name)
+	}
+	if mf.EnvVar != "" {
+		bindFlagEnv(f, mf.EnvVar)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testManifest = `
+use: app
+short: An application
+groups:
+  - {id: core, title: "Core Commands:"}
+flags:
+  - {name: verbose, shorthand: v, type: bool, persistent: true, usage: verbose output}
+commands:
+  - use: serve [dir]
+    short: Start the server
+    aliases: [s]
+    group: core
+    run: serve
+    flags:
+      - {name: port, type: int, default: "8080", usage: port to listen on}
+      - {name: timeout, type: duration, default: 5s}
+      - {name: tags, type: stringSlice, default: "a,b"}
+      - {name: token, required: true, env: APP_TOKEN}
+`
+
+func TestLoadManifest(t *testing.T) {
+	var gotArgs []string
+	var gotPort int
+	root, err := LoadManifest(strings.NewReader(testManifest), map[string]RunFunc{
+		"serve": func(cmd *Command, args []string) error {
+			gotArgs = args
+			gotPort, _ = cmd.Flags().GetInt("port")
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := executeCommand(root, "s", "--token", "x", "-v", "www"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotPort != 8080 || strings.Join(gotArgs, " ") != "www" {
+		t.Errorf("Unexpected port %d and args %v", gotPort, gotArgs)
+	}
+
+	serve, _, _ := root.Find([]string{"serve"})
+	if d, _ := serve.Flags().GetDuration("timeout"); d != 5*time.Second {
+		t.Errorf("Expected the default timeout, got %v", d)
+	}
+	if tags := serve.Flag("tags").DefValue; tags != "[a,b]" {
+		t.Errorf("Expected default tags [a,b], got %s", tags)
+	}
+	if !isRequiredFlag(serve.Flag("token")) {
+		t.Errorf("Expected token to be required")
+	}
+	if env := serve.Flag("token").Annotations[FlagEnvVarAnnotation]; len(env) != 1 || env[0] != "APP_TOKEN" {
+		t.Errorf("Expected token to be bound to APP_TOKEN, got %v", env)
+	}
+
+	output, err := executeCommand(root, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "Core Commands:\n  serve       Start the server")
+}
+
+func TestLoadManifestJSON(t *testing.T) {
+	root, err := LoadManifest(strings.NewReader(`{"use": "app", "commands": [{"use": "version", "hidden": true}]}`), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cmd, _, err := root.Find([]string{"version"}); err != nil || !cmd.Hidden {
+		t.Errorf("Expected a hidden version command, got %v (%v)", cmd, err)
+	}
+}
+
+func TestLoadManifestErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		manifest string
+		expected string
+	}{
+		{"empty", ``, "manifest: empty manifest"},
+		{"unknown field", "use: app\nsummary: x\n", "field summary not found"},
+		{"missing use", "short: x\n", "manifest: command without use"},
+		{"unknown handler", "use: app\nrun: missing\n", `manifest: command "app": unknown handler "missing"`},
+		{"unknown type", "use: app\nflags: [{name: n, type: complex}]\n", `flag "n" has unknown type "complex"`},
+		{"invalid default", "use: app\nflags: [{name: n, type: int, default: x}]\n", `flag "n" has invalid default "x"`},
+		{"duplicate flag", "use: app\nflags: [{name: n}, {name: n}]\n", `flag "n" defined twice`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadManifest(strings.NewReader(tc.manifest), nil)
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+			checkStringContains(t, err.Error(), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the latency histogram buckets
+// used by NewMetrics.
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Metrics records the number of invocations, errors and the latency of the
+// commands executed in a long-running program, such as a REPL or a daemon.
+// It implements expvar.Var, so that it can be exposed with expvar.Publish, and
+// Snapshot can be used to feed other monitoring systems, e.g. a Prometheus collector.
+type Metrics struct {
+	mutex    sync.Mutex
+	buckets  []time.Duration
+	commands map[string]*CommandMetrics
+}
+
+// CommandMetrics holds the metrics of one command.
+type CommandMetrics struct {
+	// Path is the path of the command, as returned by CommandPath.
+	Path string `json:"path"`
+	// Invocations is the number of times the command was executed.
+	Invocations uint64 `json:"invocations"`
+	// Errors is the number of executions which returned an error.
+	Errors uint64 `json:"errors"`
+	// LatencySum is the total time spent executing the command.
+	LatencySum time.Duration `json:"latency_sum"`
+	// LatencyBuckets holds the cumulative number of executions which took at most
+	// the matching duration of Metrics buckets.
+	LatencyBuckets []LatencyBucket `json:"latency_buckets"`
+}
+
+// LatencyBucket is a bucket of a latency histogram.
+type LatencyBucket struct {
+	UpperBound time.Duration `json:"le"`
+	Count      uint64        `json:"count"`
+}
+
+// NewMetrics returns a Metrics recording latencies with the given histogram
+// buckets, or DefaultLatencyBuckets if none are given.
+func NewMetrics(buckets ...time.Duration) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]time.Duration{}, buckets...)
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+	return &Metrics{buckets: buckets, commands: map[string]*CommandMetrics{}}
+}
+
+// SetMetrics sets the Metrics recording the executions of c and its children.
+func (c *Command) SetMetrics(m *Metrics) {
+	c.metrics = m
+}
+
+// Metrics returns the Metrics of the command or of its closest parent setting one.
+func (c *Command) Metrics() *Metrics {
+	if c.metrics != nil {
+		return c.metrics
+	}
+	if c.HasParent() {
+		return c.parent.Metrics()
+	}
+	return nil
+}
+
+// Observe records an execution of the command at path which took latency.
+func (m *Metrics) Observe(path string, latency time.Duration, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	cm, ok := m.commands[path]
+	if !ok {
+		cm = &CommandMetrics{Path: path, LatencyBuckets: make([]LatencyBucket, len(m.buckets))}
+		for i, b := range m.buckets {
+			cm.LatencyBuckets[i].UpperBound = b
+		}
+		m.commands[path] = cm
+	}
+	cm.Invocations++
+	if err != nil {
+		cm.Errors++
+	}
+	cm.LatencySum += latency
+	for i := range cm.LatencyBuckets {
+		if latency <= cm.LatencyBuckets[i].UpperBound {
+			cm.LatencyBuckets[i].Count++
+		}
+	}
+}
+
+// Snapshot returns a copy of the metrics of all the executed commands, sorted by path.
+func (m *Metrics) Snapshot() []CommandMetrics {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	snapshot := make([]CommandMetrics, 0, len(m.commands))
+	for _, cm := range m.commands {
+		c := *cm
+		c.LatencyBuckets = append([]LatencyBucket{}, cm.LatencyBuckets...)
+		snapshot = append(snapshot, c)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Path < snapshot[j].Path })
+	return snapshot
+}
+
+// String returns the metrics in JSON, as required by expvar.Var.
+func (m *Metrics) String() string {
+	data, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// observeMetrics records the execution of the command in its Metrics, if any.
+// Requesting the help is not considered as an error.
+func (c *Command) observeMetrics(start time.Time, err error) {
+	if errors.Is(err, flag.ErrHelp) {
+		err = nil
+	}
+	if m := c.Metrics(); m != nil {
+		m.Observe(c.CommandPath(), time.Since(start), err)
+	}
+}
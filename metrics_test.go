@@ -0,0 +1,69 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	okCmd := &Command{Use: "ok", Run: emptyRun}
+	failCmd := &Command{Use: "fail", RunE: func(*Command, []string) error { return errors.New("failed") }}
+	rootCmd.AddCommand(okCmd, failCmd)
+
+	metrics := NewMetrics(time.Hour, time.Nanosecond)
+	rootCmd.SetMetrics(metrics)
+
+	for _, args := range [][]string{{"ok"}, {"ok"}, {"ok", "--help"}, {"fail"}} {
+		_, _ = executeCommand(rootCmd, args...)
+	}
+
+	snapshot := metrics.Snapshot()
+	if len(snapshot) != 2 {
+		t.Fatalf("expected metrics for 2 commands, got %+v", snapshot)
+	}
+	fail, ok := snapshot[0], snapshot[1]
+	if fail.Path != "root fail" || fail.Invocations != 1 || fail.Errors != 1 {
+		t.Errorf("unexpected metrics for fail: %+v", fail)
+	}
+	if ok.Path != "root ok" || ok.Invocations != 3 || ok.Errors != 0 {
+		t.Errorf("unexpected metrics for ok: %+v", ok)
+	}
+	if len(ok.LatencyBuckets) != 2 || ok.LatencyBuckets[0].UpperBound != time.Nanosecond || ok.LatencyBuckets[1].Count != 3 {
+		t.Errorf("unexpected latency buckets: %+v", ok.LatencyBuckets)
+	}
+}
+
+func TestMetricsPublish(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.Observe("root child", 3*time.Millisecond, nil)
+	expvar.Publish("cobra_test_metrics", metrics)
+
+	var decoded []CommandMetrics
+	if err := json.Unmarshal([]byte(expvar.Get("cobra_test_metrics").String()), &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].Invocations != 1 || decoded[0].LatencySum != 3*time.Millisecond {
+		t.Errorf("unexpected published metrics: %+v", decoded)
+	}
+	if decoded[0].LatencyBuckets[0].Count != 1 {
+		t.Errorf("expected the latency to be in the first bucket: %+v", decoded[0].LatencyBuckets)
+	}
+}
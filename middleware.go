@@ -0,0 +1,47 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+// RunFunc executes cmd with args, the arguments following the name of the command,
+// including its flags which are not parsed yet.
+type RunFunc func(cmd *Command, args []string) error
+
+// Middleware wraps the execution of commands with logic shared by all of them, such as
+// logging or authorization.  It returns a RunFunc which calls next to continue the
+// execution, or returns without calling it to stop it.  The errors returned by next,
+// including flag.ErrHelp when help was requested, should be returned unchanged unless
+// the middleware means to handle them.
+type Middleware func(next RunFunc) RunFunc
+
+// Use adds middlewares wrapping the execution of cmd and all its sub-commands,
+// i.e. the parsing of the flags, the hooks and the Run function.  The middlewares
+// of the parents wrap the ones of their children, and the first middleware added
+// to a command wraps the following ones.
+func Use(cmd *Command, middlewares ...Middleware) {
+	cmd.middlewares = append(cmd.middlewares, middlewares...)
+}
+
+// executeWithMiddlewares executes c with args through the middlewares of c and its parents.
+func (c *Command) executeWithMiddlewares(args []string) error {
+	run := RunFunc(func(cmd *Command, args []string) error {
+		return cmd.execute(args)
+	})
+	for p := c; p != nil; p = p.parent {
+		for i := len(p.middlewares) - 1; i >= 0; i-- {
+			run = p.middlewares[i](run)
+		}
+	}
+	return run(c, args)
+}
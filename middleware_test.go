@@ -0,0 +1,69 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMiddlewares(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next RunFunc) RunFunc {
+			return func(cmd *Command, args []string) error {
+				calls = append(calls, name+" before "+cmd.Name())
+				err := next(cmd, args)
+				calls = append(calls, name+" after "+cmd.Name())
+				return err
+			}
+		}
+	}
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: func(*Command, []string) { calls = append(calls, "run") }}
+	rootCmd.AddCommand(childCmd)
+	Use(rootCmd, record("first"), record("second"))
+	Use(childCmd, record("child"))
+
+	if _, err := executeCommand(rootCmd, "child"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{
+		"first before child", "second before child", "child before child",
+		"run",
+		"child after child", "second after child", "first after child",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	errDenied := errors.New("permission denied")
+	var ran bool
+	rootCmd := &Command{Use: "root", Run: func(*Command, []string) { ran = true }, SilenceUsage: true}
+	Use(rootCmd, func(next RunFunc) RunFunc {
+		return func(cmd *Command, args []string) error {
+			return errDenied
+		}
+	})
+
+	output, err := executeCommand(rootCmd)
+	if !errors.Is(err, errDenied) || ran {
+		t.Errorf("Expected the middleware to stop the execution with its error, got %v", err)
+	}
+	checkStringContains(t, output, "Error: permission denied")
+}
@@ -0,0 +1,38 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bufio"
+)
+
+// bufferOutput replaces the output of c with a buffer if BufferOutput is set, and
+// ignores SIGPIPE meanwhile so that a broken pipe is reported as an error which can
+// be silenced.  It returns the function which flushes the buffer and restores the
+// output and the handling of SIGPIPE.
+func (c *Command) bufferOutput() func() error {
+	if !c.BufferOutput {
+		return func() error { return nil }
+	}
+	previous := c.outWriter
+	buffer := bufio.NewWriter(c.OutOrStdout())
+	c.outWriter = buffer
+	restoreSIGPIPE := ignoreSIGPIPE()
+	return func() error {
+		defer restoreSIGPIPE()
+		c.outWriter = previous
+		return buffer.Flush()
+	}
+}
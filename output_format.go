@@ -0,0 +1,168 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFlagName is the name of the flag added by AddOutputFlag.
+const OutputFlagName = "output"
+
+// The output formats supported by Print.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// outputFormats are the formats supported by Print, in order.
+var outputFormats = []string{OutputTable, OutputJSON, OutputYAML}
+
+// AddOutputFlag adds to c the persistent -o/--output flag selecting the format in
+// which Print renders values: OutputTable, OutputJSON or OutputYAML.  It accepts the
+// given formats, or all of them if none is given; the first one is the default.
+func AddOutputFlag(c *Command, formats ...string) {
+	if len(formats) == 0 {
+		formats = outputFormats
+	}
+	if c.PersistentFlags().Lookup(OutputFlagName) != nil {
+		return
+	}
+	value := &outputFormatValue{format: formats[0], formats: formats}
+	c.PersistentFlags().VarP(value, OutputFlagName, "o", fmt.Sprintf("output format (%s)", strings.Join(formats, ", ")))
+	_ = c.RegisterFlagCompletionFunc(OutputFlagName, FixedCompletions(formats, ShellCompDirectiveNoFileComp))
+}
+
+// outputFormatValue is the value of the --output flag, which only accepts some formats.
+type outputFormatValue struct {
+	format  string
+	formats []string
+}
+
+func (v *outputFormatValue) String() string { return v.format }
+
+func (v *outputFormatValue) Type() string { return "format" }
+
+func (v *outputFormatValue) Set(s string) error {
+	if !stringInSlice(s, v.formats) {
+		return fmt.Errorf("must be one of %s", strings.Join(v.formats, ", "))
+	}
+	v.format = s
+	return nil
+}
+
+// Print writes v to the output of cmd in the format selected by the --output flag,
+// see AddOutputFlag, or as a table if cmd has no such flag.
+//
+// As a table, v is a struct, a pointer to a struct or a slice of them: each struct
+// is a row and each exported field a column, whose header is the value of the
+// `table` tag of the field or its name in upper case.  Fields with the tag
+// `table:"-"` are omitted.  Other values are printed one per line.
+func Print(cmd *Command, v interface{}) error {
+	format := OutputTable
+	if f := cmd.Flag(OutputFlagName); f != nil {
+		format = f.Value.String()
+	}
+	w := cmd.OutOrStdout()
+	switch format {
+	case OutputJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case OutputYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case OutputTable:
+		return printTable(w, v)
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+// printTable writes v to w as a table, see Print.
+func printTable(w io.Writer, v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	var rows []reflect.Value
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, reflect.Indirect(rv.Index(i)))
+		}
+	} else if rv.IsValid() {
+		rows = append(rows, rv)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	var fields []int
+	if typ := elemType(rv); typ != nil && typ.Kind() == reflect.Struct {
+		var headers []string
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			header := field.Tag.Get("table")
+			if !field.IsExported() || header == "-" {
+				continue
+			}
+			if header == "" {
+				header = strings.ToUpper(field.Name)
+			}
+			fields = append(fields, i)
+			headers = append(headers, header)
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		if !row.IsValid() {
+			continue
+		}
+		if row.Kind() != reflect.Struct {
+			fmt.Fprintln(tw, row.Interface())
+			continue
+		}
+		cells := make([]string, 0, len(fields))
+		for _, i := range fields {
+			cells = append(cells, fmt.Sprint(row.Field(i).Interface()))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// elemType returns the type of the rows of a table printing rv.
+func elemType(rv reflect.Value) reflect.Type {
+	if !rv.IsValid() {
+		return nil
+	}
+	typ := rv.Type()
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
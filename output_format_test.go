@@ -0,0 +1,100 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"strings"
+	"testing"
+)
+
+type outputItem struct {
+	Name    string `json:"name" yaml:"name" table:"NAME"`
+	Size    int    `json:"size" yaml:"size"`
+	Secret  string `json:"-" yaml:"-" table:"-"`
+	private bool
+}
+
+func newOutputTree() *Command {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	listCmd := &Command{
+		Use: "list",
+		RunE: func(cmd *Command, args []string) error {
+			return Print(cmd, []outputItem{{Name: "a", Size: 1, Secret: "x"}, {Name: "longer", Size: 22}})
+		},
+	}
+	rootCmd.AddCommand(listCmd)
+	AddOutputFlag(rootCmd)
+	return rootCmd
+}
+
+func TestPrintFormats(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"list"}, "NAME     SIZE\na        1\nlonger   22\n"},
+		{[]string{"list", "-o", "json"}, "[\n  {\n    \"name\": \"a\",\n    \"size\": 1\n  },\n  {\n    \"name\": \"longer\",\n    \"size\": 22\n  }\n]\n"},
+		{[]string{"list", "--output", "yaml"}, "- name: a\n  size: 1\n- name: longer\n  size: 22\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			output, err := executeCommand(newOutputTree(), tc.args...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if output != tc.expected {
+				t.Errorf("expected: %q, got: %q", tc.expected, output)
+			}
+		})
+	}
+}
+
+func TestPrintTableValues(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	var b strings.Builder
+	rootCmd.SetOut(&b)
+
+	assertNoErr(t, Print(rootCmd, &outputItem{Name: "a", Size: 1}))
+	assertNoErr(t, Print(rootCmd, []string{"x", "y"}))
+	expected := "NAME   SIZE\na      1\nx\ny\n"
+	if b.String() != expected {
+		t.Errorf("expected: %q, got: %q", expected, b.String())
+	}
+}
+
+func TestOutputFlagFormats(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	AddOutputFlag(rootCmd, OutputJSON, OutputYAML)
+
+	output, err := executeCommand(rootCmd, "-o", "table")
+	if err == nil {
+		t.Fatal("Expected an error for a format which is not accepted")
+	}
+	checkStringContains(t, output, `invalid argument "table" for "-o, --output" flag: must be one of json, yaml`)
+
+	rootCmd = &Command{Use: "root", Run: emptyRun}
+	AddOutputFlag(rootCmd, OutputJSON, OutputYAML)
+	if f := rootCmd.Flag(OutputFlagName); f.DefValue != OutputJSON {
+		t.Errorf("Expected the first format to be the default, got %q", f.DefValue)
+	}
+	output, err = executeCommand(rootCmd, ShellCompNoDescRequestCmd, "--output", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{"json", "yaml", ":4", "Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
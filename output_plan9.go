@@ -0,0 +1,27 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build plan9
+// +build plan9
+
+package cobra
+
+import (
+	"strings"
+)
+
+// isBrokenPipe returns true if err is due to writing to a pipe whose reader went away.
+func isBrokenPipe(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "hungup channel")
+}
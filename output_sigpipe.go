@@ -0,0 +1,39 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !plan9 && !js
+// +build !plan9,!js
+
+package cobra
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ignoreSIGPIPE ignores the SIGPIPE signal until the returned function is called, so
+// that writing to a broken pipe on the standard output fails with EPIPE instead of
+// killing the program.  It does nothing if the signal is already ignored.
+// The signal is received on a private channel rather than ignored, so that the
+// channels of the application receiving it are kept, and stopping it restores the
+// previous handling of the signal.
+func ignoreSIGPIPE() func() {
+	if signal.Ignored(syscall.SIGPIPE) {
+		return func() {}
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGPIPE)
+	return func() { signal.Stop(c) }
+}
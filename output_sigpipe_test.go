@@ -0,0 +1,91 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBufferOutputClosedPipe(t *testing.T) {
+	if os.Getenv("COBRA_TEST_CLOSED_PIPE") != "" {
+		// Executed by the test below with a closed pipe as standard output.
+		rootCmd := &Command{
+			Use:          "root",
+			BufferOutput: os.Getenv("COBRA_TEST_CLOSED_PIPE") == "buffered",
+			RunE: func(cmd *Command, _ []string) error {
+				_, err := fmt.Fprint(cmd.OutOrStdout(), strings.Repeat("x", 100000))
+				return err
+			},
+		}
+		rootCmd.SetArgs([]string{})
+		if err := rootCmd.Execute(); err != nil {
+			os.Exit(3)
+		}
+		os.Exit(0)
+	}
+
+	for _, mode := range []string{"buffered", "unbuffered"} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		r.Close()
+
+		errOut := new(bytes.Buffer)
+		cmd := exec.Command(os.Args[0], "-test.run=^TestBufferOutputClosedPipe$")
+		cmd.Env = append(os.Environ(), "COBRA_TEST_CLOSED_PIPE="+mode)
+		cmd.Stdout = w
+		cmd.Stderr = errOut
+		err = cmd.Run()
+		w.Close()
+
+		exitErr, _ := err.(*exec.ExitError)
+		switch {
+		case mode == "buffered" && err != nil:
+			t.Errorf("Expected a broken pipe to be silent, got %v and %q", err, errOut.String())
+		case mode == "unbuffered" && (exitErr == nil || exitErr.ExitCode() != -1):
+			t.Errorf("Expected the program to be killed by SIGPIPE without BufferOutput, got %v", err)
+		}
+	}
+}
+
+func TestIgnoreSIGPIPEKeepsNotify(t *testing.T) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGPIPE)
+	defer signal.Stop(c)
+
+	restore := ignoreSIGPIPE()
+	restore()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGPIPE); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Error("Expected SIGPIPE to still be delivered to the channel of the application")
+	}
+}
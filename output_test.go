@@ -0,0 +1,87 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !plan9
+// +build !plan9
+
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// brokenPipeWriter fails like a pipe whose reader went away.
+type brokenPipeWriter struct{}
+
+func (brokenPipeWriter) Write([]byte) (int, error) {
+	return 0, fmt.Errorf("write |1: %w", syscall.EPIPE)
+}
+
+func TestBufferOutput(t *testing.T) {
+	writes := 0
+	out := new(bytes.Buffer)
+	rootCmd := &Command{
+		Use:          "root",
+		BufferOutput: true,
+		Run: func(cmd *Command, _ []string) {
+			cmd.Print("one ")
+			writes = out.Len()
+			cmd.Print("two")
+		},
+	}
+	rootCmd.SetOut(out)
+	rootCmd.SetArgs([]string{})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if writes != 0 {
+		t.Errorf("Expected the output to be buffered, %d bytes were written", writes)
+	}
+	if out.String() != "one two" {
+		t.Errorf("Expected the output to be flushed, got %q", out.String())
+	}
+	if rootCmd.OutOrStdout() != out {
+		t.Error("Expected the output to be restored")
+	}
+}
+
+func TestBufferOutputBrokenPipe(t *testing.T) {
+	for _, buffered := range []bool{true, false} {
+		errOut := new(bytes.Buffer)
+		rootCmd := &Command{
+			Use:          "root",
+			BufferOutput: buffered,
+			RunE: func(cmd *Command, _ []string) error {
+				_, err := fmt.Fprint(cmd.OutOrStdout(), strings.Repeat("x", 10000))
+				return err
+			},
+		}
+		rootCmd.SetOut(brokenPipeWriter{})
+		rootCmd.SetErr(errOut)
+		rootCmd.SetArgs([]string{})
+
+		err := rootCmd.Execute()
+		if buffered && (err != nil || errOut.Len() != 0) {
+			t.Errorf("Expected a broken pipe to be silent, got %v and %q", err, errOut.String())
+		}
+		if !buffered && err == nil {
+			t.Error("Expected the broken pipe to be reported without BufferOutput")
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parameter is a named positional argument of a command, such as <key> in
+// "app config get <key>".  The parameters of a command are the first positional
+// arguments, in order, and must all be given.
+type Parameter struct {
+	// Name is the name of the parameter, shown as <name> in the usage line.
+	Name string
+	// ValidArgsFunction provides the completions of the parameter.
+	// When nil, the ValidArgsFunction of the command is used.
+	ValidArgsFunction func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective)
+}
+
+// Param returns the value of the parameter called name of cmd, or an empty string
+// if there is no such parameter.  It is meant to be called from the *Run functions.
+func Param(cmd *Command, name string) string {
+	return cmd.params[name]
+}
+
+// bindParams stores the values of the parameters of the command and returns the
+// remaining positional arguments.
+func (c *Command) bindParams(args []string) ([]string, error) {
+	c.params = nil
+	if len(c.Params) == 0 {
+		return args, nil
+	}
+	if len(args) < len(c.Params) {
+		return nil, fmt.Errorf("missing parameter <%s> for %q", c.Params[len(args)].Name, c.CommandPath())
+	}
+	c.params = make(map[string]string, len(c.Params))
+	for i, p := range c.Params {
+		c.params[p.Name] = args[i]
+	}
+	return args[len(c.Params):], nil
+}
+
+// paramsUseLine returns the parameters of the command which are not already
+// part of use, formatted for the usage line.
+func (c *Command) paramsUseLine(use string) string {
+	var b strings.Builder
+	for _, p := range c.Params {
+		param := "<" + p.Name + ">"
+		if !strings.Contains(use, param) {
+			b.WriteString(" " + param)
+		}
+	}
+	return b.String()
+}
+
+// paramCompletionFunc returns the completion function of the parameter at the
+// position of the argument being completed, if any.
+func (c *Command) paramCompletionFunc(args []string) func(cmd *Command, args []string, toComplete string) ([]string, ShellCompDirective) {
+	if len(args) < len(c.Params) {
+		return c.Params[len(args)].ValidArgsFunction
+	}
+	return nil
+}
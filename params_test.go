@@ -0,0 +1,109 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getParamsCmd(key *string, rest *[]string) *Command {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	configCmd := &Command{Use: "config"}
+	getCmd := &Command{
+		Use: "get",
+		Params: []Parameter{{
+			Name:              "key",
+			ValidArgsFunction: FixedCompletions([]string{"region", "project"}, ShellCompDirectiveNoFileComp),
+		}},
+		Args: MaximumNArgs(1),
+		Run: func(cmd *Command, args []string) {
+			*key = Param(cmd, "key")
+			*rest = args
+		},
+		ValidArgsFunction: FixedCompletions([]string{"extra"}, ShellCompDirectiveNoFileComp),
+	}
+	configCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(configCmd)
+	return rootCmd
+}
+
+func TestParams(t *testing.T) {
+	var key string
+	var rest []string
+	rootCmd := getParamsCmd(&key, &rest)
+
+	if _, err := executeCommand(rootCmd, "config", "get", "region", "more"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if key != "region" {
+		t.Errorf("Expected key to be %q, got %q", "region", key)
+	}
+	if !reflect.DeepEqual(rest, []string{"more"}) {
+		t.Errorf("Expected the remaining args to be [more], got %q", rest)
+	}
+
+	_, err := executeCommand(rootCmd, "config", "get")
+	if err == nil || err.Error() != `missing parameter <key> for "app config get"` {
+		t.Errorf("Expected a missing parameter error, got %v", err)
+	}
+
+	if Param(rootCmd, "key") != "" {
+		t.Error("Expected no parameter on a command which does not declare it")
+	}
+}
+
+func TestParamsUseLine(t *testing.T) {
+	var key string
+	var rest []string
+	rootCmd := getParamsCmd(&key, &rest)
+	getCmd, _, _ := rootCmd.Find([]string{"config", "get"})
+
+	if useLine := getCmd.UseLine(); useLine != "app config get <key>" {
+		t.Errorf("Unexpected use line %q", useLine)
+	}
+
+	getCmd.Use = "get <key> [extra]"
+	if useLine := getCmd.UseLine(); useLine != "app config get <key> [extra]" {
+		t.Errorf("Unexpected use line %q", useLine)
+	}
+}
+
+func TestParamsCompletion(t *testing.T) {
+	var key string
+	var rest []string
+	rootCmd := getParamsCmd(&key, &rest)
+
+	output, err := executeCommand(rootCmd, ShellCompNoDescRequestCmd, "config", "get", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"region",
+		"project",
+		":4",
+		"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+
+	// Past the parameters, the completion function of the command is used.
+	output, err = executeCommand(rootCmd, ShellCompNoDescRequestCmd, "config", "get", "region", "")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "extra\n:4\n")
+}
@@ -0,0 +1,140 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// PluginOptions configures the discovery of plugins, see EnablePlugins.
+type PluginOptions struct {
+	// Prefix is the prefix of the name of the plugin executables.
+	// It defaults to the name of the root command followed by a dash, e.g. "kubectl-".
+	Prefix string
+	// Dirs are the directories searched for plugins, in order.
+	// They default to the directories of the PATH environment variable.
+	Dirs []string
+}
+
+// Plugin is an external executable providing a sub-command of the program.
+type Plugin struct {
+	// Name is the name of the sub-command, i.e. the name of the executable without
+	// the prefix.
+	Name string
+	// Path is the path of the executable.
+	Path string
+}
+
+// EnablePlugins makes the root command c execute, as git and kubectl do, the
+// executable named <prefix><name> found in the plugin directories when its first
+// argument <name> is not one of its sub-commands.  The plugin receives the following
+// arguments, the environment, and the input and outputs of c, and the execution
+// fails with an ExitError holding its exit code if it fails.
+func (c *Command) EnablePlugins(opts PluginOptions) {
+	c.pluginOptions = &opts
+}
+
+// Plugins returns the plugins which can be executed by c, sorted by name, e.g. to
+// list them in the help.  When several directories hold a plugin with the same
+// name, the first one is returned.  It returns nil if plugins are not enabled.
+func (c *Command) Plugins() []Plugin {
+	if c.pluginOptions == nil {
+		return nil
+	}
+	prefix := c.pluginPrefix()
+	seen := map[string]bool{}
+	var plugins []Plugin
+	for _, dir := range c.pluginDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			name = strings.TrimPrefix(name, prefix)
+			if name == "" || seen[name] {
+				continue
+			}
+			if path, err := exec.LookPath(filepath.Join(dir, entry.Name())); err == nil {
+				seen[name] = true
+				plugins = append(plugins, Plugin{Name: name, Path: path})
+			}
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// findPlugin returns the path of the plugin to execute for args, if plugins are
+// enabled and the first argument is not a sub-command of c.
+func (c *Command) findPlugin(args []string) (string, bool) {
+	if c.pluginOptions == nil || len(args) == 0 || isCompletionRequest(args) {
+		return "", false
+	}
+	name := args[0]
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\`) {
+		return "", false
+	}
+	if c.hasSubCommand(name) {
+		return "", false
+	}
+	for _, dir := range c.pluginDirs() {
+		if path, err := exec.LookPath(filepath.Join(dir, c.pluginPrefix()+name)); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// executePlugin executes the plugin at path with args.
+func (c *Command) executePlugin(path string, args []string) error {
+	cmd := exec.CommandContext(c.Context(), path, args...)
+	cmd.Env = os.Environ()
+	cmd.Stdin = c.InOrStdin()
+	cmd.Stdout = c.OutOrStdout()
+	cmd.Stderr = c.ErrOrStderr()
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitErr.ExitCode()}
+	}
+	return err
+}
+
+func (c *Command) pluginPrefix() string {
+	if c.pluginOptions.Prefix != "" {
+		return c.pluginOptions.Prefix
+	}
+	return c.Name() + "-"
+}
+
+func (c *Command) pluginDirs() []string {
+	if c.pluginOptions.Dirs != nil {
+		return c.pluginOptions.Dirs
+	}
+	return filepath.SplitList(os.Getenv("PATH"))
+}
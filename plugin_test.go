@@ -0,0 +1,123 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writePlugin writes a shell script named name in dir.
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func newPluginTree(t *testing.T) (*Command, []string) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("plugins are shell scripts")
+	}
+	dirs := []string{t.TempDir(), t.TempDir()}
+	writePlugin(t, dirs[0], "root-hello", `echo "hello $* from $PLUGIN_TEST_VAR"`)
+	writePlugin(t, dirs[0], "root-fail", `echo failing >&2; exit 3`)
+	writePlugin(t, dirs[1], "root-hello", `echo shadowed`)
+	writePlugin(t, dirs[1], "root-child", `echo shadowed by the child command`)
+	writePlugin(t, dirs[1], "other-tool", `echo not a plugin`)
+	if err := os.WriteFile(filepath.Join(dirs[1], "root-data"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "child", Short: "A child", Run: emptyRun})
+	rootCmd.EnablePlugins(PluginOptions{Dirs: dirs})
+	return rootCmd, dirs
+}
+
+func TestPlugins(t *testing.T) {
+	rootCmd, dirs := newPluginTree(t)
+
+	expected := []Plugin{
+		{Name: "child", Path: filepath.Join(dirs[1], "root-child")},
+		{Name: "fail", Path: filepath.Join(dirs[0], "root-fail")},
+		{Name: "hello", Path: filepath.Join(dirs[0], "root-hello")},
+	}
+	if got := rootCmd.Plugins(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if plugins := (&Command{Use: "root"}).Plugins(); plugins != nil {
+		t.Errorf("Expected no plugins when they are not enabled, got %v", plugins)
+	}
+}
+
+func TestPluginExecution(t *testing.T) {
+	rootCmd, _ := newPluginTree(t)
+	t.Setenv("PLUGIN_TEST_VAR", "env")
+
+	output, err := executeCommand(rootCmd, "hello", "--name", "world")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output != "hello --name world from env\n" {
+		t.Errorf("Unexpected output %q", output)
+	}
+
+	output, err = executeCommand(rootCmd, "fail")
+	if code := ExitCode(err); code != 3 {
+		t.Errorf("Expected the exit code of the plugin, got %d (%v)", code, err)
+	}
+	if output != "failing\n" {
+		t.Errorf("Expected only the output of the plugin, got %q", output)
+	}
+
+	// Sub-commands win over plugins.
+	output, err = executeCommand(rootCmd, "child")
+	if err != nil || output != "" {
+		t.Errorf("Expected the child command to be executed, got %q (%v)", output, err)
+	}
+
+	output, err = executeCommand(rootCmd, "unknown")
+	if err == nil {
+		t.Error("Expected an error for an unknown command")
+	}
+	checkStringContains(t, output, `unknown command "unknown" for "root"`)
+}
+
+func TestPluginCompletion(t *testing.T) {
+	rootCmd, _ := newPluginTree(t)
+
+	output, err := executeCommand(rootCmd, ShellCompRequestCmd, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := strings.Join([]string{
+		"child\tA child",
+		"completion\tGenerate the autocompletion script for the specified shell",
+		"help\tHelp about any command",
+		"fail\tplugin",
+		"hello\tplugin",
+		":4",
+		"Completion ended with directive: ShellCompDirectiveNoFileComp", ""}, "\n")
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
@@ -78,6 +78,7 @@ filter __%[1]s_escapeStringWithSpecialChars {
     $ShellCompDirectiveFilterFileExt=%[7]d
     $ShellCompDirectiveFilterDirs=%[8]d
     $ShellCompDirectiveKeepOrder=%[9]d
+    $ShellCompDirectiveShellCommand=%[11]d
 
     # Prepare the command to request completions for the program.
     # Split the command at the first space to separate the program and arguments.
@@ -95,8 +96,8 @@ filter __%[1]s_escapeStringWithSpecialChars {
     __%[1]s_debug "New WordToComplete: $WordToComplete"
 
 
-    # Check for flag with equal sign
-    $IsEqualFlag = ($WordToComplete -Like "--*=*" )
+    # Check for flag with equal sign, either long (--flag=) or shorthand (-f=)
+    $IsEqualFlag = ($WordToComplete -Like "-*=*" )
     if ( $IsEqualFlag ) {
         __%[1]s_debug "Completing equal sign flag"
         # Remove the flag part
@@ -146,6 +147,12 @@ filter __%[1]s_escapeStringWithSpecialChars {
         return
     }
 
+    if (($Directive -band $ShellCompDirectiveShellCommand) -ne 0 ) {
+        # The completions are the lines printed by the shell command
+        __%[1]s_debug "Executing shell command: $($Out[0])"
+        $Out = Invoke-Expression "$($Out[0])" 2>$null
+    }
+
     $Longest = 0
     [Array]$Values = $Out | ForEach-Object {
         #Split the output in name and description
@@ -182,8 +189,9 @@ filter __%[1]s_escapeStringWithSpecialChars {
     }
 
     $Values = $Values | Where-Object {
-        # filter the result
-        $_.Name -like "$WordToComplete*"
+        # filter the result; the word to complete is escaped so that glob
+        # characters it contains (e.g., * or [) are matched literally
+        $_.Name -like "$([WildcardPattern]::Escape($WordToComplete))*"
 
         # Join the flag back if we have an equal sign flag
         if ( $IsEqualFlag ) {
@@ -282,7 +290,8 @@ filter __%[1]s_escapeStringWithSpecialChars {
 Register-ArgumentCompleter -CommandName '%[1]s' -ScriptBlock ${__%[2]sCompleterBlock}
 `, name, nameForVar, compCmd,
 		ShellCompDirectiveError, ShellCompDirectiveNoSpace, ShellCompDirectiveNoFileComp,
-		ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs, ShellCompDirectiveKeepOrder, activeHelpEnvVar(name)))
+		ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs, ShellCompDirectiveKeepOrder, activeHelpEnvVar(name),
+		ShellCompDirectiveShellCommand))
 }
 
 func (c *Command) genPowerShellCompletion(w io.Writer, includeDesc bool) error {
@@ -0,0 +1,232 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	flag "github.com/spf13/pflag"
+)
+
+// FlagSecretAnnotation marks a flag whose value is secret: it is read without
+// being echoed when prompted for, see SetInteractive.
+const FlagSecretAnnotation = "cobra_annotation_flag_secret"
+
+// SetInteractive sets whether the command and its children which do not set their
+// own mode prompt the user for the value of the required flags which were not set,
+// instead of failing.  The prompts are written to the error output and the values
+// read from the input of the command; they are skipped when the input is a file or
+// a pipe rather than a terminal, so that scripts still fail on missing flags.
+func (c *Command) SetInteractive(interactive bool) {
+	c.interactive = &interactive
+}
+
+// isInteractive returns true if the command prompts for its missing required flags.
+func (c *Command) isInteractive() bool {
+	for p := c; p != nil; p = p.Parent() {
+		if p.interactive != nil {
+			return *p.interactive
+		}
+	}
+	return false
+}
+
+// MarkFlagSecret marks the named flag as secret: when prompted for, its value is
+// read without being echoed.
+func (c *Command) MarkFlagSecret(name string) error {
+	return c.Flags().SetAnnotation(name, FlagSecretAnnotation, []string{"true"})
+}
+
+// promptRequiredFlags prompts for the value of the required flags which were not
+// set, if the command is interactive.  The flags left empty remain unset.
+func (c *Command) promptRequiredFlags() error {
+	if c.DisableFlagParsing || !c.isInteractive() {
+		return nil
+	}
+	if f, ok := c.InOrStdin().(*os.File); ok && !isTerminal(f) {
+		return nil
+	}
+	var err error
+	c.Flags().VisitAll(func(f *flag.Flag) {
+		required := f.Annotations[BashCompOneRequiredFlag]
+		if err != nil || len(required) == 0 || required[0] != "true" || f.Changed {
+			return
+		}
+		label := f.Name
+		if f.Usage != "" {
+			label += " (" + f.Usage + ")"
+		}
+		label += ": "
+		var value string
+		if _, secret := f.Annotations[FlagSecretAnnotation]; secret {
+			value, err = PromptSecret(c, label)
+		} else {
+			c.PrintErr(label)
+			value, err = readLine(promptContext(c), c.InOrStdin())
+		}
+		if err == io.EOF {
+			err = nil
+		}
+		if err != nil || value == "" {
+			return
+		}
+		if setErr := setFlagValue(f, value); setErr != nil {
+			err = newUsageError(fmt.Errorf("invalid value %q for flag %q: %w", value, f.Name, setErr))
+			return
+		}
+		c.setFlagSource(f, FlagSourcePrompt)
+	})
+	return err
+}
+
+// PromptSecret prints label to the error output of the command and reads a line
+// from its input, without echoing it if the input is a terminal which supports it.
+// The returned string does not include the line terminator.
+// If the context of the command is cancelled before a line is read, the context
+// error is returned.
+// Use cmd.SetIn() to provide the input in tests.
+func PromptSecret(cmd *Command, label string) (string, error) {
+	cmd.PrintErr(label)
+
+	in := cmd.InOrStdin()
+	if f, ok := in.(*os.File); ok && isTerminal(f) {
+		if restore, err := disableEcho(f); err == nil {
+			defer func() {
+				restore()
+				// The newline typed by the user was not echoed
+				cmd.PrintErrln()
+			}()
+		}
+	}
+	return readLine(promptContext(cmd), in)
+}
+
+// PromptConfirm prints label followed by " [y/N]: " to the error output of the
+// command and reads the answer from its input.  It returns true if the answer is
+// "y" or "yes" and false if it is empty, "n" or "no", ignoring case; any other answer
+// leads to the question being asked again.
+// If the context of the command is cancelled before an answer is read, the context
+// error is returned.
+// Use cmd.SetIn() to provide the input in tests.
+func PromptConfirm(cmd *Command, label string) (bool, error) {
+	in := cmd.InOrStdin()
+	ctx := promptContext(cmd)
+	for {
+		cmd.PrintErr(label + " [y/N]: ")
+		answer, err := readLine(ctx, in)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true, nil
+		case "", "n", "no":
+			return false, nil
+		}
+	}
+}
+
+func promptContext(cmd *Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// lineReader reads the lines of an input for the prompts.  A read abandoned by a
+// cancelled prompt keeps running, and its line is returned to the next prompt
+// instead of being lost.
+type lineReader struct {
+	in io.Reader
+	// reading is true while a line is being read, or read but not returned yet.
+	reading bool
+	lines   chan lineResult
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+var (
+	lineReadersMu sync.Mutex
+	lineReaders   = map[io.Reader]*lineReader{}
+)
+
+// readLine reads a line from in, one byte at a time so that no input following
+// the line is consumed.  The read is abandoned if ctx is done first; the line is
+// then returned by the next call to readLine with the same input.
+func readLine(ctx context.Context, in io.Reader) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	lineReadersMu.Lock()
+	r := &lineReader{in: in, lines: make(chan lineResult, 1)}
+	if reflect.TypeOf(in).Comparable() {
+		if shared, ok := lineReaders[in]; ok {
+			r = shared
+		} else {
+			lineReaders[in] = r
+		}
+	}
+	if !r.reading {
+		r.reading = true
+		go r.read()
+	}
+	lineReadersMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-r.lines:
+		lineReadersMu.Lock()
+		r.reading = false
+		if res.err != nil && lineReaders[in] == r {
+			// Nothing more can be read from in.
+			delete(lineReaders, in)
+		}
+		lineReadersMu.Unlock()
+		return res.line, res.err
+	}
+}
+
+func (r *lineReader) read() {
+	var sb strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.in.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				r.lines <- lineResult{line: strings.TrimSuffix(sb.String(), "\r")}
+				return
+			}
+			sb.WriteByte(buf[0])
+		}
+		if err != nil {
+			if err == io.EOF && sb.Len() > 0 {
+				err = nil
+			}
+			r.lines <- lineResult{line: sb.String(), err: err}
+			return
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPromptSecret(t *testing.T) {
+	cmd := &Command{Use: "root"}
+	errBuf := new(bytes.Buffer)
+	cmd.SetErr(errBuf)
+	in := strings.NewReader("s3cr3t\r\nnext line\n")
+	cmd.SetIn(in)
+
+	secret, err := PromptSecret(cmd, "Password: ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if secret != "s3cr3t" {
+		t.Errorf("expected %q, got %q", "s3cr3t", secret)
+	}
+	if errBuf.String() != "Password: " {
+		t.Errorf("unexpected prompt %q", errBuf.String())
+	}
+	// Only the first line must have been consumed
+	rest, _ := io.ReadAll(in)
+	if string(rest) != "next line\n" {
+		t.Errorf("expected remaining input %q, got %q", "next line\n", rest)
+	}
+}
+
+func TestPromptSecretEOF(t *testing.T) {
+	cmd := &Command{Use: "root"}
+	cmd.SetErr(new(bytes.Buffer))
+
+	cmd.SetIn(strings.NewReader("no newline"))
+	secret, err := PromptSecret(cmd, "Password: ")
+	if err != nil || secret != "no newline" {
+		t.Errorf("expected %q and no error, got %q and %v", "no newline", secret, err)
+	}
+
+	cmd.SetIn(strings.NewReader(""))
+	if _, err := PromptSecret(cmd, "Password: "); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestPromptConfirm(t *testing.T) {
+	testcases := []struct {
+		input    string
+		expected bool
+		prompts  int
+	}{
+		{input: "y\n", expected: true, prompts: 1},
+		{input: "YES\n", expected: true, prompts: 1},
+		{input: "\n", expected: false, prompts: 1},
+		{input: "no\n", expected: false, prompts: 1},
+		{input: "maybe\ny\n", expected: true, prompts: 2},
+	}
+
+	for _, tc := range testcases {
+		cmd := &Command{Use: "root"}
+		errBuf := new(bytes.Buffer)
+		cmd.SetErr(errBuf)
+		cmd.SetIn(strings.NewReader(tc.input))
+
+		got, err := PromptConfirm(cmd, "Continue?")
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.input, err)
+		}
+		if got != tc.expected {
+			t.Errorf("%q: expected %v, got %v", tc.input, tc.expected, got)
+		}
+		if n := strings.Count(errBuf.String(), "Continue? [y/N]: "); n != tc.prompts {
+			t.Errorf("%q: expected %d prompts, got %d", tc.input, tc.prompts, n)
+		}
+	}
+}
+
+func TestPromptCancelled(t *testing.T) {
+	cmd := &Command{Use: "root"}
+	cmd.SetErr(new(bytes.Buffer))
+	// A reader which never returns
+	r, w := io.Pipe()
+	defer w.Close()
+	cmd.SetIn(r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd.SetContext(ctx)
+	cancel()
+
+	if _, err := PromptConfirm(cmd, "Continue?"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if _, err := PromptSecret(cmd, "Password: "); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestPromptCancelledKeepsInput(t *testing.T) {
+	cmd := &Command{Use: "root"}
+	cmd.SetErr(new(bytes.Buffer))
+	r, w := io.Pipe()
+	defer w.Close()
+	cmd.SetIn(r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd.SetContext(ctx)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := PromptSecret(cmd, "Password: "); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// The line typed after the cancellation goes to the next prompt.
+	go func() { _, _ = w.Write([]byte("y\n")) }()
+	cmd.SetContext(context.Background())
+	done := make(chan bool, 1)
+	go func() {
+		confirmed, err := PromptConfirm(cmd, "Continue?")
+		done <- confirmed && err == nil
+	}()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Error("expected the next prompt to read the answer")
+		}
+	case <-time.After(time.Second):
+		t.Error("the answer was consumed by the cancelled prompt")
+	}
+}
+
+func TestInteractiveRequiredFlags(t *testing.T) {
+	var name, token string
+	var source FlagSource
+	getCmd := func(input string) (*Command, *Command) {
+		name, token = "", ""
+		rootCmd := &Command{Use: "root"}
+		childCmd := &Command{Use: "child", Run: func(cmd *Command, _ []string) { source = cmd.FlagSource("token") }}
+		childCmd.Flags().StringVar(&name, "name", "", "your name")
+		childCmd.Flags().StringVar(&token, "token", "", "")
+		assertNoErr(t, childCmd.MarkFlagRequired("name"))
+		assertNoErr(t, childCmd.MarkFlagRequired("token"))
+		assertNoErr(t, childCmd.MarkFlagSecret("token"))
+		rootCmd.AddCommand(childCmd)
+		rootCmd.SetInteractive(true)
+		rootCmd.SetIn(strings.NewReader(input))
+		return rootCmd, childCmd
+	}
+
+	rootCmd, _ := getCmd("s3cr3t\n")
+	output, err := executeCommand(rootCmd, "child", "--name", "gopher")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output != "token: " {
+		t.Errorf("Expected a prompt for the missing flag only, got %q", output)
+	}
+	if name != "gopher" || token != "s3cr3t" || source != FlagSourcePrompt {
+		t.Errorf("Expected the flag to be set from the prompt, got %q from %s", token, source)
+	}
+
+	// Flags left empty remain unset.
+	rootCmd, _ = getCmd("\n")
+	output, err = executeCommand(rootCmd, "child", "--token", "s3cr3t")
+	var requiredErr *RequiredFlagError
+	if !errors.As(err, &requiredErr) {
+		t.Fatalf("Expected a RequiredFlagError, got %v", err)
+	}
+	checkStringContains(t, output, "name (your name): ")
+
+	// Commands can opt out of the mode of their parents.
+	rootCmd, childCmd := getCmd("gopher\n")
+	childCmd.SetInteractive(false)
+	output, err = executeCommand(rootCmd, "child", "--token", "s3cr3t")
+	if !errors.As(err, &requiredErr) {
+		t.Fatalf("Expected a RequiredFlagError, got %v", err)
+	}
+	checkStringOmits(t, output, "name (your name): ")
+}
@@ -0,0 +1,62 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "context"
+
+// CommandProvider provides sub-commands generated when the program is executed,
+// e.g. from configuration files, remote APIs or plugin manifests, rather than
+// when the tree of commands is built.
+type CommandProvider interface {
+	// ProvideCommands returns the sub-commands to add to the command the provider
+	// was added to.  ctx is the context the program is executed with.
+	ProvideCommands(ctx context.Context) []*Command
+}
+
+// CommandProviderFunc is a function implementing CommandProvider.
+type CommandProviderFunc func(ctx context.Context) []*Command
+
+// ProvideCommands calls f(ctx).
+func (f CommandProviderFunc) ProvideCommands(ctx context.Context) []*Command {
+	return f(ctx)
+}
+
+// AddCommandProvider adds providers of sub-commands of c.  The providers are
+// queried each time the program is executed, and their commands are part of the
+// tree for the duration of the execution only, including for help and shell
+// completion.  The commands they provide may have providers of their own.
+func (c *Command) AddCommandProvider(providers ...CommandProvider) {
+	c.commandProviders = append(c.commandProviders, providers...)
+}
+
+// addProvidedCommands adds the commands of the providers of c and its sub-commands,
+// and returns the function removing them.
+func (c *Command) addProvidedCommands(ctx context.Context) func() {
+	var added []*Command
+	c.visitTree(func(cmd *Command) {
+		for _, p := range cmd.commandProviders {
+			provided := p.ProvideCommands(ctx)
+			cmd.AddCommand(provided...)
+			added = append(added, provided...)
+		}
+	})
+	return func() {
+		for _, cmd := range added {
+			if cmd.parent != nil {
+				cmd.parent.removeCommands(cmd)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type ctxKey struct{}
+
+func TestCommandProviders(t *testing.T) {
+	var ran []string
+	var queries int
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommandProvider(CommandProviderFunc(func(ctx context.Context) []*Command {
+		queries++
+		name := ctx.Value(ctxKey{}).(string)
+		toolCmd := &Command{Use: name, Short: "A provided command", Run: func(cmd *Command, args []string) { ran = append(ran, cmd.CommandPath()) }}
+		toolCmd.AddCommandProvider(CommandProviderFunc(func(context.Context) []*Command {
+			return []*Command{{Use: "nested", Run: func(cmd *Command, args []string) { ran = append(ran, cmd.CommandPath()) }}}
+		}))
+		return []*Command{toolCmd}
+	}))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "tool")
+	if _, _, err := executeCommandWithContextC(ctx, rootCmd, "tool"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err := executeCommandWithContextC(ctx, rootCmd, "tool", "nested"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(ran, ",") != "root tool,root tool nested" || queries != 2 {
+		t.Errorf("Expected the provided commands to be executed, got %v after %d queries", ran, queries)
+	}
+	if rootCmd.hasSubCommand("tool") {
+		t.Error("Expected the provided commands to be removed after the execution")
+	}
+
+	output, err := executeCommandWithContext(ctx, rootCmd, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "tool        A provided command")
+}
@@ -0,0 +1,148 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Registry collects commands declared in different packages, typically from
+// their init() functions, so that the command tree can be assembled without
+// the root command having to import every package explicitly.
+type Registry struct {
+	mutex         sync.Mutex
+	registrations []registration
+}
+
+type registration struct {
+	parentPath string
+	factory    func() *Command
+}
+
+// DefaultRegistry is the registry used by Register and Assemble.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds the command built by factory to the registry, as a child of the
+// command at parentPath.  The parent path is made of the names of the commands
+// from the root, excluded, to the parent: "" designates the root command and
+// "config" its "config" sub-command.  The parent may itself be registered.
+func (r *Registry) Register(parentPath string, factory func() *Command) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.registrations = append(r.registrations, registration{parentPath: parentPath, factory: factory})
+}
+
+// Assemble builds the registered commands and adds them to the tree of root.
+// It returns an error if a parent cannot be found, which includes cycles between
+// registered commands, or if a command conflicts with an existing one of the same
+// name or alias.  Commands which could be added stay in the tree.
+func (r *Registry) Assemble(root *Command) error {
+	r.mutex.Lock()
+	pending := make([]registration, 0, len(r.registrations))
+	pending = append(pending, r.registrations...)
+	r.mutex.Unlock()
+
+	var problems []string
+	cmds := make([]*Command, len(pending))
+	for i, reg := range pending {
+		cmds[i] = reg.factory()
+	}
+
+	// Add the commands whose parent exists until no progress can be made:
+	// registered commands may be the parents of other registered commands.
+	for progress := true; progress && len(pending) > 0; {
+		progress = false
+		var remaining []registration
+		var remainingCmds []*Command
+		for i, reg := range pending {
+			parent := findCommandByNames(root, strings.Fields(reg.parentPath))
+			if parent == nil {
+				remaining = append(remaining, reg)
+				remainingCmds = append(remainingCmds, cmds[i])
+				continue
+			}
+			progress = true
+			if err := checkCommandConflict(parent, cmds[i]); err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			parent.AddCommand(cmds[i])
+		}
+		pending, cmds = remaining, remainingCmds
+	}
+
+	for i, reg := range pending {
+		problems = append(problems, fmt.Sprintf("cannot register command %q: parent %q not found or part of a cycle",
+			cmds[i].Name(), strings.TrimSpace(root.Name()+" "+reg.parentPath)))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// Register adds the command built by factory to DefaultRegistry.
+// See Registry.Register.
+func Register(parentPath string, factory func() *Command) {
+	DefaultRegistry.Register(parentPath, factory)
+}
+
+// Assemble adds the commands of DefaultRegistry to the tree of root.
+// See Registry.Assemble.
+func Assemble(root *Command) error {
+	return DefaultRegistry.Assemble(root)
+}
+
+// findCommandByNames returns the descendant of c designated by the exact names of
+// the commands leading to it, or nil if there is none.
+func findCommandByNames(c *Command, names []string) *Command {
+	for _, name := range names {
+		var next *Command
+		for _, sub := range c.commands {
+			if sub.Name() == name {
+				next = sub
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		c = next
+	}
+	return c
+}
+
+// checkCommandConflict returns an error if the name or one of the aliases of cmd
+// is already used by a child of parent.
+func checkCommandConflict(parent, cmd *Command) error {
+	names := append([]string{cmd.Name()}, cmd.Aliases...)
+	for _, sub := range parent.commands {
+		for _, name := range names {
+			if sub.Name() == name || stringInSlice(name, sub.Aliases) {
+				return fmt.Errorf("cannot register command %q: %q is already used by %q",
+					cmd.Name(), name, sub.CommandPath())
+			}
+		}
+	}
+	return nil
+}
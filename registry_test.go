@@ -0,0 +1,99 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"testing"
+)
+
+func newRunnable(use string, aliases ...string) func() *Command {
+	return func() *Command {
+		return &Command{Use: use, Aliases: aliases, Run: emptyRun}
+	}
+}
+
+func TestRegistryAssemble(t *testing.T) {
+	registry := NewRegistry()
+	// Children may be registered before their parent.
+	registry.Register("config", newRunnable("set"))
+	registry.Register("config set", newRunnable("key"))
+	registry.Register("", newRunnable("config", "cfg"))
+	registry.Register("", newRunnable("version"))
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	if err := registry.Assemble(rootCmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, args := range [][]string{{"version"}, {"cfg", "set", "key"}} {
+		cmd, _, err := rootCmd.Find(args)
+		if err != nil {
+			t.Fatalf("Unexpected error finding %q: %v", args, err)
+		}
+		if cmd.Name() != args[len(args)-1] {
+			t.Errorf("expected to find %q, got %q", args[len(args)-1], cmd.Name())
+		}
+	}
+
+	// Assembling again creates a new tree.
+	otherRoot := &Command{Use: "other", Run: emptyRun}
+	if err := registry.Assemble(otherRoot); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(otherRoot.Commands()) != 2 {
+		t.Errorf("expected 2 commands, got %d", len(otherRoot.Commands()))
+	}
+}
+
+func TestRegistryAssembleErrors(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("", newRunnable("get"))
+	registry.Register("", newRunnable("fetch", "get"))
+	registry.Register("missing", newRunnable("orphan"))
+	registry.Register("b", newRunnable("a"))
+	registry.Register("a", newRunnable("b"))
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	err := registry.Assemble(rootCmd)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	expected := `cannot register command "fetch": "get" is already used by "root get"
+cannot register command "orphan": parent "root missing" not found or part of a cycle
+cannot register command "a": parent "root b" not found or part of a cycle
+cannot register command "b": parent "root a" not found or part of a cycle`
+	if err.Error() != expected {
+		t.Errorf("Expected error:\n%s\nGot:\n%s", expected, err.Error())
+	}
+
+	// The valid commands are still added.
+	if len(rootCmd.Commands()) != 1 || rootCmd.Commands()[0].Name() != "get" {
+		t.Errorf("expected only the get command, got %v", rootCmd.Commands())
+	}
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	defer func(r *Registry) { DefaultRegistry = r }(DefaultRegistry)
+	DefaultRegistry = NewRegistry()
+
+	Register("", newRunnable("child"))
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	if err := Assemble(rootCmd); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !rootCmd.HasSubCommands() {
+		t.Error("expected the registered command to be added")
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// FlagRenamedToAnnotation is set by RenameFlag on the hidden flag keeping the old
+// name of a renamed flag working; its value is the new name of the flag.
+const FlagRenamedToAnnotation = "cobra_annotation_flag_renamed_to"
+
+// RenameFlag keeps --oldName working as a hidden alias of the flag newName of cmd,
+// which must exist, after the flag was renamed.  Using the old name sets the new
+// flag and prints a warning.  The alias is persistent if the new flag is.
+func RenameFlag(cmd *Command, oldName, newName string) error {
+	fs := cmd.Flags()
+	target := cmd.PersistentFlags().Lookup(newName)
+	if target != nil {
+		fs = cmd.PersistentFlags()
+	} else if target = cmd.Flags().Lookup(newName); target == nil {
+		return fmt.Errorf("RenameFlag: flag '%s' does not exist", newName)
+	}
+	if cmd.Flag(oldName) != nil {
+		return fmt.Errorf("RenameFlag: flag '%s' already exists", oldName)
+	}
+
+	fs.AddFlag(&flag.Flag{
+		Name:        oldName,
+		Usage:       fmt.Sprintf("deprecated alias of --%s", newName),
+		Value:       &renamedFlagValue{target: target},
+		DefValue:    target.DefValue,
+		NoOptDefVal: target.NoOptDefVal,
+		Hidden:      true,
+		Annotations: map[string][]string{FlagRenamedToAnnotation: {newName}},
+	})
+	return nil
+}
+
+// renamedFlagValue is the value of the flag keeping the old name of a renamed
+// flag working: it sets the value of the renamed flag.
+type renamedFlagValue struct {
+	target *flag.Flag
+}
+
+func (v *renamedFlagValue) String() string { return v.target.Value.String() }
+
+func (v *renamedFlagValue) Type() string { return v.target.Value.Type() }
+
+func (v *renamedFlagValue) Set(s string) error {
+	if err := v.target.Value.Set(s); err != nil {
+		return err
+	}
+	v.target.Changed = true
+	return nil
+}
+
+// warnRenamedFlags prints a warning for each flag of c used by its old name.
+func (c *Command) warnRenamedFlags() {
+	c.Flags().VisitAll(func(f *flag.Flag) {
+		if newName, ok := f.Annotations[FlagRenamedToAnnotation]; ok && f.Changed && len(newName) > 0 {
+			c.Printf("Flag --%s has been renamed to --%s\n", f.Name, newName[0])
+		}
+	})
+}
+
+// RenameCommand keeps the command path oldPath working as a hidden alias of the
+// existing command newPath after the command was renamed or moved.  Both paths are
+// relative to root, e.g. "config view" and "config show"; the parent of the old
+// path must exist, but not the old command itself.  Using the old path executes
+// the new command and prints a warning.
+func RenameCommand(root *Command, oldPath, newPath string) error {
+	newWords := strings.Fields(newPath)
+	if len(newWords) == 0 || root.commandAt(newWords) == nil {
+		return fmt.Errorf("RenameCommand: command '%s' does not exist", newPath)
+	}
+	oldWords := strings.Fields(oldPath)
+	if len(oldWords) == 0 {
+		return fmt.Errorf("RenameCommand: invalid command path '%s'", oldPath)
+	}
+	parent := root.commandAt(oldWords[:len(oldWords)-1])
+	if parent == nil {
+		return fmt.Errorf("RenameCommand: command '%s' does not exist", strings.Join(oldWords[:len(oldWords)-1], " "))
+	}
+	oldName := oldWords[len(oldWords)-1]
+	if parent.hasSubCommand(oldName) {
+		return fmt.Errorf("RenameCommand: command '%s' already exists", oldPath)
+	}
+
+	if parent.renamedCommands == nil {
+		parent.renamedCommands = map[string][]string{}
+	}
+	parent.renamedCommands[oldName] = newWords
+	return nil
+}
+
+// commandAt returns the command at path, the names of the commands below c, or nil.
+func (c *Command) commandAt(path []string) *Command {
+	cmd := c
+	for _, name := range path {
+		next := cmd.subCommand(name, cmd.ExecOptions())
+		if next == nil {
+			return nil
+		}
+		cmd = next.loadLazy()
+	}
+	return cmd
+}
+
+// renamedCommand returns the command which the old name of one of the sub-commands
+// of c, registered by RenameCommand, stands for, and records that it was used.
+func (c *Command) renamedCommand(name string) *Command {
+	newPath, ok := c.renamedCommands[name]
+	if !ok {
+		return nil
+	}
+	root := c.Root()
+	cmd := root.commandAt(newPath)
+	if cmd != nil {
+		root.renamedCommandsUsed = append(root.renamedCommandsUsed,
+			fmt.Sprintf("Command %q has been renamed to %q\n", c.CommandPath()+" "+name, cmd.CommandPath()))
+	}
+	return cmd
+}
+
+// warnRenamedCommands prints a warning for each command executed by its old path.
+func (c *Command) warnRenamedCommands() {
+	for _, warning := range c.Root().renamedCommandsUsed {
+		c.Print(warning)
+	}
+}
+
+// DeprecatedAliases returns the old paths, registered by RenameCommand, which
+// execute c.
+func (c *Command) DeprecatedAliases() []string {
+	var aliases []string
+	root := c.Root()
+	root.visitTree(func(parent *Command) {
+		for name, newPath := range parent.renamedCommands {
+			if root.commandAt(newPath) == c {
+				aliases = append(aliases, parent.CommandPath()+" "+name)
+			}
+		}
+	})
+	sort.Strings(aliases)
+	return aliases
+}
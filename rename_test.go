@@ -0,0 +1,119 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenameFlag(t *testing.T) {
+	var output string
+	rootCmd := &Command{Use: "root"}
+	rootCmd.PersistentFlags().String("log-level", "info", "")
+	getCmd := &Command{Use: "get", Run: func(cmd *Command, _ []string) {
+		output, _ = cmd.Flags().GetString("output")
+	}}
+	getCmd.Flags().StringP("output", "o", "text", "output format")
+	rootCmd.AddCommand(getCmd)
+
+	if err := RenameFlag(getCmd, "format", "output"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := RenameFlag(rootCmd, "verbosity", "log-level"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := RenameFlag(getCmd, "fmt", "missing"); err == nil {
+		t.Errorf("Expected an error renaming a missing flag")
+	}
+	if err := RenameFlag(getCmd, "format", "output"); err == nil {
+		t.Errorf("Expected an error when the old name is in use")
+	}
+
+	out, err := executeCommand(rootCmd, "get", "--format", "json", "--verbosity=debug")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output != "json" || !getCmd.Flags().Changed("output") {
+		t.Errorf("Expected the old name to set the new flag, got %q", output)
+	}
+	if level, _ := getCmd.Flags().GetString("log-level"); level != "debug" {
+		t.Errorf("Expected the old name of the persistent flag to set it, got %q", level)
+	}
+	checkStringContains(t, out, "Flag --format has been renamed to --output")
+	checkStringContains(t, out, "Flag --verbosity has been renamed to --log-level")
+
+	help, err := executeCommand(rootCmd, "get", "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringOmits(t, help, "--format string")
+}
+
+func TestRenameCommand(t *testing.T) {
+	var ran []string
+	rootCmd := &Command{Use: "root"}
+	configCmd := &Command{Use: "config"}
+	showCmd := &Command{Use: "show [key]", Run: func(_ *Command, args []string) { ran = args }}
+	configCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(configCmd, &Command{Use: "version", Run: emptyRun})
+
+	if err := RenameCommand(rootCmd, "config view", "config show"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := RenameCommand(rootCmd, "get-config", "config show"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, tc := range []struct{ oldPath, newPath string }{
+		{"config view", "config list"},
+		{"missing view", "config show"},
+		{"version", "config show"},
+	} {
+		if err := RenameCommand(rootCmd, tc.oldPath, tc.newPath); err == nil {
+			t.Errorf("Expected an error renaming %q to %q", tc.oldPath, tc.newPath)
+		}
+	}
+
+	for _, args := range [][]string{{"config", "view", "editor"}, {"get-config", "editor"}} {
+		ran = nil
+		output, err := executeCommand(rootCmd, args...)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		if strings.Join(ran, " ") != "editor" {
+			t.Errorf("%v: expected the new command to run with [editor], got %v", args, ran)
+		}
+		oldPath := "root " + strings.Join(args[:len(args)-1], " ")
+		checkStringContains(t, output, `Command "`+oldPath+`" has been renamed to "root config show"`)
+	}
+
+	output, err := executeCommand(rootCmd, "config", "show", "editor")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringOmits(t, output, "renamed")
+
+	expected := []string{"root config view", "root get-config"}
+	if got := showCmd.DeprecatedAliases(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected deprecated aliases %v, got %v", expected, got)
+	}
+
+	help, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringOmits(t, help, "get-config")
+}
@@ -0,0 +1,165 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// SchemaRequestCmd is the name of the hidden command which prints the schema
+// of the program, as returned by Schema.
+const SchemaRequestCmd = "__schema"
+
+// CommandSchema describes a command, its flags and its sub-commands.
+type CommandSchema struct {
+	Name       string        `json:"name"`
+	Path       string        `json:"path"`
+	Use        string        `json:"use"`
+	Aliases    []string      `json:"aliases,omitempty"`
+	Short      string        `json:"short,omitempty"`
+	Long       string        `json:"long,omitempty"`
+	Example    string        `json:"example,omitempty"`
+	Examples   []Example     `json:"examples,omitempty"`
+	GroupID    string        `json:"group,omitempty"`
+	Groups     []GroupSchema `json:"groups,omitempty"`
+	Deprecated string        `json:"deprecated,omitempty"`
+	Hidden     bool          `json:"hidden,omitempty"`
+	Runnable   bool          `json:"runnable"`
+	// Args is the name of the function validating the positional arguments,
+	// such as "cobra.ExactArgs".
+	Args      string          `json:"args,omitempty"`
+	ArgNames  []string        `json:"arg_names,omitempty"`
+	ValidArgs []string        `json:"valid_args,omitempty"`
+	Params    []string        `json:"params,omitempty"`
+	Flags     []FlagSchema    `json:"flags,omitempty"`
+	Commands  []CommandSchema `json:"commands,omitempty"`
+}
+
+// GroupSchema describes a group of commands.
+type GroupSchema struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// FlagSchema describes a flag defined by a command.
+type FlagSchema struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default"`
+	Usage      string `json:"usage,omitempty"`
+	Persistent bool   `json:"persistent,omitempty"`
+	Required   bool   `json:"required,omitempty"`
+	Hidden     bool   `json:"hidden,omitempty"`
+	Deprecated string `json:"deprecated,omitempty"`
+	EnvVar     string `json:"env,omitempty"`
+}
+
+// Schema returns the description of c and all its sub-commands, including the
+// hidden ones, as JSON.  It is meant to be consumed by external tools, such as
+// wrappers or graphical interfaces, and is also printed by the hidden __schema
+// command of the program.
+func Schema(c *Command) ([]byte, error) {
+	return json.MarshalIndent(c.schema(), "", "  ")
+}
+
+func (c *Command) schema() CommandSchema {
+	s := CommandSchema{
+		Name:       c.Name(),
+		Path:       c.CommandPath(),
+		Use:        c.Use,
+		Aliases:    c.Aliases,
+		Short:      c.Short,
+		Long:       c.Long,
+		Example:    c.Example,
+		Examples:   c.Examples,
+		GroupID:    c.GroupID,
+		Deprecated: c.Deprecated,
+		Hidden:     c.Hidden,
+		Runnable:   c.Runnable(),
+		Args:       funcName(c.Args),
+		ArgNames:   c.ArgNames,
+		ValidArgs:  c.ValidArgs,
+	}
+	for _, g := range c.commandgroups {
+		s.Groups = append(s.Groups, GroupSchema{ID: g.ID, Title: g.Title, Description: g.Description})
+	}
+	for _, p := range c.Params {
+		s.Params = append(s.Params, p.Name)
+	}
+	c.LocalFlags().VisitAll(func(f *flag.Flag) {
+		s.Flags = append(s.Flags, FlagSchema{
+			Name:       f.Name,
+			Shorthand:  f.Shorthand,
+			Type:       f.Value.Type(),
+			Default:    f.DefValue,
+			Usage:      f.Usage,
+			Persistent: c.PersistentFlags().Lookup(f.Name) != nil,
+			Required:   isRequiredFlag(f),
+			Hidden:     f.Hidden,
+			Deprecated: f.Deprecated,
+			EnvVar:     strings.Join(f.Annotations[FlagEnvVarAnnotation], ","),
+		})
+	})
+	for _, sub := range c.Commands() {
+		if !c.HasParent() && c.isEphemeralChild(sub) {
+			continue
+		}
+		s.Commands = append(s.Commands, sub.schema())
+	}
+	return s
+}
+
+// closureSuffix matches the suffix of the name of the anonymous functions,
+// such as the ones returned by ExactArgs.
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// funcName returns the name of fn, such as "cobra.ExactArgs", or an empty string if fn is nil.
+func funcName(fn PositionalArgs) string {
+	if fn == nil {
+		return ""
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	return closureSuffix.ReplaceAllString(name, "")
+}
+
+// newSchemaCmd returns the hidden command printing the schema of the program.
+// Like the completion command, it is added to the tree as an ephemeral command.
+func newSchemaCmd() *Command {
+	return &Command{
+		Use:                   SchemaRequestCmd,
+		Short:                 "Print the schema of the program as JSON",
+		Hidden:                true,
+		DisableFlagsInUseLine: true,
+		Args:                  NoArgs,
+		RunE: func(cmd *Command, args []string) error {
+			b, err := Schema(cmd.Root())
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(b))
+			return err
+		},
+	}
+}
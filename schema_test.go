@@ -0,0 +1,85 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().StringP("output", "o", "text", "Output format")
+	rootCmd.AddGroup(&Group{ID: "manage", Title: "Management Commands:"})
+	getCmd := &Command{
+		Use:        "get",
+		Aliases:    []string{"g"},
+		GroupID:    "manage",
+		Deprecated: "use show instead",
+		Args:       ExactArgs(1),
+		Run:        emptyRun,
+	}
+	getCmd.Flags().Int("limit", 10, "Maximum number of results")
+	assertNoErr(t, getCmd.MarkFlagRequired("limit"))
+	rootCmd.AddCommand(getCmd)
+
+	b, err := Schema(rootCmd)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var schema CommandSchema
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(schema.Groups, []GroupSchema{{ID: "manage", Title: "Management Commands:"}}) {
+		t.Errorf("Unexpected groups: %v", schema.Groups)
+	}
+	if !reflect.DeepEqual(schema.Flags, []FlagSchema{{Name: "output", Shorthand: "o", Type: "string", Default: "text", Usage: "Output format", Persistent: true}}) {
+		t.Errorf("Unexpected flags: %v", schema.Flags)
+	}
+	if len(schema.Commands) != 1 {
+		t.Fatalf("Expected one sub-command, got %v", schema.Commands)
+	}
+	get := schema.Commands[0]
+	if get.Path != "root get" || get.GroupID != "manage" || get.Deprecated != "use show instead" ||
+		!reflect.DeepEqual(get.Aliases, []string{"g"}) || get.Args != "cobra.ExactArgs" || !get.Runnable {
+		t.Errorf("Unexpected sub-command: %+v", get)
+	}
+	if !reflect.DeepEqual(get.Flags, []FlagSchema{{Name: "limit", Type: "int", Default: "10", Usage: "Maximum number of results", Required: true}}) {
+		t.Errorf("Unexpected flags: %v", get.Flags)
+	}
+}
+
+func TestSchemaCmd(t *testing.T) {
+	rootCmd := &Command{Use: "root", Args: NoArgs, Run: emptyRun}
+	AddEphemeral(rootCmd, &Command{Use: "__debug", Run: emptyRun})
+
+	output, err := executeCommand(rootCmd, SchemaRequestCmd)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var schema CommandSchema
+	if err := json.Unmarshal([]byte(output), &schema); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if schema.Name != "root" || schema.Args != "cobra.NoArgs" || len(schema.Commands) != 0 {
+		t.Errorf("Expected the schema of the root command only, got %+v", schema)
+	}
+	if rootCmd.HasSubCommands() {
+		t.Errorf("Expected the %s command not to remain in the tree", SchemaRequestCmd)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SeeAlsoCommands returns the commands referenced by SeeAlso, in order.
+// Paths which do not exist in the command tree are ignored.
+func (c *Command) SeeAlsoCommands() []*Command {
+	var cmds []*Command
+	for _, path := range c.SeeAlso {
+		if cmd := c.Root().findCommandByPath(path); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// findCommandByPath returns the command of the tree of c whose CommandPath is path,
+// or nil if there is none.  Aliases are accepted in place of command names.
+func (c *Command) findCommandByPath(path string) *Command {
+	names := strings.Fields(path)
+	if len(names) == 0 || names[0] != c.Name() {
+		return nil
+	}
+	cmd := c
+	for _, name := range names[1:] {
+		next := cmd.findNext(name)
+		if next == nil {
+			return nil
+		}
+		cmd = next
+	}
+	return cmd
+}
+
+// ValidateTree checks the consistency of the command tree of c, such as the
+// existence of the commands referenced by SeeAlso.  It is meant to be called from
+// the tests of a program, and returns all the problems found.
+func (c *Command) ValidateTree() error {
+	var problems []string
+	c.visitTree(func(cmd *Command) {
+		for _, path := range cmd.SeeAlso {
+			if cmd.Root().findCommandByPath(path) == nil {
+				problems = append(problems, fmt.Sprintf("command %q refers to unknown command %q in SeeAlso", cmd.CommandPath(), path))
+			}
+		}
+	})
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// visitTree calls fn for c and all its descendants.
+func (c *Command) visitTree(fn func(*Command)) {
+	fn(c)
+	for _, sub := range c.commands {
+		sub.visitTree(fn)
+	}
+}
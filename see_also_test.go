@@ -0,0 +1,57 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"testing"
+)
+
+func TestSeeAlsoInHelp(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	configCmd := &Command{Use: "config", Run: emptyRun}
+	setCmd := &Command{Use: "set", Run: emptyRun}
+	getCmd := &Command{Use: "get", Run: emptyRun, SeeAlso: []string{"root config set", "root unknown"}}
+	configCmd.AddCommand(setCmd)
+	rootCmd.AddCommand(configCmd, getCmd)
+
+	output, err := executeCommand(rootCmd, "help", "get")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "\nSee also:\n  root config set\n")
+	checkStringOmits(t, output, "unknown")
+}
+
+func TestValidateTreeSeeAlso(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Aliases: []string{"kid"}, Run: emptyRun, SeeAlso: []string{"root other"}}
+	otherCmd := &Command{Use: "other", Run: emptyRun, SeeAlso: []string{"root kid", "root"}}
+	rootCmd.AddCommand(childCmd, otherCmd)
+
+	if err := rootCmd.ValidateTree(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	childCmd.SeeAlso = append(childCmd.SeeAlso, "child", "root missing")
+	err := rootCmd.ValidateTree()
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	expected := `command "root child" refers to unknown command "child" in SeeAlso` + "\n" +
+		`command "root child" refers to unknown command "root missing" in SeeAlso`
+	if err.Error() != expected {
+		t.Errorf("Expected error:\n%s\nGot:\n%s", expected, err.Error())
+	}
+}
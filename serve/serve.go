@@ -0,0 +1,132 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serve exposes the commands of a program over HTTP, so that automation
+// can execute them without starting a process.
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// RunPath is the path of the endpoint executing commands.
+const RunPath = "/run"
+
+// Request is the JSON body of the requests to RunPath.
+type Request struct {
+	// Args are the arguments of the program, without its name.
+	Args []string `json:"args"`
+	// Stdin is the standard input of the command.
+	Stdin string `json:"stdin,omitempty"`
+}
+
+// Response is the JSON body of the responses of RunPath.
+type Response struct {
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+	// ExitCode is the exit code the program would have exited with, see cobra.ExitCode.
+	ExitCode int `json:"exit_code"`
+	// Error is the error returned by the execution, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Handler returns the handler executing the commands of the tree returned by newRoot
+// for the POST requests to RunPath, whose body is a Request and whose response is a
+// Response.  Since commands keep the values of their flags, newRoot is called for
+// each request to execute it with a fresh tree; the command is executed with the
+// context of the request and its output is captured in the response.
+func Handler(newRoot func() *cobra.Command) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != RunPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var stdout, stderr bytes.Buffer
+		root := newRoot()
+		root.SetArgs(append([]string{}, req.Args...))
+		root.SetIn(strings.NewReader(req.Stdin))
+		root.SetOut(&stdout)
+		root.SetErr(&stderr)
+		err := root.ExecuteContext(r.Context())
+
+		resp := Response{Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: cobra.ExitCode(err)}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// AddrFlagName is the name of the flag of the command returned by NewCommand
+// setting the address to listen on.
+const AddrFlagName = "addr"
+
+// NewCommand returns the 'serve' command, which serves the Handler of the tree
+// returned by newRoot on the address set by its --addr flag until its context is
+// cancelled, e.g. on interrupt when executed with cobra.ExecuteWithSignals.
+func NewCommand(newRoot func() *cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the commands of the program over HTTP",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, err := cmd.Flags().GetString(AddrFlagName)
+			if err != nil {
+				return err
+			}
+			return listenAndServe(cmd.Context(), &http.Server{Addr: addr, Handler: Handler(newRoot)})
+		},
+	}
+	cmd.Flags().String(AddrFlagName, "localhost:8080", "address to listen on")
+	return cmd
+}
+
+// listenAndServe runs srv until ctx is cancelled.
+func listenAndServe(ctx context.Context, srv *http.Server) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		case <-done:
+		}
+	}()
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
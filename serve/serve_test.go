@@ -0,0 +1,121 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newRoot() *cobra.Command {
+	rootCmd := &cobra.Command{Use: "root", SilenceUsage: true}
+	greetCmd := &cobra.Command{
+		Use: "greet",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			input, _ := io.ReadAll(cmd.InOrStdin())
+			fmt.Fprintf(cmd.OutOrStdout(), "hello %s%s\n", name, input)
+			return nil
+		},
+	}
+	greetCmd.Flags().String("name", "world", "")
+	failCmd := &cobra.Command{
+		Use: "fail",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return &cobra.ExitError{Code: 3, Err: errors.New("failed")}
+		},
+	}
+	rootCmd.AddCommand(greetCmd, failCmd)
+	return rootCmd
+}
+
+func run(t *testing.T, srv *httptest.Server, body string) Response {
+	t.Helper()
+	resp, err := http.Post(srv.URL+RunPath, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected status %s", resp.Status)
+	}
+	var r Response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(Handler(newRoot))
+	defer srv.Close()
+
+	expected := Response{Stdout: "hello you!\n"}
+	if got := run(t, srv, `{"args": ["greet", "--name", "you"], "stdin": "!"}`); got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	// Flags set by a previous request do not leak into the next one.
+	expected = Response{Stdout: "hello world\n"}
+	if got := run(t, srv, `{"args": ["greet"]}`); got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	expected = Response{Stderr: "Error: failed\n", ExitCode: 3, Error: "failed"}
+	if got := run(t, srv, `{"args": ["fail"]}`); got != expected {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	if got := run(t, srv, `{"args": ["greet", "--bad"]}`); got.ExitCode != 2 || got.Error != "unknown flag: --bad" {
+		t.Errorf("Expected a usage error, got %+v", got)
+	}
+}
+
+func TestHandlerInvalidRequests(t *testing.T) {
+	srv := httptest.NewServer(Handler(newRoot))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + RunPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected %d, got %s", http.StatusMethodNotAllowed, resp.Status)
+	}
+
+	resp, err = http.Post(srv.URL+RunPath, "application/json", strings.NewReader("not json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected %d, got %s", http.StatusBadRequest, resp.Status)
+	}
+
+	resp, err = http.Post(srv.URL+"/other", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected %d, got %s", http.StatusNotFound, resp.Status)
+	}
+}
@@ -96,3 +96,14 @@ func (c *Command) MarkPersistentFlagDirname(name string) error {
 func MarkFlagDirname(flags *pflag.FlagSet, name string) error {
 	return flags.SetAnnotation(name, BashCompSubdirsInDir, []string{})
 }
+
+// MarkFlagCompletionCommand makes the shell completion scripts complete the values
+// of the named flag of cmd with the lines printed by shellCommand, such as
+// "kubectl get ns -o name", which they execute in the shell of the user.  It is an
+// alternative to RegisterFlagCompletionFunc when the completions depend on the
+// environment of the shell rather than of the program.
+func MarkFlagCompletionCommand(cmd *Command, name, shellCommand string) error {
+	return cmd.RegisterFlagCompletionFunc(name, func(*Command, []string, string) ([]string, ShellCompDirective) {
+		return []string{shellCommand}, ShellCompDirectiveShellCommand | ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected []string
+	}{
+		{"", nil},
+		{"  \t ", nil},
+		{"get pods", []string{"get", "pods"}},
+		{"  get \t pods  ", []string{"get", "pods"}},
+		{`get "my file"`, []string{"get", "my file"}},
+		{`get 'my "file"'`, []string{"get", `my "file"`}},
+		{`it\'s a\ b`, []string{"it's", "a b"}},
+		{`"a \"quoted\" \$word \n"`, []string{`a "quoted" $word \n`}},
+		{`'a \n'`, []string{`a \n`}},
+		{`--name="John Doe" --empty '' ""`, []string{"--name=John Doe", "--empty", "", ""}},
+		{"line\\\ncontinued", []string{"linecontinued"}},
+		{"héllo 'wörld'", []string{"héllo", "wörld"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.line, func(t *testing.T) {
+			words, err := SplitWords(tc.line)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(words, tc.expected) {
+				t.Errorf("Expected %q, got %q", tc.expected, words)
+			}
+		})
+	}
+}
+
+func TestSplitWordsErrors(t *testing.T) {
+	testCases := map[string]string{
+		`get "pods`: "unterminated double-quoted string",
+		`get 'pods`: "unterminated single-quoted string",
+		`get pods\`: "unterminated backslash escape",
+	}
+	for line, expected := range testCases {
+		if _, err := SplitWords(line); err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q for %q, got %v", expected, line, err)
+		}
+	}
+}
+
+func TestExecuteLine(t *testing.T) {
+	var gotArgs []string
+	root := &Command{Use: "root", Run: emptyRun}
+	child := &Command{Use: "child", Run: func(_ *Command, args []string) { gotArgs = args }}
+	child.Flags().String("name", "", "")
+	root.AddCommand(child)
+	root.SetOut(new(bytes.Buffer))
+	root.SetErr(new(bytes.Buffer))
+
+	if err := root.ExecuteLine(`child --name "John Doe" 'a b' c`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name, _ := child.Flags().GetString("name"); name != "John Doe" {
+		t.Errorf("Expected name 'John Doe', got %q", name)
+	}
+	if !reflect.DeepEqual(gotArgs, []string{"a b", "c"}) {
+		t.Errorf("Expected args [a b c], got %q", gotArgs)
+	}
+
+	if err := root.ExecuteLine(`child "unterminated`); err == nil {
+		t.Errorf("Expected an error for an unterminated quote")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// ErrInterrupted is matched by errors.Is for the InterruptedError returned by
+// ExecuteWithSignals when the command failed after being interrupted by a signal.
+var ErrInterrupted = errors.New("interrupted")
+
+// InterruptedError is returned by ExecuteWithSignals when the command failed after
+// being interrupted by a signal.  It is ErrInterrupted for errors.Is and unwraps to
+// the error returned by the command.
+type InterruptedError struct {
+	// Signal is the signal which interrupted the command.
+	Signal os.Signal
+	// Err is the error returned by the command.
+	Err error
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("%v by signal %v: %v", ErrInterrupted, e.Signal, e.Err)
+}
+
+func (e *InterruptedError) Is(target error) bool { return target == ErrInterrupted }
+
+func (e *InterruptedError) Unwrap() error { return e.Err }
+
+// signalContextKey is the key of the signalState in the context of the commands
+// executed by ExecuteWithSignals.
+type signalContextKey struct{}
+
+// signalState records the signal which interrupted an execution.
+type signalState struct {
+	mu     sync.Mutex
+	signal os.Signal
+}
+
+func (s *signalState) set(sig os.Signal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signal = sig
+}
+
+func (s *signalState) get() os.Signal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signal
+}
+
+// ExecuteWithSignals executes c like ExecuteContext, with a context which is cancelled
+// when one of signals is received: os.Interrupt and, where it exists, SIGTERM by default.
+// The context is derived from the one set on c, if any, and is available to all the
+// commands through Context().
+//
+// Only the first signal is captured: a second one, such as a second Ctrl-C, terminates
+// the program as usual if the command does not return once the context is cancelled.
+//
+// When the command returns an error after being interrupted, its persistent post-run
+// hooks are run anyway so that they can clean up, and the returned error is an
+// InterruptedError.
+func ExecuteWithSignals(c *Command, signals ...os.Signal) error {
+	if len(signals) == 0 {
+		signals = defaultInterruptSignals
+	}
+	root := c.Root()
+	original := root.ctx
+	// Do not leave the cancelled context behind for later executions.
+	defer func() { root.ctx = original }()
+	parent := original
+	if parent == nil {
+		parent = context.Background()
+	}
+	state := &signalState{}
+	ctx, cancel := context.WithCancel(context.WithValue(parent, signalContextKey{}, state))
+	defer cancel()
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+	defer signal.Stop(ch)
+	go func() {
+		select {
+		case sig := <-ch:
+			signal.Stop(ch)
+			state.set(sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := root.ExecuteContext(ctx)
+	if sig := state.get(); sig != nil && err != nil {
+		return &InterruptedError{Signal: sig, Err: err}
+	}
+	return err
+}
+
+// interrupted returns true if the command is executed by ExecuteWithSignals
+// and was interrupted by a signal.
+func (c *Command) interrupted() bool {
+	if c.ctx == nil {
+		return false
+	}
+	state, ok := c.ctx.Value(signalContextKey{}).(*signalState)
+	return ok && state.get() != nil
+}
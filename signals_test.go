@@ -0,0 +1,113 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package cobra
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExecuteWithSignals(t *testing.T) {
+	cleanedUp := false
+	rootCmd := &Command{
+		Use: "root",
+		RunE: func(cmd *Command, _ []string) error {
+			if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+				return err
+			}
+			<-cmd.Context().Done()
+			return &ExitError{Code: 3, Err: cmd.Context().Err()}
+		},
+		PersistentPostRun: func(*Command, []string) { cleanedUp = true },
+	}
+	rootCmd.SetArgs([]string{})
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	err := ExecuteWithSignals(rootCmd, syscall.SIGUSR1)
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("Expected an error wrapping ErrInterrupted, got %v", err)
+	}
+	if expected := "interrupted by signal user defined signal 1: context canceled"; err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+	var interruptedErr *InterruptedError
+	if !errors.As(err, &interruptedErr) || interruptedErr.Signal != syscall.SIGUSR1 {
+		t.Errorf("Expected an InterruptedError, got %#v", err)
+	}
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the error of the command to be wrapped, got %#v", err)
+	}
+	if code := ExitCode(err); code != 130 {
+		t.Errorf("Expected the exit code of an interruption, got %d", code)
+	}
+	if !cleanedUp {
+		t.Error("Expected the persistent post-run hook to run after the interruption")
+	}
+}
+
+func TestExecuteWithSignalsNotInterrupted(t *testing.T) {
+	var cmdErr error
+	rootCmd := &Command{
+		Use:  "root",
+		RunE: func(cmd *Command, _ []string) error { return cmdErr },
+		PersistentPostRun: func(*Command, []string) {
+			t.Error("Unexpected call of the persistent post-run hook after an error")
+		},
+	}
+	rootCmd.SetArgs([]string{})
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+
+	cmdErr = errors.New("failure")
+	if err := ExecuteWithSignals(rootCmd, syscall.SIGUSR1); err != cmdErr {
+		t.Errorf("Expected the error of the command, got %v", err)
+	}
+}
+
+func TestExecuteWithSignalsSecondSignal(t *testing.T) {
+	if os.Getenv("COBRA_TEST_SECOND_SIGNAL") != "" {
+		// Executed by the test below: the command ignores the cancellation.
+		rootCmd := &Command{
+			Use: "root",
+			RunE: func(cmd *Command, _ []string) error {
+				_ = syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+				<-cmd.Context().Done()
+				_ = syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+				time.Sleep(5 * time.Second)
+				return nil
+			},
+		}
+		rootCmd.SetArgs([]string{})
+		_ = ExecuteWithSignals(rootCmd)
+		os.Exit(0)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestExecuteWithSignalsSecondSignal$")
+	cmd.Env = append(os.Environ(), "COBRA_TEST_SECOND_SIGNAL=1")
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != -1 {
+		t.Errorf("Expected the second signal to terminate the program, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// These values should not be changed: users will be using them explicitly.
+const (
+	configEnvVarSuffixStateFile = "STATE_FILE"
+	stateFileName               = "state.json"
+)
+
+var (
+	// stateLockTimeout is how long to wait for another process to release the state.
+	stateLockTimeout = 5 * time.Second
+	// stateLockStaleAge is the age after which a lock is considered abandoned.
+	stateLockStaleAge = 30 * time.Second
+)
+
+// StateStore is a small key-value store persisted between the runs of a program,
+// for instance to remember the last used project or whether it is the first run.
+// Values are stored as JSON in a file shared by all the commands of the program.
+type StateStore struct {
+	path string
+	// err is the reason why the store has no path, if any.
+	err error
+}
+
+// State returns the state store of the program of cmd.
+// The state is stored in <XDG_DATA_HOME>/<program>/state.json, where XDG_DATA_HOME
+// defaults to ~/.local/share.  The environment variable <PROGRAM>_STATE_FILE can be
+// set to use another file, e.g. in tests.
+// If neither is set and the home directory of the user is unknown, the store is
+// unusable: Get finds no value and Set and Delete return an error.
+func State(cmd *Command) *StateStore {
+	root := cmd.Root()
+	if path := os.Getenv(configEnvVar(root.Name(), configEnvVarSuffixStateFile)); path != "" {
+		return &StateStore{path: path}
+	}
+
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			// Do not fall back to a directory shared with the other users.
+			return &StateStore{err: fmt.Errorf("cannot locate state directory: %w", err)}
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return &StateStore{path: filepath.Join(dataDir, root.Name(), stateFileName)}
+}
+
+// Path returns the path of the file holding the state, or an empty string if
+// the store is unusable.
+func (s *StateStore) Path() string {
+	return s.path
+}
+
+// Get returns the value stored for key, if any.
+func (s *StateStore) Get(key string) (string, bool) {
+	if s.err != nil {
+		return "", false
+	}
+	values, err := s.load()
+	if err != nil {
+		return "", false
+	}
+	v, ok := values[key]
+	return v, ok
+}
+
+// Set stores value for key.
+func (s *StateStore) Set(key, value string) error {
+	return s.update(func(values map[string]string) {
+		values[key] = value
+	})
+}
+
+// Delete removes key from the store.
+func (s *StateStore) Delete(key string) error {
+	return s.update(func(values map[string]string) {
+		delete(values, key)
+	})
+}
+
+// update applies fn to the stored values while holding the lock of the store.
+func (s *StateStore) update(fn func(map[string]string)) error {
+	if s.err != nil {
+		return s.err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("cannot create state directory: %w", err)
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	values, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(values)
+	return s.save(values)
+}
+
+// load reads the stored values.  A missing file is an empty store; a corrupted
+// file is moved aside to <file>.corrupt so that the program can keep working.
+func (s *StateStore) load() (map[string]string, error) {
+	values := map[string]string{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read state: %w", err)
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		_ = os.Rename(s.path, s.path+".corrupt")
+		return map[string]string{}, nil
+	}
+	return values, nil
+}
+
+// save atomically replaces the stored values.
+func (s *StateStore) save(values map[string]string) error {
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot write state: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("cannot write state: %w", err)
+	}
+	return nil
+}
+
+// lock acquires the lock file of the store, which protects it from concurrent
+// updates by goroutines and other processes, and returns the function releasing it.
+func (s *StateStore) lock() (func(), error) {
+	lockPath := s.path + ".lock"
+	deadline := time.Now().Add(stateLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("cannot lock state: %w", err)
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > stateLockStaleAge {
+			// The process holding the lock most likely died.
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("cannot lock state: %s is locked by another process", s.path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestStatePath(t *testing.T) {
+	rootCmd := &Command{Use: "my-prog"}
+	childCmd := &Command{Use: "child"}
+	rootCmd.AddCommand(childCmd)
+
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("MY_PROG_STATE_FILE", "")
+	if expected := filepath.Join(dir, "my-prog", "state.json"); State(childCmd).Path() != expected {
+		t.Errorf("expected %q, got %q", expected, State(childCmd).Path())
+	}
+
+	t.Setenv("MY_PROG_STATE_FILE", "/tmp/override.json")
+	if State(childCmd).Path() != "/tmp/override.json" {
+		t.Errorf("expected the override path, got %q", State(childCmd).Path())
+	}
+}
+
+func TestStateWithoutHome(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	t.Setenv("ROOT_STATE_FILE", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	// The variables holding the home directory, depending on the OS.
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	t.Setenv("home", "")
+
+	store := State(rootCmd)
+	if store.Path() != "" {
+		t.Errorf("expected no path without a home directory, got %q", store.Path())
+	}
+	if err := store.Set("key", "value"); err == nil {
+		t.Error("expected an error without a home directory")
+	}
+	if _, ok := store.Get("key"); ok {
+		t.Error("expected no value without a home directory")
+	}
+}
+
+func TestStateGetSet(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	t.Setenv("ROOT_STATE_FILE", filepath.Join(t.TempDir(), "sub", "state.json"))
+
+	state := State(rootCmd)
+	if _, ok := state.Get("region"); ok {
+		t.Error("expected no value in a new store")
+	}
+	if err := state.Set("region", "eu-west-1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := state.Set("first-run", "false"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A new store reads the persisted values.
+	if v, ok := State(rootCmd).Get("region"); !ok || v != "eu-west-1" {
+		t.Errorf("expected region to be persisted, got %q, %v", v, ok)
+	}
+
+	if err := state.Delete("region"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := state.Get("region"); ok {
+		t.Error("expected region to be deleted")
+	}
+	if v, _ := state.Get("first-run"); v != "false" {
+		t.Errorf("expected first-run to be kept, got %q", v)
+	}
+}
+
+func TestStateCorruptionRecovery(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	path := filepath.Join(t.TempDir(), "state.json")
+	t.Setenv("ROOT_STATE_FILE", path)
+
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	state := State(rootCmd)
+	if _, ok := state.Get("key"); ok {
+		t.Error("expected no value in a corrupted store")
+	}
+	if err := state.Set("key", "value"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v, _ := state.Get("key"); v != "value" {
+		t.Errorf("expected the store to be usable again, got %q", v)
+	}
+	if data, err := os.ReadFile(path + ".corrupt"); err != nil || string(data) != "{not json" {
+		t.Errorf("expected the corrupted file to be kept aside, got %q, %v", data, err)
+	}
+}
+
+func TestStateConcurrentSet(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	t.Setenv("ROOT_STATE_FILE", filepath.Join(t.TempDir(), "state.json"))
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := State(rootCmd).Set(fmt.Sprintf("key%d", i), "value"); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if _, ok := State(rootCmd).Get(fmt.Sprintf("key%d", i)); !ok {
+			t.Errorf("expected key%d to be stored", i)
+		}
+	}
+}
+
+func TestStateStaleLock(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	path := filepath.Join(t.TempDir(), "state.json")
+	t.Setenv("ROOT_STATE_FILE", path)
+
+	if err := os.WriteFile(path+".lock", nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	oldStaleAge := stateLockStaleAge
+	stateLockStaleAge = 0
+	defer func() { stateLockStaleAge = oldStaleAge }()
+
+	if err := State(rootCmd).Set("key", "value"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
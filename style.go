@@ -0,0 +1,134 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Theme defines the styles applied by the default help and usage templates, and
+// to the error prefix, when the output is a terminal.  A style is a space-separated
+// list of attributes, "bold", "faint", "italic" and "underline", and colors, "black",
+// "red", "green", "yellow", "blue", "magenta", "cyan" and "white", e.g. "bold cyan".
+// An empty style leaves the text unchanged.
+type Theme struct {
+	// Heading is the style of the headings, such as "Usage:" and "Flags:".
+	Heading string
+	// Command is the style of the names of the sub-commands.
+	Command string
+	// Flag is the style of the names of the flags.
+	Flag string
+	// ErrPrefix is the style of the error prefix, see SetErrPrefix.
+	ErrPrefix string
+}
+
+// ColorTheme is the theme of all the commands.  It is empty by default, which
+// leaves the output unchanged.
+//
+// Styles are only applied when the output is a terminal and the NO_COLOR environment
+// variable is not set.  Setting the CLICOLOR_FORCE environment variable to a value
+// other than "0" applies them to any output.
+var ColorTheme Theme
+
+// styleCodes are the SGR parameters of the style attributes.
+var styleCodes = map[string]int{
+	"bold": 1, "faint": 2, "italic": 3, "underline": 4,
+	"black": 30, "red": 31, "green": 32, "yellow": 33,
+	"blue": 34, "magenta": 35, "cyan": 36, "white": 37,
+}
+
+// style returns text with the style applied if color is true.
+func style(color bool, spec, text string) string {
+	if !color || text == "" {
+		return text
+	}
+	var codes []string
+	for _, attr := range strings.Fields(spec) {
+		if code, ok := styleCodes[attr]; ok {
+			codes = append(codes, strconv.Itoa(code))
+		}
+	}
+	if len(codes) == 0 {
+		return text
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + text + "\x1b[0m"
+}
+
+// flagNames matches the names of the flags at the start of the lines of the usage of flags.
+var flagNames = regexp.MustCompile(`(?m)^(\s+)(?:(-[^\s,-]), )?(--[^\s=]+)`)
+
+// styleFuncs returns the template functions applying styles if color is true.
+func styleFuncs(color bool) template.FuncMap {
+	return template.FuncMap{
+		"Bold": func(text string) string {
+			return style(color, "bold", text)
+		},
+		"Color": func(spec, text string) string {
+			return style(color, spec, text)
+		},
+		"Heading": func(text string) string {
+			return style(color, ColorTheme.Heading, text)
+		},
+		"StyleCommand": func(text string) string {
+			return style(color, ColorTheme.Command, text)
+		},
+		"StyleFlags": func(usages string) string {
+			if !color || ColorTheme.Flag == "" {
+				return usages
+			}
+			return flagNames.ReplaceAllStringFunc(usages, func(line string) string {
+				m := flagNames.FindStringSubmatch(line)
+				styled := m[1]
+				if m[2] != "" {
+					styled += style(color, ColorTheme.Flag, m[2]) + ", "
+				}
+				return styled + style(color, ColorTheme.Flag, m[3])
+			})
+		},
+	}
+}
+
+// colorWriter is a writer which records whether styles should be applied to the
+// text written to it, and the width of the terminal, if any, it is wrapped to, for
+// buffers collecting text written to the output later.
+type colorWriter struct {
+	io.Writer
+	color bool
+	width int
+}
+
+// colorSupported returns true if styles should be applied to the text written to w.
+func colorSupported(w io.Writer) bool {
+	if cw, ok := w.(*colorWriter); ok {
+		return cw.color
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	return os.Getenv("TERM") != "dumb" && isTerminal(w)
+}
+
+// styledErrPrefix returns the error prefix of cmd styled for the error output of c.
+func (c *Command) styledErrPrefix(cmd *Command) string {
+	return style(colorSupported(c.ErrOrStderr()), ColorTheme.ErrPrefix, cmd.ErrPrefix())
+}
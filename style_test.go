@@ -0,0 +1,90 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"testing"
+)
+
+func newStyledTree() *Command {
+	rootCmd := &Command{Use: "root", Run: emptyRun, SilenceUsage: true}
+	childCmd := &Command{Use: "child", Short: "A child", RunE: func(*Command, []string) error { return errors.New("failed") }}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	childCmd.Flags().String("name", "", "a name")
+	return rootCmd
+}
+
+func TestColorTheme(t *testing.T) {
+	defer func(theme Theme) { ColorTheme = theme }(ColorTheme)
+	ColorTheme = Theme{Heading: "bold", Command: "cyan", Flag: "yellow", ErrPrefix: "bold red"}
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "1")
+
+	output, err := executeCommand(newStyledTree(), "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "\x1b[1mUsage:\x1b[0m\n")
+	checkStringContains(t, output, "\x1b[1mAvailable Commands:\x1b[0m\n")
+	checkStringContains(t, output, "  \x1b[36mchild      \x1b[0m A child\n")
+	checkStringContains(t, output, "  \x1b[33m-v\x1b[0m, \x1b[33m--verbose\x1b[0m   verbose output\n")
+
+	output, err = executeCommand(newStyledTree(), "child", "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "      \x1b[33m--name\x1b[0m string   a name\n")
+	checkStringContains(t, output, "\x1b[1mGlobal Flags:\x1b[0m\n")
+
+	output, _ = executeCommand(newStyledTree(), "child")
+	checkStringContains(t, output, "\x1b[1;31mError:\x1b[0m failed\n")
+
+	// NO_COLOR wins over CLICOLOR_FORCE.
+	t.Setenv("NO_COLOR", "1")
+	output, _ = executeCommand(newStyledTree(), "--help")
+	checkStringOmits(t, output, "\x1b[")
+}
+
+func TestColorThemeNotTerminal(t *testing.T) {
+	defer func(theme Theme) { ColorTheme = theme }(ColorTheme)
+	ColorTheme = Theme{Heading: "bold", Command: "cyan", Flag: "yellow", ErrPrefix: "red"}
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+
+	output, err := executeCommand(newStyledTree(), "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringOmits(t, output, "\x1b[")
+	checkStringContains(t, output, "Usage:\n")
+}
+
+func TestStyleTemplateFuncs(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "1")
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.SetHelpTemplate(`{{Bold .Name}} {{Color "green underline" "ok"}} {{Color "unknown" "plain"}}` + "\n")
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "\x1b[1mroot\x1b[0m \x1b[32;4mok\x1b[0m plain\n"
+	if output != expected {
+		t.Errorf("expected: %q, got: %q", expected, output)
+	}
+}
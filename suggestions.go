@@ -0,0 +1,202 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggester returns, among the candidates, the ones which should be suggested
+// to the user who typed an unknown command name.  The returned suggestions are
+// displayed in order.
+type Suggester func(typed string, candidates []string) []string
+
+// SetSuggester sets the algorithm used to suggest sub-commands when an unknown
+// command is typed.  It applies to c and its children which do not set their own.
+// When no suggester is set, the Levenshtein distance is used, with a maximum
+// distance of SuggestionsMinimumDistance.
+func (c *Command) SetSuggester(s Suggester) {
+	c.suggester = s
+}
+
+// Suggester returns the suggester set by SetSuggester for this command or a parent,
+// or nil if none was set.
+func (c *Command) Suggester() Suggester {
+	if c.suggester != nil {
+		return c.suggester
+	}
+	if c.HasParent() {
+		return c.parent.Suggester()
+	}
+	return nil
+}
+
+// suggestionsDisabled returns true if DisableSuggestions is set on c or a parent.
+func (c *Command) suggestionsDisabled() bool {
+	for p := c; p != nil; p = p.parent {
+		if p.DisableSuggestions {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestionsMinimumDistance returns the SuggestionsMinimumDistance of c or of
+// its closest parent setting it, or 2.
+func (c *Command) suggestionsMinimumDistance() int {
+	for p := c; p != nil; p = p.parent {
+		if p.SuggestionsMinimumDistance > 0 {
+			return p.SuggestionsMinimumDistance
+		}
+	}
+	return 2
+}
+
+// LevenshteinSuggester suggests the candidates which are within maxDistance
+// edits of what was typed, ignoring case, or which start with what was typed.
+// This is the default suggestion algorithm.
+func LevenshteinSuggester(maxDistance int) Suggester {
+	return func(typed string, candidates []string) []string {
+		suggestions := []string{}
+		for _, candidate := range candidates {
+			suggestByLevenshtein := ld(typed, candidate, true) <= maxDistance
+			suggestByPrefix := strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(typed))
+			if suggestByLevenshtein || suggestByPrefix {
+				suggestions = append(suggestions, candidate)
+			}
+		}
+		return suggestions
+	}
+}
+
+// JaroWinklerSuggester suggests the candidates whose Jaro-Winkler similarity
+// with what was typed, ignoring case, is at least threshold (between 0 and 1).
+// The suggestions are sorted from the most to the least similar.
+// Jaro-Winkler favors common prefixes which often makes it more useful than
+// the Levenshtein distance for short command names.
+func JaroWinklerSuggester(threshold float64) Suggester {
+	return func(typed string, candidates []string) []string {
+		type scored struct {
+			name  string
+			score float64
+		}
+		var matches []scored
+		for _, candidate := range candidates {
+			score := jaroWinkler(strings.ToLower(typed), strings.ToLower(candidate))
+			if score >= threshold {
+				matches = append(matches, scored{candidate, score})
+			}
+		}
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+		suggestions := []string{}
+		for _, m := range matches {
+			suggestions = append(suggestions, m.name)
+		}
+		return suggestions
+	}
+}
+
+// PrefixSubstringSuggester suggests the candidates which start with what was
+// typed, followed by the ones which contain it, ignoring case.
+func PrefixSubstringSuggester(typed string, candidates []string) []string {
+	lowerTyped := strings.ToLower(typed)
+	prefixes := []string{}
+	substrings := []string{}
+	for _, candidate := range candidates {
+		lowerCandidate := strings.ToLower(candidate)
+		switch {
+		case strings.HasPrefix(lowerCandidate, lowerTyped):
+			prefixes = append(prefixes, candidate)
+		case strings.Contains(lowerCandidate, lowerTyped):
+			substrings = append(substrings, candidate)
+		}
+	}
+	return append(prefixes, substrings...)
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of s and t, between 0 and 1.
+func jaroWinkler(s, t string) float64 {
+	a, b := []rune(s), []rune(t)
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	matchDistance := len(a)
+	if len(b) > matchDistance {
+		matchDistance = len(b)
+	}
+	matchDistance = matchDistance/2 - 1
+	if matchDistance < 0 {
+		matchDistance = 0
+	}
+
+	aMatches := make([]bool, len(a))
+	bMatches := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		start := i - matchDistance
+		if start < 0 {
+			start = 0
+		}
+		end := i + matchDistance + 1
+		if end > len(b) {
+			end = len(b)
+		}
+		for j := start; j < end; j++ {
+			if bMatches[j] || a[i] != b[j] {
+				continue
+			}
+			aMatches[i] = true
+			bMatches[j] = true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	k := 0
+	for i := range a {
+		if !aMatches[i] {
+			continue
+		}
+		for !bMatches[k] {
+			k++
+		}
+		if a[i] != b[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for i := 0; i < len(a) && i < len(b) && i < 4; i++ {
+		if a[i] != b[i] {
+			break
+		}
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,120 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		s, t     string
+		expected float64
+	}{
+		{"", "", 1},
+		{"abc", "", 0},
+		{"abc", "abc", 1},
+		{"abc", "xyz", 0},
+		{"martha", "marhta", 0.9611},
+		{"dwayne", "duane", 0.84},
+		{"dixon", "dicksonx", 0.8133},
+	}
+	for _, tc := range tests {
+		if got := jaroWinkler(tc.s, tc.t); math.Abs(got-tc.expected) > 0.0001 {
+			t.Errorf("jaroWinkler(%q, %q) = %.4f, expected %.4f", tc.s, tc.t, got, tc.expected)
+		}
+	}
+}
+
+func TestSuggesters(t *testing.T) {
+	candidates := []string{"ls", "list", "log", "install", "status"}
+	tests := []struct {
+		name      string
+		suggester Suggester
+		typed     string
+		expected  []string
+	}{
+		{"levenshtein", LevenshteinSuggester(2), "lst", []string{"ls", "list", "log"}},
+		{"jaro-winkler", JaroWinklerSuggester(0.8), "lst", []string{"list", "ls"}},
+		{"prefix-substring", PrefixSubstringSuggester, "st", []string{"status", "list", "install"}},
+		{"prefix-substring case", PrefixSubstringSuggester, "LI", []string{"list"}},
+		{"no match", PrefixSubstringSuggester, "foo", []string{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.suggester(tc.typed, candidates)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetSuggester(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	rootCmd.AddCommand(childCmd)
+	childCmd.AddCommand(
+		&Command{Use: "install", Run: emptyRun},
+		&Command{Use: "uninstall", Run: emptyRun},
+		&Command{Use: "status", SuggestFor: []string{"stall"}, Run: emptyRun},
+	)
+
+	// The default suggester finds nothing within one edit of "stall".
+	rootCmd.SuggestionsMinimumDistance = 1
+	if got := childCmd.SuggestionsFor("stall"); !reflect.DeepEqual(got, []string{"status"}) {
+		t.Errorf("expected only the explicit suggestion, got %v", got)
+	}
+
+	// The suggester is inherited from the root command.
+	rootCmd.SetSuggester(PrefixSubstringSuggester)
+	expected := []string{"install", "uninstall", "status"}
+	if got := childCmd.SuggestionsFor("stall"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	output, _ := executeCommand(rootCmd, "hil")
+	checkStringContains(t, output, "Did you mean this?\n\tchild\n")
+}
+
+func TestSuggestionSettingsInherited(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	remoteCmd := &Command{Use: "remote"}
+	remoteCmd.AddCommand(&Command{Use: "prune", Run: emptyRun}, &Command{Use: "rename", Run: emptyRun})
+	rootCmd.AddCommand(remoteCmd)
+
+	if got := remoteCmd.SuggestionsFor("prn"); !reflect.DeepEqual(got, []string{"prune"}) {
+		t.Errorf("Expected the default distance of 2, got %v", got)
+	}
+
+	rootCmd.SuggestionsMinimumDistance = 3
+	if got := remoteCmd.SuggestionsFor("rnme"); !reflect.DeepEqual(got, []string{"prune", "rename"}) {
+		t.Errorf("Expected the distance of the root, got %v", got)
+	}
+	if remoteCmd.SuggestionsMinimumDistance != 0 {
+		t.Errorf("Expected the setting of the command to be left alone")
+	}
+
+	rootCmd.DisableSuggestions = true
+	rootCmd.SetExecOptions(ExecOptions{StrictSubCommands: true})
+	_, err := executeCommand(rootCmd, "remote", "prun")
+	var unknownErr *UnknownCommandError
+	if !errors.As(err, &unknownErr) || len(unknownErr.Suggestions) != 0 {
+		t.Errorf("Expected an unknown command error without suggestions, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+// TelemetryEvent describes an execution of a command, see SetTelemetry.  It holds
+// neither the values of the flags nor the arguments, which may be sensitive.
+type TelemetryEvent struct {
+	// CommandPath is the path of the command, as returned by CommandPath.
+	CommandPath string
+	// Flags are the sorted names of the flags which were set.
+	Flags []string
+	// Duration is the time spent executing the command.
+	Duration time.Duration
+	// ErrorType is the Go type of the error returned by the execution, e.g.
+	// "*cobra.DeprecatedCommandError", or empty if it succeeded.  Requesting the
+	// help is not considered as an error.
+	ErrorType string
+	// UsageError is true if the error is a usage error, see IsUsageError.
+	UsageError bool
+}
+
+// TelemetryHook receives an event after each execution of a command, so that
+// programs can measure how they are used.  Sending the events anywhere is up to
+// the hook.
+type TelemetryHook interface {
+	CommandExecuted(e TelemetryEvent)
+}
+
+// TelemetryFunc is a function used as a TelemetryHook.
+type TelemetryFunc func(e TelemetryEvent)
+
+// CommandExecuted calls f(e).
+func (f TelemetryFunc) CommandExecuted(e TelemetryEvent) {
+	f(e)
+}
+
+// SetTelemetry sets the hook receiving the executions of c and its children.
+// There is none by default.
+func (c *Command) SetTelemetry(h TelemetryHook) {
+	c.telemetry = h
+}
+
+// Telemetry returns the TelemetryHook of the command or of its closest parent
+// setting one.
+func (c *Command) Telemetry() TelemetryHook {
+	for p := c; p != nil; p = p.parent {
+		if p.telemetry != nil {
+			return p.telemetry
+		}
+	}
+	return nil
+}
+
+// sendTelemetry sends the execution of the command to its TelemetryHook, if any.
+func (c *Command) sendTelemetry(start time.Time, err error) {
+	h := c.Telemetry()
+	if h == nil {
+		return
+	}
+
+	e := TelemetryEvent{CommandPath: c.CommandPath(), Flags: []string{}, Duration: time.Since(start)}
+	c.Flags().Visit(func(f *flag.Flag) {
+		e.Flags = append(e.Flags, f.Name)
+	})
+	sort.Strings(e.Flags)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		e.UsageError = IsUsageError(err)
+		var usageErr *usageError
+		if errors.As(err, &usageErr) {
+			err = usageErr.err
+		}
+		e.ErrorType = fmt.Sprintf("%T", err)
+	}
+	h.CommandExecuted(e)
+}
@@ -0,0 +1,61 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"reflect"
+	"testing"
+)
+
+type failureError struct{}
+
+func (failureError) Error() string { return "failure" }
+
+func TestTelemetry(t *testing.T) {
+	var events []TelemetryEvent
+	rootCmd := &Command{Use: "root"}
+	rootCmd.PersistentFlags().Bool("debug", false, "")
+	getCmd := &Command{Use: "get", Args: NoArgs, Run: emptyRun}
+	getCmd.Flags().String("token", "", "")
+	failCmd := &Command{Use: "fail", RunE: func(*Command, []string) error { return failureError{} }}
+	rootCmd.AddCommand(getCmd, failCmd)
+
+	_, _ = executeCommand(rootCmd, "get")
+	rootCmd.SetTelemetry(TelemetryFunc(func(e TelemetryEvent) { events = append(events, e) }))
+	if getCmd.Telemetry() == nil {
+		t.Fatalf("Expected the telemetry hook to be inherited")
+	}
+
+	_, _ = executeCommand(rootCmd, "get", "--token", "secret", "--debug")
+	_, _ = executeCommand(rootCmd, "fail")
+	_, _ = executeCommand(rootCmd, "get", "extra")
+	_, _ = executeCommand(rootCmd, "fail", "--help")
+
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 events, got %+v", events)
+	}
+	if events[0].CommandPath != "root get" || !reflect.DeepEqual(events[0].Flags, []string{"debug", "token"}) || events[0].ErrorType != "" {
+		t.Errorf("Unexpected event for a success: %+v", events[0])
+	}
+	if events[1].ErrorType != "cobra.failureError" || events[1].UsageError {
+		t.Errorf("Unexpected event for a failure: %+v", events[1])
+	}
+	if events[2].ErrorType != "*cobra.UnknownCommandError" || !events[2].UsageError {
+		t.Errorf("Unexpected event for a usage error: %+v", events[2])
+	}
+	if events[3].CommandPath != "root fail" || events[3].ErrorType != "" {
+		t.Errorf("Unexpected event for a help request: %+v", events[3])
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"context"
+	"errors"
+)
+
+// startTimeout sets on c a context cancelled once its Timeout elapses.  It returns
+// the function to call with the error of the execution once it is done, which
+// restores the context and returns the error to report.
+func (c *Command) startTimeout() func(err error) error {
+	if c.Timeout <= 0 {
+		return func(err error) error { return err }
+	}
+	original := c.ctx
+	parent := original
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, c.Timeout)
+	c.ctx = ctx
+	return func(err error) error {
+		// Do not leave the cancelled context behind for later executions.
+		c.ctx = original
+		cancel()
+		// Only report the timeout of c, not the deadline of the parent context.
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+			return &TimeoutError{Command: c.CommandPath(), Timeout: c.Timeout, Err: err}
+		}
+		return err
+	}
+}
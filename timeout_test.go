@@ -0,0 +1,68 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCommandTimeout(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	slowCmd := &Command{
+		Use:     "slow",
+		Timeout: 10 * time.Millisecond,
+		RunE: func(cmd *Command, args []string) error {
+			<-cmd.Context().Done()
+			return cmd.Context().Err()
+		},
+	}
+	rootCmd.AddCommand(slowCmd)
+
+	output, err := executeCommand(rootCmd, "slow")
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != 10*time.Millisecond || timeoutErr.Command != "root slow" {
+		t.Fatalf("Expected a TimeoutError for 'root slow', got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	checkStringContains(t, output, "Error: command timed out after 10ms")
+	if slowCmd.Context() != nil && slowCmd.Context().Err() != nil {
+		t.Error("Expected the cancelled context not to be left on the command")
+	}
+}
+
+func TestCommandTimeoutNotReached(t *testing.T) {
+	errFailed := errors.New("failed")
+	rootCmd := &Command{
+		Use:     "root",
+		Timeout: time.Minute,
+		RunE: func(cmd *Command, args []string) error {
+			if _, ok := cmd.Context().Deadline(); !ok {
+				t.Error("Expected the context to have a deadline")
+			}
+			return errFailed
+		},
+	}
+
+	_, err := executeCommand(rootCmd)
+	var timeoutErr *TimeoutError
+	if !errors.Is(err, errFailed) || errors.As(err, &timeoutErr) {
+		t.Errorf("Expected the error of the command, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "errors"
+
+// UnknownCommandFunc is called when the arguments of a command name one of its
+// sub-commands which does not exist.  cmd is the command which has no such
+// sub-command and args are its arguments, starting with the unknown name.  It
+// returns the command to execute instead, with its arguments, or an error.  It
+// returns a nil command and a nil error when it handled the unknown command
+// itself, e.g. by running a plugin, in which case the execution succeeds.
+type UnknownCommandFunc func(cmd *Command, args []string) (*Command, []string, error)
+
+// SetUnknownCommandFunc sets the function called instead of failing when an unknown
+// command is typed.  It applies to c and its children which do not set their own.
+func (c *Command) SetUnknownCommandFunc(f UnknownCommandFunc) {
+	c.unknownCommandFunc = f
+}
+
+// UnknownCommandFunc returns the function set by SetUnknownCommandFunc for this
+// command or a parent, or nil if none was set.
+func (c *Command) UnknownCommandFunc() UnknownCommandFunc {
+	for p := c; p != nil; p = p.parent {
+		if p.unknownCommandFunc != nil {
+			return p.unknownCommandFunc
+		}
+	}
+	return nil
+}
+
+// unknownSubCommandError returns the error reported when cmd is executed with
+// args which start with an unknown sub-command, or nil.  It is only checked before
+// the execution when an UnknownCommandFunc is set, since the execution reports it
+// otherwise.
+func (c *Command) unknownSubCommandError(args []string) error {
+	if c.UnknownCommandFunc() == nil || c.Runnable() || !c.HasAvailableSubCommands() || c.DisableFlagParsing {
+		return nil
+	}
+	if names := stripFlags(args, c); len(names) > 0 {
+		return &UnknownCommandError{Command: c.CommandPath(), Name: names[0], Suggestions: c.suggestionsForError(names[0])}
+	}
+	return nil
+}
+
+// resolveUnknownCommand calls the UnknownCommandFunc of cmd, if any, when err is
+// an UnknownCommandError, and returns the command to execute instead, its
+// arguments and the error; otherwise it returns its own arguments.
+func resolveUnknownCommand(cmd *Command, args []string, err error) (*Command, []string, error) {
+	var unknownErr *UnknownCommandError
+	if cmd == nil || !errors.As(err, &unknownErr) {
+		return cmd, args, err
+	}
+	f := cmd.UnknownCommandFunc()
+	if f == nil {
+		return cmd, args, err
+	}
+	return f(cmd, args)
+}
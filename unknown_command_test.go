@@ -0,0 +1,104 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// autoCorrect runs the only suggestion for the unknown command, if there is one.
+func autoCorrect(cmd *Command, args []string) (*Command, []string, error) {
+	suggestions := cmd.SuggestionsFor(args[0])
+	if len(suggestions) != 1 {
+		return nil, nil, &UnknownCommandError{Command: cmd.CommandPath(), Name: args[0], Suggestions: suggestions}
+	}
+	return cmd.Find(append(suggestions[:1], args[1:]...))
+}
+
+func TestUnknownCommandFuncAutoCorrect(t *testing.T) {
+	var gotArgs []string
+	rootCmd := &Command{Use: "root"}
+	remoteCmd := &Command{Use: "remote"}
+	addCmd := &Command{Use: "add", Run: func(_ *Command, args []string) { gotArgs = args }}
+	addCmd.Flags().Bool("fetch", false, "")
+	remoteCmd.AddCommand(addCmd, &Command{Use: "remove", Run: emptyRun})
+	rootCmd.AddCommand(remoteCmd)
+	rootCmd.SetUnknownCommandFunc(autoCorrect)
+
+	for _, args := range [][]string{{"remot", "add", "origin"}, {"remote", "ad", "--fetch", "origin"}} {
+		gotArgs = nil
+		if _, err := executeCommand(rootCmd, args...); err != nil {
+			t.Errorf("%v: unexpected error: %v", args, err)
+		}
+		if strings.Join(gotArgs, " ") != "origin" {
+			t.Errorf("%v: expected args [origin], got %v", args, gotArgs)
+		}
+	}
+	if !addCmd.Flags().Changed("fetch") {
+		t.Errorf("Expected the flags to be passed to the corrected command")
+	}
+
+	output, err := executeCommand(rootCmd, "remote", "rm")
+	var unknownErr *UnknownCommandError
+	if !errors.As(err, &unknownErr) || unknownErr.Command != "root remote" {
+		t.Errorf("Expected an unknown command error for root remote, got %v", err)
+	}
+	checkStringContains(t, output, `unknown command "rm" for "root remote"`)
+}
+
+func TestUnknownCommandFuncHandled(t *testing.T) {
+	var got []string
+	rootCmd := &Command{Use: "root", Args: NoArgs}
+	rootCmd.AddCommand(&Command{Use: "child", Run: emptyRun})
+	rootCmd.SetUnknownCommandFunc(func(cmd *Command, args []string) (*Command, []string, error) {
+		got = args
+		return nil, nil, nil
+	})
+
+	output, err := executeCommand(rootCmd, "plugin", "--verbose")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if output != "" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+	if strings.Join(got, " ") != "plugin --verbose" {
+		t.Errorf("Expected the unknown command and its arguments, got %v", got)
+	}
+}
+
+func TestUnknownCommandFuncInherited(t *testing.T) {
+	rootCmd := &Command{Use: "root"}
+	childCmd := &Command{Use: "child"}
+	childCmd.AddCommand(&Command{Use: "sub", Run: emptyRun})
+	rootCmd.AddCommand(childCmd)
+
+	if childCmd.UnknownCommandFunc() != nil {
+		t.Errorf("Expected no unknown command function")
+	}
+	errGuidance := errors.New("see the documentation")
+	rootCmd.SetUnknownCommandFunc(func(*Command, []string) (*Command, []string, error) {
+		return nil, nil, errGuidance
+	})
+
+	if _, err := executeCommand(rootCmd, "child", "unknown"); !errors.Is(err, errGuidance) {
+		t.Errorf("Expected the error of the unknown command function, got %v", err)
+	}
+	if _, err := executeCommand(rootCmd, "child", "sub"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// VerbosityFlagName is the name of the flag added by AddVerbosityFlag.
+const VerbosityFlagName = "verbose"
+
+// The verbosity levels from which Infof and Debugf print their message.
+const (
+	VerbosityInfo  = 1
+	VerbosityDebug = 2
+)
+
+// AddVerbosityFlag adds to c the persistent -v/--verbose flag, which can be repeated,
+// as in -vvv, to increase the verbosity level returned by Verbosity.
+func AddVerbosityFlag(c *Command) {
+	if c.PersistentFlags().Lookup(VerbosityFlagName) != nil {
+		return
+	}
+	c.PersistentFlags().CountP(VerbosityFlagName, "v", "increase the verbosity, can be repeated")
+}
+
+// Verbosity returns the number of times the --verbose flag was given to cmd, see
+// AddVerbosityFlag, or 0 if cmd has no such flag.
+func Verbosity(cmd *Command) int {
+	f := cmd.Flag(VerbosityFlagName)
+	if f == nil || f.Value.Type() != "count" {
+		return 0
+	}
+	level, _ := strconv.Atoi(f.Value.String())
+	return level
+}
+
+// Infof prints a message to the error output of c if the verbosity level of c is
+// at least VerbosityInfo.
+func (c *Command) Infof(format string, i ...interface{}) {
+	c.logf(VerbosityInfo, format, i...)
+}
+
+// Debugf prints a message to the error output of c if the verbosity level of c is
+// at least VerbosityDebug.
+func (c *Command) Debugf(format string, i ...interface{}) {
+	c.logf(VerbosityDebug, format, i...)
+}
+
+// logf prints a message, followed by a newline if it has none, to the error output
+// of c if the verbosity level of c is at least level.
+func (c *Command) logf(level int, format string, i ...interface{}) {
+	if Verbosity(c) < level {
+		return
+	}
+	msg := fmt.Sprintf(format, i...)
+	if len(msg) == 0 || msg[len(msg)-1] != '\n' {
+		msg += "\n"
+	}
+	c.PrintErr(msg)
+}
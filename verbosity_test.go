@@ -0,0 +1,66 @@
+// Copyright 2013-2023 The Cobra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cobra
+
+import "testing"
+
+func newVerbosityTree(level *int) *Command {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{
+		Use: "child",
+		Run: func(cmd *Command, args []string) {
+			*level = Verbosity(cmd)
+			cmd.Infof("info %d", 1)
+			cmd.Debugf("debug %d\n", 2)
+		},
+	})
+	AddVerbosityFlag(rootCmd)
+	return rootCmd
+}
+
+func TestVerbosity(t *testing.T) {
+	testcases := []struct {
+		args     []string
+		level    int
+		expected string
+	}{
+		{[]string{"child"}, 0, ""},
+		{[]string{"child", "-v"}, 1, "info 1\n"},
+		{[]string{"-vv", "child"}, 2, "info 1\ndebug 2\n"},
+		{[]string{"child", "--verbose", "-vv"}, 3, "info 1\ndebug 2\n"},
+	}
+
+	for _, tc := range testcases {
+		var level int
+		output, err := executeCommand(newVerbosityTree(&level), tc.args...)
+		if err != nil {
+			t.Fatalf("%v: Unexpected error: %v", tc.args, err)
+		}
+		if level != tc.level {
+			t.Errorf("%v: expected level %d, got %d", tc.args, tc.level, level)
+		}
+		if output != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, output)
+		}
+	}
+}
+
+func TestVerbosityWithoutFlag(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().Bool(VerbosityFlagName, true, "")
+	if level := Verbosity(rootCmd); level != 0 {
+		t.Errorf("expected level 0, got %d", level)
+	}
+}
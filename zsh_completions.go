@@ -110,8 +110,9 @@ _%[1]s()
     local shellCompDirectiveFilterFileExt=%[6]d
     local shellCompDirectiveFilterDirs=%[7]d
     local shellCompDirectiveKeepOrder=%[8]d
+    local shellCompDirectiveShellCommand=%[10]d
 
-    local lastParam lastChar flagPrefix requestComp out directive comp lastComp noSpace keepOrder
+    local lastParam lastChar flagPrefix requestComp out directive comp lastComp noSpace keepOrder shellCommand
     local -a completions
 
     __%[1]s_debug "\n========= starting completion logic =========="
@@ -129,9 +130,10 @@ _%[1]s()
     __%[1]s_debug "lastParam: ${lastParam}, lastChar: ${lastChar}"
 
     # For zsh, when completing a flag with an = (e.g., %[1]s -n=<TAB>)
-    # completions must be prefixed with the flag
+    # completions must be prefixed with the flag.  Only the part up to the
+    # first = is the flag, the value itself may contain = (e.g., --selector=app=foo)
     setopt local_options BASH_REMATCH
-    if [[ "${lastParam}" =~ '-.*=' ]]; then
+    if [[ "${lastParam}" =~ '^-[^=]*=' ]]; then
         # We are dealing with a flag with an =
         flagPrefix="-P ${BASH_REMATCH}"
     fi
@@ -197,6 +199,12 @@ _%[1]s()
             continue
         fi
 
+        if [ -n "$comp" ] && [ $((directive & shellCompDirectiveShellCommand)) -ne 0 ] && [ -z "$shellCommand" ]; then
+            # The first completion is the shell command printing the completions
+            shellCommand=$comp
+            continue
+        fi
+
         if [ -n "$comp" ]; then
             # If requested, completions are returned with a description.
             # The description is preceded by a TAB character.
@@ -213,6 +221,18 @@ _%[1]s()
         fi
     done < <(printf "%%s\n" "${out[@]}")
 
+    if [ -n "$shellCommand" ]; then
+        __%[1]s_debug "Executing shell command: ${shellCommand}"
+        local tab="$(printf '\t')"
+        while IFS='\n' read -r comp; do
+            if [ -n "$comp" ]; then
+                comp=${comp//:/\\:}
+                comp=${comp//$tab/:}
+                completions+=${comp}
+            fi
+        done < <(eval ${shellCommand} 2>/dev/null)
+    fi
+
     # Add a delimiter after the activeHelp statements, but only if:
     # - there are completions following the activeHelp statements, or
     # - file completion will be performed (so there will be choices after the activeHelp)
@@ -304,5 +324,5 @@ fi
 `, name, compCmd,
 		ShellCompDirectiveError, ShellCompDirectiveNoSpace, ShellCompDirectiveNoFileComp,
 		ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs, ShellCompDirectiveKeepOrder,
-		activeHelpMarker))
+		activeHelpMarker, ShellCompDirectiveShellCommand))
 }